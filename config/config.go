@@ -42,6 +42,9 @@ package config
 // }
 
 import (
+	"strings"
+	"time"
+
 	"github.com/spf13/viper"
 )
 
@@ -53,16 +56,82 @@ type Config struct {
 		Name     string
 	}
 	Email struct {
-		Key string
+		Key        string
+		Backend    string
+		Host       string
+		Port       int
+		Username   string
+		From       string
+		TLS        string
+		Timeout    time.Duration
+		CaptureDir string
+	}
+	Port    string
+	JWT     string
+	JWTKeys struct {
+		Algorithm string
+		Rotation  time.Duration
+	}
+	TwoFactor struct {
+		Issuer            string
+		RequiredForAdmins bool
+	}
+	// Password configures the password policy and hashing. Zero values keep
+	// the defaults.
+	Password struct {
+		MinLength        int
+		CharacterClasses int
+		History          int
+		BreachedFile     string
+		Hash             string
+		BcryptCost       int
+	}
+	LoginGuard struct {
+		// CaptchaAfter is after how many failed logins clients are asked for
+		// a CAPTCHA, never when zero.
+		CaptchaAfter int
+	}
+	MagicLink struct {
+		// URL is the page of the client that logs in with emailed links. It
+		// gets the token of the link in its query and posts it with the
+		// device token to /users/magic-link/redeem.
+		URL string
 	}
-	Port  string
-	JWT   string
+	// Media configures where uploads are stored and how large they can be.
+	Media struct {
+		// Storage is "local", the default, or "s3".
+		Storage string
+		Dir     string
+		BaseURL string
+		// MaxUploadSize and Quota are in bytes, defaults when zero.
+		MaxUploadSize int64
+		Quota         int64
+		S3            struct {
+			Endpoint  string
+			Region    string
+			Bucket    string
+			AccessKey string
+			SecretKey string
+			PathStyle bool
+		}
+	}
+	// OIDC are the OpenID Connect providers users can log in with.
+	OIDC   []OIDCProvider
 	Gemini struct {
-		ApiKey string
-		Model  string
+		ApiKey         string
+		Model          string
+		EmbeddingModel string
 	}
 }
 
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	Scopes       []string
+}
+
 func LoadConfig() (*Config, error) {
 	viper.AutomaticEnv()
 
@@ -77,19 +146,66 @@ func LoadConfig() (*Config, error) {
 			Password: viper.GetString("DATABASE_PASSWORD"),
 			Uri:      viper.GetString("DATABASE_URI"),
 		},
-		Email: struct{ Key string }{
-			Key: viper.GetString("EMAIL_KEY"),
-		},
 		Port: viper.GetString("PORT"),
 		JWT:  viper.GetString("JWT"),
 		Gemini: struct {
-			ApiKey string
-			Model  string
+			ApiKey         string
+			Model          string
+			EmbeddingModel string
 		}{
-			ApiKey: viper.GetString("GEMINI_API_KEY"),
-			Model:  viper.GetString("GEMINI_MODEL"),
+			ApiKey:         viper.GetString("GEMINI_API_KEY"),
+			Model:          viper.GetString("GEMINI_MODEL"),
+			EmbeddingModel: viper.GetString("GEMINI_EMBEDDING_MODEL"),
 		},
 	}
+	cfg.Email.Key = viper.GetString("EMAIL_KEY")
+	cfg.Email.Backend = viper.GetString("EMAIL_BACKEND")
+	cfg.Email.Host = viper.GetString("EMAIL_SMTP_HOST")
+	cfg.Email.Port = viper.GetInt("EMAIL_SMTP_PORT")
+	cfg.Email.Username = viper.GetString("EMAIL_SMTP_USERNAME")
+	cfg.Email.From = viper.GetString("EMAIL_FROM")
+	cfg.Email.TLS = viper.GetString("EMAIL_SMTP_TLS")
+	cfg.Email.Timeout = viper.GetDuration("EMAIL_SMTP_TIMEOUT")
+	cfg.Email.CaptureDir = viper.GetString("EMAIL_CAPTURE_DIR")
+	cfg.JWTKeys.Algorithm = viper.GetString("JWT_ALGORITHM")
+	cfg.JWTKeys.Rotation = viper.GetDuration("JWT_KEY_ROTATION")
+	cfg.TwoFactor.Issuer = viper.GetString("TWO_FACTOR_ISSUER")
+	// admins need a second factor unless it is turned off explicitly
+	cfg.TwoFactor.RequiredForAdmins = viper.GetString("TWO_FACTOR_REQUIRED_FOR_ADMINS") != "false"
+	cfg.LoginGuard.CaptchaAfter = viper.GetInt("LOGIN_CAPTCHA_AFTER")
+	cfg.MagicLink.URL = viper.GetString("MAGIC_LINK_URL")
+	cfg.Password.MinLength = viper.GetInt("PASSWORD_MIN_LENGTH")
+	cfg.Password.CharacterClasses = viper.GetInt("PASSWORD_CHARACTER_CLASSES")
+	cfg.Password.History = viper.GetInt("PASSWORD_HISTORY")
+	cfg.Password.BreachedFile = viper.GetString("PASSWORD_BREACHED_FILE")
+	cfg.Password.Hash = viper.GetString("PASSWORD_HASH")
+	cfg.Password.BcryptCost = viper.GetInt("PASSWORD_BCRYPT_COST")
+	cfg.Media.Storage = viper.GetString("MEDIA_STORAGE")
+	cfg.Media.Dir = viper.GetString("MEDIA_DIR")
+	cfg.Media.BaseURL = viper.GetString("MEDIA_BASE_URL")
+	cfg.Media.MaxUploadSize = viper.GetInt64("MEDIA_MAX_UPLOAD_MB") << 20
+	cfg.Media.Quota = viper.GetInt64("MEDIA_QUOTA_MB") << 20
+	cfg.Media.S3.Endpoint = viper.GetString("MEDIA_S3_ENDPOINT")
+	cfg.Media.S3.Region = viper.GetString("MEDIA_S3_REGION")
+	cfg.Media.S3.Bucket = viper.GetString("MEDIA_S3_BUCKET")
+	cfg.Media.S3.AccessKey = viper.GetString("MEDIA_S3_ACCESS_KEY")
+	cfg.Media.S3.SecretKey = viper.GetString("MEDIA_S3_SECRET_KEY")
+	cfg.Media.S3.PathStyle = viper.GetBool("MEDIA_S3_PATH_STYLE")
+	// OIDC_PROVIDERS=google,keycloak reads OIDC_GOOGLE_ISSUER and so on
+	for _, name := range strings.Split(viper.GetString("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		cfg.OIDC = append(cfg.OIDC, OIDCProvider{
+			Name:         name,
+			Issuer:       viper.GetString(prefix + "ISSUER"),
+			ClientId:     viper.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: viper.GetString(prefix + "CLIENT_SECRET"),
+			Scopes:       strings.Fields(viper.GetString(prefix + "SCOPES")),
+		})
+	}
 
 	return cfg, nil
 }
@@ -1,6 +1,10 @@
 package controllers
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
 	"net/http"
 	"strconv"
 
@@ -103,6 +107,131 @@ func (cont *BlogController) HandleGetBlogById(ctx *gin.Context) {
 	}
 }
 
+// HandleGetRelatedBlogs godoc
+// @Summary Get related blog posts
+// @Description Retrieve existing blog posts ranked by tag overlap and content similarity to the given post. Default limit=5.
+// @Tags Blog
+// @Produce json
+// @Param blogId path string true "Blog post ID"
+// @Param limit query int false "Maximum number of related posts"
+// @Success 200 {array} domain.RelatedBlog "Related blog posts"
+// @Failure 404 {object} map[string]interface{} "Blog not found"
+// @Router /blogs/{blogId}/related [get]
+func (cont *BlogController) HandleGetRelatedBlogs(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = 5
+	}
+	related, err := cont.usecase.GetRelatedBlogs(ctx.Param("blogId"), limit)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	} else {
+		ctx.IndentedJSON(http.StatusOK, related)
+	}
+}
+
+// HandleSemanticSearch godoc
+// @Summary Semantic search over blog posts
+// @Description Retrieve blog posts whose meaning is closest to the query text. Default limit=10.
+// @Tags Blog
+// @Produce json
+// @Param q query string true "Search text"
+// @Param limit query int false "Maximum number of results"
+// @Success 200 {array} domain.RelatedBlog "Matching blog posts"
+// @Failure 400 {object} map[string]interface{} "Invalid query"
+// @Failure 503 {object} map[string]interface{} "Semantic search is not configured"
+// @Router /blogs/search [get]
+func (cont *BlogController) HandleSemanticSearch(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	blogs, err := cont.usecase.SemanticSearch(ctx.Query("q"), limit)
+	if err == usecase.ErrSemanticSearchDisabled {
+		ctx.IndentedJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	} else if err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	} else {
+		ctx.IndentedJSON(http.StatusOK, blogs)
+	}
+}
+
+// HandleMoreLikeThis godoc
+// @Summary Get blog posts similar in meaning
+// @Description Retrieve blog posts whose embeddings are closest to the given post. Default limit=5.
+// @Tags Blog
+// @Produce json
+// @Param blogId path string true "Blog post ID"
+// @Param limit query int false "Maximum number of results"
+// @Success 200 {array} domain.RelatedBlog "Similar blog posts"
+// @Failure 404 {object} map[string]interface{} "Blog not found"
+// @Failure 503 {object} map[string]interface{} "Semantic search is not configured"
+// @Router /blogs/{blogId}/similar [get]
+func (cont *BlogController) HandleMoreLikeThis(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = 5
+	}
+	blogs, err := cont.usecase.MoreLikeThis(ctx.Param("blogId"), limit)
+	if err == usecase.ErrSemanticSearchDisabled {
+		ctx.IndentedJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	} else if err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	} else {
+		ctx.IndentedJSON(http.StatusOK, blogs)
+	}
+}
+
+// HandleGetBlogMeta godoc
+// @Summary Get the metadata of a blog post
+// @Description Describes a blog post for link previews and search engines: Open Graph and Twitter Card tags and JSON-LD Article data, built from its SEO fields, excerpt and cover image. With format=html, returns the tags ready to put in the head of a page.
+// @Tags Blog
+// @Produce json
+// @Produce html
+// @Param blogId path string true "Blog post ID"
+// @Param format query string false "html for the markup"
+// @Success 200 {object} domain.BlogMeta "Metadata of the blog post"
+// @Failure 404 {object} map[string]interface{} "Blog not found"
+// @Router /blogs/{blogId}/meta [get]
+func (cont *BlogController) HandleGetBlogMeta(ctx *gin.Context) {
+	meta, err := cont.usecase.GetBlogMeta(ctx.Param("blogId"))
+	if err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if ctx.Query("format") == "html" {
+		markup, err := renderBlogMeta(meta)
+		if err != nil {
+			ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", markup)
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, meta)
+}
+
+// renderBlogMeta writes the tags of the head of the page of a blog.
+func renderBlogMeta(meta domain.BlogMeta) ([]byte, error) {
+	var markup bytes.Buffer
+	fmt.Fprintf(&markup, "<title>%s</title>\n", html.EscapeString(meta.Title))
+	fmt.Fprintf(&markup, "<link rel=\"canonical\" href=\"%s\">\n", html.EscapeString(meta.CanonicalURL))
+	for _, tag := range meta.Tags {
+		if tag.Property != "" {
+			fmt.Fprintf(&markup, "<meta property=\"%s\" content=\"%s\">\n", html.EscapeString(tag.Property), html.EscapeString(tag.Content))
+		} else {
+			fmt.Fprintf(&markup, "<meta name=\"%s\" content=\"%s\">\n", html.EscapeString(tag.Name), html.EscapeString(tag.Content))
+		}
+	}
+	// json.Marshal escapes <, > and &, so the data cannot close the script
+	jsonLD, err := json.Marshal(meta.JSONLD)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&markup, "<script type=\"application/ld+json\">%s</script>\n", jsonLD)
+	return markup.Bytes(), nil
+}
+
 // HandleGetPopularBlog godoc
 // @Summary Get popular blog posts
 // @Description Retrieve a list of blog posts that are popular.
@@ -195,7 +324,7 @@ func (cont *BlogController) HandleBlogUpdate(ctx *gin.Context) {
 
 // HandleBlogDelete godoc
 // @Summary Delete a blog post
-// @Description Delete an existing blog post using its unique ID. Requires proper ownership.
+// @Description Delete an existing blog post using its unique ID. Requires ownership or the blogs:delete-any permission.
 // @Tags Blog
 // @Produce json
 // @Security BearerAuth
@@ -211,7 +340,14 @@ func (cont *BlogController) HandleBlogDelete(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
 		return
 	}
-	err = cont.usecase.DeleteBLog(ctx.Param("blogId"), claims.ID)
+	authorId := claims.ID
+	if infrastructure.HasPermission(ctx, domain.PermDeleteAnyBlog) {
+		// Editors and moderators delete the blog as its author.
+		if blog, err := cont.usecase.GetBlogByBLogId(ctx.Param("blogId")); err == nil {
+			authorId = blog.AuthorId
+		}
+	}
+	err = cont.usecase.DeleteBLog(ctx.Param("blogId"), authorId)
 	if err != nil {
 		ctx.IndentedJSON(http.StatusNotFound, err)
 	} else {
@@ -269,14 +405,14 @@ func (cont *BlogController) HandleBlogLikeOrDislike(ctx *gin.Context) {
 
 // HandleCommentOnBlog godoc
 // @Summary Add a comment to a blog post
-// @Description Add a new comment to the specified blog post.
+// @Description Add a new comment to the specified blog post. Set parent_id to reply to another comment.
 // @Tags Blog Comments
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param blogId path string true "Blog post ID"
 // @Param comment body domain.Comment true "Comment data"
-// @Success 200 {object} map[string]string "Comment added successfully"
+// @Success 200 {object} domain.Comment "Comment added successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid comment data"
 // @Failure 401 {string} string "Unauthorized - invalid or missing token"
 // @Failure 404 {object} map[string]interface{} "Blog not found"
@@ -297,17 +433,17 @@ func (cont *BlogController) HandleCommentOnBlog(ctx *gin.Context) {
 		return
 	}
 	newComment.AuthorId = claims.ID
-	err = cont.usecase.AddComment(blogId, newComment)
+	comment, err := cont.usecase.AddComment(blogId, newComment)
 	if err != nil {
-		ctx.IndentedJSON(http.StatusNotFound, err)
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
 	} else {
-		ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Comment added successfully"})
+		ctx.IndentedJSON(http.StatusOK, comment)
 	}
 }
 
 // HandleGetAllComments godoc
 // @Summary Get all comments for a blog post
-// @Description Retrieve paginated comments for the specified blog post. Defaults: pageNumber=1, pageSize=5.
+// @Description Retrieve paginated top level comments for the specified blog post. Defaults: pageNumber=1, pageSize=5.
 // @Tags Blog Comments
 // @Produce json
 // @Param blogId path string true "Blog post ID"
@@ -340,6 +476,38 @@ func (cont *BlogController) HandleGetAllComments(ctx *gin.Context) {
 	}
 }
 
+// HandleGetCommentThread godoc
+// @Summary Get a comment thread
+// @Description Retrieve the comments of a blog post, or the replies below one comment, as a tree. Defaults: depth=3 (max 10), sort=top.
+// @Tags Blog Comments
+// @Produce json
+// @Param blogId path string true "Blog post ID"
+// @Param commentId path string false "Root comment ID"
+// @Param depth query int false "Number of reply levels to include"
+// @Param sort query string false "Sort order of siblings (top/newest/oldest)"
+// @Success 200 {array} domain.CommentNode "Comment tree"
+// @Failure 400 {object} map[string]string "Invalid sort order"
+// @Failure 404 {object} map[string]interface{} "Comment not found"
+// @Router /blogs/{blogId}/comments/thread [get]
+// @Router /blogs/{blogId}/comments/{commentId}/thread [get]
+func (cont *BlogController) HandleGetCommentThread(ctx *gin.Context) {
+	depth, err := strconv.Atoi(ctx.Query("depth"))
+	if err != nil || depth < 1 {
+		depth = 0
+	}
+	order := ctx.DefaultQuery("sort", domain.CommentSortTop)
+	if order != domain.CommentSortTop && order != domain.CommentSortNewest && order != domain.CommentSortOldest {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"message": "allowed:[top,newest,oldest]", "error": "unknown sort order"})
+		return
+	}
+	thread, err := cont.usecase.GetCommentThread(ctx.Param("blogId"), ctx.Param("commentId"), domain.CommentThreadOption{MaxDepth: depth, Sort: order})
+	if err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	} else {
+		ctx.IndentedJSON(http.StatusOK, thread)
+	}
+}
+
 // HandleGetCommentById godoc
 // @Summary Get a specific comment
 // @Description Retrieve a single comment by its ID for the given blog post.
@@ -353,6 +521,9 @@ func (cont *BlogController) HandleGetAllComments(ctx *gin.Context) {
 func (cont *BlogController) HandleGetCommentById(ctx *gin.Context) {
 	blogId := ctx.Param("blogId")
 	commentId := ctx.Param("commentId")
+	if replyId := ctx.Param("replyId"); replyId != "" {
+		commentId = replyId
+	}
 	comments, err := cont.usecase.GetCommentById(blogId, commentId)
 	if err != nil {
 		ctx.IndentedJSON(http.StatusNotFound, err)
@@ -361,9 +532,42 @@ func (cont *BlogController) HandleGetCommentById(ctx *gin.Context) {
 	}
 }
 
+// HandleDeleteComment godoc
+// @Summary Delete a comment
+// @Description Delete a comment together with all the replies below it. Only the author or a user with the comments:delete-any permission can delete a comment.
+// @Tags Blog Comments
+// @Produce json
+// @Security BearerAuth
+// @Param blogId path string true "Blog post ID"
+// @Param commentId path string true "Comment ID"
+// @Success 200 {object} map[string]string "Comment deleted"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 404 {object} map[string]interface{} "Comment not found"
+// @Router /blogs/{blogId}/comments/{commentId} [delete]
+func (cont *BlogController) HandleDeleteComment(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	authorId := claims.ID
+	if infrastructure.HasPermission(ctx, domain.PermDeleteAnyComment) {
+		// Moderators delete the comment as its author.
+		if comment, err := cont.usecase.GetCommentById(ctx.Param("blogId"), ctx.Param("commentId")); err == nil {
+			authorId = comment.AuthorId
+		}
+	}
+	err = cont.usecase.DeleteComment(ctx.Param("blogId"), ctx.Param("commentId"), authorId)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	} else {
+		ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Comment deleted"})
+	}
+}
+
 // HandleCommentLikeOrDislike godoc
 // @Summary Interact with a comment
-// @Description Like, dislike, or view a comment by specifying the interaction type.
+// @Description Like, dislike, or view a comment or reply by specifying the interaction type.
 // @Tags Blog Comments
 // @Produce json
 // @Security BearerAuth
@@ -380,6 +584,9 @@ func (cont *BlogController) HandleCommentLikeOrDislike(ctx *gin.Context) {
 	interactionType := ctx.Param("type")
 	blogId := ctx.Param("blogId")
 	commentId := ctx.Param("commentId")
+	if replyId := ctx.Param("replyId"); replyId != "" {
+		commentId = replyId
+	}
 	claims, err := infrastructure.GetClaims(ctx)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
@@ -414,22 +621,22 @@ func (cont *BlogController) HandleCommentLikeOrDislike(ctx *gin.Context) {
 
 // HandleReplyOnComment godoc
 // @Summary Reply to a comment
-// @Description Add a new reply to an existing comment on a blog post.
+// @Description Add a reply to an existing comment or reply. Replies are comments with a parent and can be nested to any depth.
 // @Tags Blog Comments
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param blogId path string true "Blog post ID"
 // @Param commentId path string true "Comment ID"
-// @Param reply body domain.Reply true "Reply data"
-// @Success 200 {object} map[string]string "Reply added successfully"
+// @Param reply body domain.Comment true "Reply data"
+// @Success 200 {object} domain.Comment "Reply added successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid reply data"
 // @Failure 401 {string} string "Unauthorized - invalid or missing token"
 // @Failure 404 {object} map[string]interface{} "Comment not found"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /blogs/{blogId}/comments/{commentId}/replies [post]
 func (cont *BlogController) HandleReplyOnComment(ctx *gin.Context) {
-	var newReply domain.Reply
+	var newReply domain.Comment
 	blogId := ctx.Param("blogId")
 	commentId := ctx.Param("commentId")
 	err := ctx.ShouldBindJSON(&newReply)
@@ -443,17 +650,17 @@ func (cont *BlogController) HandleReplyOnComment(ctx *gin.Context) {
 		return
 	}
 	newReply.AuthorId = claims.ID
-	err = cont.usecase.ReplyToComment(blogId, commentId, newReply)
+	reply, err := cont.usecase.ReplyToComment(blogId, commentId, newReply)
 	if err != nil {
-		ctx.IndentedJSON(http.StatusNotFound, err)
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
 	} else {
-		ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Reply added successfully"})
+		ctx.IndentedJSON(http.StatusOK, reply)
 	}
 }
 
 // HandleGetAllRepliesForComment godoc
 // @Summary Get all replies for a comment
-// @Description Retrieve paginated replies for the specified comment. Defaults: pageNumber=1, pageSize=5.
+// @Description Retrieve the paginated direct replies of the specified comment. Defaults: pageNumber=1, pageSize=5.
 // @Tags Blog Comments
 // @Produce json
 // @Param blogId path string true "Blog post ID"
@@ -487,78 +694,3 @@ func (cont *BlogController) HandleGetAllRepliesForComment(ctx *gin.Context) {
 		ctx.IndentedJSON(http.StatusOK, replies)
 	}
 }
-
-// HandleGetReplyById godoc
-// @Summary Get a specific reply
-// @Description Retrieve a single reply by its ID for the given comment and blog post.
-// @Tags Blog Comments
-// @Produce json
-// @Param blogId path string true "Blog post ID"
-// @Param commentId path string true "Comment ID"
-// @Param replyId path string true "Reply ID"
-// @Success 200 {object} domain.Reply "Reply details"
-// @Failure 404 {object} map[string]interface{} "Reply not found"
-// @Router /blogs/{blogId}/comments/{commentId}/replies/{replyId} [get]
-func (cont *BlogController) HandleGetReplyById(ctx *gin.Context) {
-	blogId := ctx.Param("blogId")
-	commentId := ctx.Param("commentId")
-	replyId := ctx.Param("replyId")
-	replies, err := cont.usecase.GetReplyById(blogId, commentId, replyId)
-	if err != nil {
-		ctx.IndentedJSON(http.StatusNotFound, err)
-	} else {
-		ctx.IndentedJSON(http.StatusOK, replies)
-	}
-}
-
-// HandleReplyLikeOrDislike godoc
-// @Summary Interact with a reply
-// @Description Like, dislike, or view a reply by specifying the interaction type.
-// @Tags Blog Comments
-// @Produce json
-// @Security BearerAuth
-// @Param blogId path string true "Blog post ID"
-// @Param commentId path string true "Comment ID"
-// @Param replyId path string true "Reply ID"
-// @Param type path string true "Interaction type (like/dislike/view)"
-// @Success 200 {object} map[string]string "Interaction result"
-// @Failure 400 {object} map[string]string "Invalid interaction type"
-// @Failure 401 {string} string "Unauthorized - invalid or missing token"
-// @Failure 404 {object} map[string]interface{} "Reply not found"
-// @Failure 500 {object} map[string]string "Internal server error"
-// @Router /blogs/{blogId}/comments/{commentId}/replies/{replyId}/interact/{type} [post]
-func (cont *BlogController) HandleReplyLikeOrDislike(ctx *gin.Context) {
-	like := ctx.Param("type")
-	commentId := ctx.Param("commentId")
-	blogId := ctx.Param("blogId")
-	replyId := ctx.Param("replyId")
-	claims, err := infrastructure.GetClaims(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
-		return
-	}
-	if like == "like" {
-		err := cont.usecase.LikeReply(blogId, commentId, replyId, claims.ID)
-		if err != nil {
-			ctx.IndentedJSON(http.StatusNotFound, err)
-		} else {
-			ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Reply liked successfully"})
-		}
-	} else if like == "dislike" {
-		err := cont.usecase.DislikeReply(blogId, commentId, replyId, claims.ID)
-		if err != nil {
-			ctx.IndentedJSON(http.StatusNotFound, err)
-		} else {
-			ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Reply disliked successfully"})
-		}
-	} else if like == "view" {
-		err := cont.usecase.ViewReply(blogId, commentId, replyId, claims.ID)
-		if err != nil {
-			ctx.IndentedJSON(http.StatusNotFound, err)
-		} else {
-			ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Reply viewed successfully"})
-		}
-	} else {
-		ctx.IndentedJSON(http.StatusNotFound, gin.H{"message": "invalid interaction type"})
-	}
-}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/yesetoda/BlogMate/infrastructure"
+	usecase "github.com/yesetoda/BlogMate/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountController struct {
+	usecase *usecase.AccountChangeUsecase
+}
+
+func NewAccountController(uc *usecase.AccountChangeUsecase) *AccountController {
+	return &AccountController{usecase: uc}
+}
+
+// EmailChangeRequest asks to change the email of the account.
+type EmailChangeRequest struct {
+	Password string `json:"password" binding:"required"`
+	NewEmail string `json:"new_email" binding:"required,email"`
+}
+
+// UsernameChangeRequest changes the username of the account.
+type UsernameChangeRequest struct {
+	Password string `json:"password" binding:"required"`
+	Username string `json:"username" binding:"required"`
+}
+
+// HandleRequestEmailChange godoc
+// @Summary Change my email
+// @Description Checks the password and emails a confirmation link, valid for 24 hours, to the new address. The email changes once it is opened, and the old address is then told how to undo it.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body EmailChangeRequest true "Password and new email"
+// @Success 202 {object} map[string]string "Confirmation sent"
+// @Failure 400 {object} map[string]string "Invalid password, invalid or taken email"
+// @Router /me/email [post]
+func (cont *AccountController) HandleRequestEmailChange(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	var request EmailChangeRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := cont.usecase.RequestEmailChange(claims.ID, request.Password, request.NewEmail); err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusAccepted, gin.H{"message": "A confirmation link was sent to the new address"})
+}
+
+// HandleConfirmEmailChange godoc
+// @Summary Confirm a new email
+// @Description Changes the email of the account to the address the link was sent to, and logs the user out to log in again.
+// @Tags users
+// @Produce json
+// @Param token query string true "Token of the emailed link"
+// @Success 200 {object} domain.AccountUser "User with the new email"
+// @Failure 400 {object} map[string]string "Invalid, used or expired link, or taken email"
+// @Router /users/email/confirm [get]
+func (cont *AccountController) HandleConfirmEmailChange(ctx *gin.Context) {
+	user, err := cont.usecase.ConfirmEmailChange(ctx.Query("token"))
+	if err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, user.Account())
+}
+
+// HandleRevertEmailChange godoc
+// @Summary Undo an email change
+// @Description Gives the account back the email it had before a change, from the link sent to the old address, cancels pending changes and logs the user out everywhere.
+// @Tags users
+// @Produce json
+// @Param token query string true "Token of the emailed link"
+// @Success 200 {object} map[string]string "Email restored"
+// @Failure 400 {object} map[string]string "Invalid, used or expired link"
+// @Router /users/email/revert [get]
+func (cont *AccountController) HandleRevertEmailChange(ctx *gin.Context) {
+	if _, err := cont.usecase.RevertEmailChange(ctx.Query("token")); err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Your email was restored and everyone was logged out, reset your password if it was not you"})
+}
+
+// HandleChangeUsername godoc
+// @Summary Change my username
+// @Description Checks the password and changes the username, at most once every 30 days. Usernames are 3 to 30 letters, digits or underscores and some are reserved. The user is logged out to log in again.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UsernameChangeRequest true "Password and new username"
+// @Success 200 {object} domain.AccountUser "User with the new username"
+// @Failure 400 {object} map[string]string "Invalid password, invalid, reserved or taken username, or changed too recently"
+// @Router /me/username [put]
+func (cont *AccountController) HandleChangeUsername(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	var request UsernameChangeRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user, err := cont.usecase.ChangeUsername(claims.ID, request.Password, request.Username)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, user.Account())
+}
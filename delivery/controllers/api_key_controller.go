@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/yesetoda/BlogMate/infrastructure"
+	usecase "github.com/yesetoda/BlogMate/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyController struct {
+	usecase *usecase.APIKeyUsecase
+}
+
+func NewAPIKeyController(uc *usecase.APIKeyUsecase) *APIKeyController {
+	return &APIKeyController{usecase: uc}
+}
+
+// CreateAPIKeyRequest creates an API key. Keys without an expiry do not
+// expire.
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+// HandleCreateAPIKey godoc
+// @Summary Create an API key
+// @Description Creates a personal API key for automation, sent as the X-API-Key header or as a bearer token. Scopes are read, blogs:write and comments:write; any key can read. The key is only shown in this answer.
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key body CreateAPIKeyRequest true "Name, scopes and expiry"
+// @Success 201 {object} domain.NewAPIKey "The key"
+// @Failure 400 {object} map[string]string "Invalid name, scopes or expiry"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Router /me/api-keys [post]
+func (cont *APIKeyController) HandleCreateAPIKey(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	var request CreateAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	key, err := cont.usecase.Create(claims.ID, request.Name, request.Scopes, time.Duration(request.ExpiresInDays)*24*time.Hour)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusCreated, key)
+}
+
+// HandleGetMyAPIKeys godoc
+// @Summary List my API keys
+// @Description Lists the API keys of the current user with their scopes, expiry and last use, newest first. The keys themselves are not shown.
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.APIKey "API keys"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /me/api-keys [get]
+func (cont *APIKeyController) HandleGetMyAPIKeys(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	keys, err := cont.usecase.GetAPIKeys(claims.ID)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, keys)
+}
+
+// HandleDeleteAPIKey godoc
+// @Summary Delete an API key
+// @Description Deletes an API key of the current user, it stops working at once.
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Param keyId path string true "API key ID"
+// @Success 200 {object} map[string]string "Deleted"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 404 {object} map[string]string "API key not found"
+// @Router /me/api-keys/{keyId} [delete]
+func (cont *APIKeyController) HandleDeleteAPIKey(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	if err := cont.usecase.Delete(claims.ID, ctx.Param("keyId")); err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"message": "API key deleted"})
+}
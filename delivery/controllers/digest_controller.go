@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	usecase "github.com/yesetoda/BlogMate/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DigestController struct {
+	usecase *usecase.DigestUsecase
+}
+
+func NewDigestController(uc *usecase.DigestUsecase) *DigestController {
+	return &DigestController{usecase: uc}
+}
+
+// HandleGetMyDigest godoc
+// @Summary Get my digest settings
+// @Description Returns whether the current user receives digest emails, how often, and the authors and tags they follow.
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.DigestSubscription "Digest settings"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /me/digest [get]
+func (cont *DigestController) HandleGetMyDigest(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	subscription, err := cont.usecase.GetSubscription(claims.ID)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, subscription)
+}
+
+// HandleUpdateMyDigest godoc
+// @Summary Update my digest settings
+// @Description Opt in or out of digest emails, choose daily or weekly (the default), and the author ids and tags to follow. Digests fall back to trending posts when nothing followed was published.
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param subscription body domain.DigestSubscription true "Digest settings"
+// @Success 200 {object} domain.DigestSubscription "Updated digest settings"
+// @Failure 400 {object} map[string]string "Invalid request payload or frequency"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Router /me/digest [put]
+func (cont *DigestController) HandleUpdateMyDigest(ctx *gin.Context) {
+	var subscription domain.DigestSubscription
+	if err := ctx.ShouldBindJSON(&subscription); err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	subscription, err = cont.usecase.UpdateSubscription(claims.ID, subscription)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, subscription)
+}
+
+// HandleUnsubscribeDigest godoc
+// @Summary Unsubscribe from digest emails
+// @Description One-click unsubscribe with the token from the link at the bottom of every digest, no login needed.
+// @Tags Digest
+// @Produce json
+// @Param token query string true "Unsubscribe token"
+// @Success 200 {object} map[string]string "Unsubscribed"
+// @Failure 404 {object} map[string]string "Invalid unsubscribe token"
+// @Router /digest/unsubscribe [get]
+// @Router /digest/unsubscribe [post]
+func (cont *DigestController) HandleUnsubscribeDigest(ctx *gin.Context) {
+	if err := cont.usecase.Unsubscribe(ctx.Query("token")); err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"message": "You will no longer receive digest emails"})
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/yesetoda/BlogMate/infrastructure"
+
+	"github.com/gin-gonic/gin"
+)
+
+type KeyController struct {
+	keyring *infrastructure.Keyring
+}
+
+func NewKeyController(keyring *infrastructure.Keyring) *KeyController {
+	return &KeyController{keyring: keyring}
+}
+
+// HandleJWKS godoc
+// @Summary Token signing keys
+// @Description Lists the public keys that verify BlogMate access tokens as a JSON Web Key Set, so that other services can validate the tokens by their kid header without sharing a secret.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} infrastructure.JWKSet "Public keys"
+// @Router /.well-known/jwks.json [get]
+func (cont *KeyController) HandleJWKS(ctx *gin.Context) {
+	set := infrastructure.JWKSet{Keys: []infrastructure.JWK{}}
+	if cont.keyring != nil {
+		set = cont.keyring.JWKS()
+	}
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, set)
+}
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"net/http"
+
+	usecase "github.com/yesetoda/BlogMate/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MagicLinkController struct {
+	usecase *usecase.MagicLinkUsecase
+	users   *UserController
+}
+
+// NewMagicLinkController logs users in through users, so link logins get the
+// same second factor check and tokens as password logins.
+func NewMagicLinkController(uc *usecase.MagicLinkUsecase, users *UserController) *MagicLinkController {
+	return &MagicLinkController{usecase: uc, users: users}
+}
+
+// MagicLinkRequestBody asks for a login link.
+type MagicLinkRequestBody struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// MagicLinkRedeemRequest logs in with the token of the emailed link and the
+// device token returned when it was asked for.
+type MagicLinkRedeemRequest struct {
+	Token       string `json:"token"`
+	DeviceToken string `json:"device_token" binding:"required"`
+}
+
+// HandleRequestMagicLink godoc
+// @Summary Ask for a login link
+// @Description Emails a single-use login link valid for 15 minutes. The answer is the same for unknown emails. Keep the device token, it is needed with the link to log in on this device.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body MagicLinkRequestBody true "Email of the account"
+// @Success 200 {object} domain.MagicLinkRequest "Device token"
+// @Failure 400 {object} map[string]string "Invalid email"
+// @Router /users/magic-link [post]
+func (cont *MagicLinkController) HandleRequestMagicLink(ctx *gin.Context) {
+	var request MagicLinkRequestBody
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	response, err := cont.usecase.Request(request.Email, ctx.Request.UserAgent(), ctx.ClientIP())
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, response)
+}
+
+// HandleRedeemMagicLink godoc
+// @Summary Log in with a login link
+// @Description Uses up the login link and answers like /users/login. The token of the link may be in the body or the query, the device token must be the one of the device that asked for the link.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param token query string false "Token of the link"
+// @Param request body MagicLinkRedeemRequest true "Link and device tokens"
+// @Success 200 {object} domain.TokenPair "access_token and refresh_token"
+// @Success 202 {object} domain.LoginChallengeResponse "Second factor required"
+// @Failure 400 {object} map[string]string "error"
+// @Failure 401 {object} map[string]string "Invalid, used or expired link"
+// @Router /users/magic-link/redeem [post]
+func (cont *MagicLinkController) HandleRedeemMagicLink(ctx *gin.Context) {
+	var request MagicLinkRedeemRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.Token == "" {
+		request.Token = ctx.Query("token")
+	}
+	user, err := cont.usecase.Redeem(request.Token, request.DeviceToken)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	cont.users.completeLogin(ctx, user)
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/yesetoda/BlogMate/infrastructure"
+	usecase "github.com/yesetoda/BlogMate/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MediaController struct {
+	usecase *usecase.MediaUsecase
+}
+
+func NewMediaController(uc *usecase.MediaUsecase) *MediaController {
+	return &MediaController{usecase: uc}
+}
+
+// HandleUploadMedia godoc
+// @Summary Upload media
+// @Description Uploads a JPEG, PNG, GIF or WebP image or a PDF document, told from its content. Images get a thumbnail. Files count against the storage quota of the user and are deleted after 24 hours unless a blog or the profile uses them.
+// @Tags media
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "File to upload"
+// @Success 201 {object} domain.Media "Uploaded media"
+// @Failure 400 {object} map[string]string "Missing, empty or unsupported file"
+// @Failure 413 {object} map[string]string "File too large or quota exceeded"
+// @Router /media [post]
+func (cont *MediaController) HandleUploadMedia(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	limit := cont.usecase.MaxUploadSize()
+	// leave room for the multipart envelope, the file itself is checked below
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, limit+1<<20)
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": usecase.ErrMediaTooLarge.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "upload the file in the file field of a multipart form"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	media, err := cont.usecase.Upload(claims.ID, header.Filename, data)
+	if errors.Is(err, usecase.ErrMediaTooLarge) || errors.Is(err, usecase.ErrMediaQuotaExceeded) {
+		ctx.IndentedJSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusCreated, media)
+}
+
+// HandleGetMedia godoc
+// @Summary Get media
+// @Description Serves an uploaded file, or the thumbnail of an image with size=thumbnail
+// @Tags media
+// @Produce octet-stream
+// @Param mediaId path string true "Media ID"
+// @Param size query string false "thumbnail for the thumbnail of an image"
+// @Success 200 {file} file "Content of the media"
+// @Failure 404 {object} map[string]string "No such media"
+// @Router /media/{mediaId} [get]
+func (cont *MediaController) HandleGetMedia(ctx *gin.Context) {
+	file, err := cont.usecase.Open(ctx.Param("mediaId"), ctx.Query("size") == "thumbnail")
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Body.Close()
+	disposition := "inline"
+	if !strings.HasPrefix(file.ContentType, "image/") {
+		disposition = "attachment"
+	}
+	ctx.DataFromReader(http.StatusOK, file.Size, file.ContentType, file.Body, map[string]string{
+		// the content of media never changes
+		"Cache-Control":           "public, max-age=31536000, immutable",
+		"Content-Disposition":     mime.FormatMediaType(disposition, map[string]string{"filename": file.Media.Filename}),
+		"Content-Security-Policy": "default-src 'none'; sandbox",
+		"X-Content-Type-Options":  "nosniff",
+	})
+}
+
+// HandleGetMyMedia godoc
+// @Summary List my media
+// @Description Lists the media of the authenticated user, newest first, with what uses them and how much of the quota they take, in bytes
+// @Tags media
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Media and usage"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /me/media [get]
+func (cont *MediaController) HandleGetMyMedia(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	media, usage, err := cont.usecase.GetMyMedia(claims.ID)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"media": media, "usage": usage})
+}
+
+// HandleDeleteMedia godoc
+// @Summary Delete media
+// @Description Deletes media of the authenticated user that no blog or profile uses anymore
+// @Tags media
+// @Produce json
+// @Security BearerAuth
+// @Param mediaId path string true "Media ID"
+// @Success 200 {object} map[string]string "Media deleted"
+// @Failure 400 {object} map[string]string "Media still used"
+// @Failure 404 {object} map[string]string "No such media"
+// @Router /media/{mediaId} [delete]
+func (cont *MediaController) HandleDeleteMedia(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	err = cont.usecase.DeleteMedia(claims.ID, ctx.Param("mediaId"))
+	if errors.Is(err, usecase.ErrMediaNotFound) {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Media deleted"})
+}
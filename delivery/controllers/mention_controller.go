@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	usecase "github.com/yesetoda/BlogMate/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MentionController struct {
+	usecase *usecase.MentionUsecase
+}
+
+func NewMentionController(uc *usecase.MentionUsecase) *MentionController {
+	return &MentionController{usecase: uc}
+}
+
+// HandleGetMyMentions godoc
+// @Summary List my mentions
+// @Description Retrieve the blogs and comments that mention the current user, newest first. Defaults: pageNumber=1, pageSize=10.
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Param pageNumber query int false "Page number"
+// @Param pageSize query int false "Number of items per page"
+// @Success 200 {array} domain.Mention "Mentions of the current user"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /me/mentions [get]
+func (cont *MentionController) HandleGetMyMentions(ctx *gin.Context) {
+	ipage, err := strconv.Atoi(ctx.Query("pageNumber"))
+	if err != nil || ipage < 1 {
+		ipage = 1
+	}
+	ipageSize, err := strconv.Atoi(ctx.Query("pageSize"))
+	if err != nil || ipageSize < 1 {
+		ipageSize = 10
+	}
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	mentions, err := cont.usecase.GetMentions(claims.ID, domain.PaginationInfo{Page: ipage, PageSize: ipageSize})
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, mentions)
+}
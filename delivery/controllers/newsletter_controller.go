@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	usecase "github.com/yesetoda/BlogMate/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NewsletterController struct {
+	usecase *usecase.NewsletterUsecase
+}
+
+func NewNewsletterController(uc *usecase.NewsletterUsecase) *NewsletterController {
+	return &NewsletterController{usecase: uc}
+}
+
+// NewsletterSubscription is the body of a newsletter subscription, with
+// either an author id or a tag.
+type NewsletterSubscription struct {
+	Email    string `json:"email" binding:"required"`
+	AuthorId string `json:"author_id"`
+	Tag      string `json:"tag"`
+}
+
+// HandleSubscribeNewsletter godoc
+// @Summary Subscribe to an author or a tag by email
+// @Description Readers without an account get an email when the author publishes, or a post with the tag is published. The subscription starts once the link sent to the address is opened.
+// @Tags Newsletter
+// @Accept json
+// @Produce json
+// @Param subscription body NewsletterSubscription true "Email and author id or tag"
+// @Success 202 {object} map[string]string "Confirmation email sent"
+// @Failure 400 {object} map[string]string "Invalid email, author or tag"
+// @Router /newsletter/subscribe [post]
+func (cont *NewsletterController) HandleSubscribeNewsletter(ctx *gin.Context) {
+	var request NewsletterSubscription
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	kind, target := domain.NewsletterAuthor, request.AuthorId
+	if request.Tag != "" {
+		kind, target = domain.NewsletterTag, request.Tag
+	}
+	if request.AuthorId != "" && request.Tag != "" {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": "subscribe to either an author or a tag"})
+		return
+	}
+	if err := cont.usecase.Subscribe(request.Email, kind, target); err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusAccepted, gin.H{"message": "Check your email to confirm the subscription"})
+}
+
+// HandleConfirmNewsletter godoc
+// @Summary Confirm a newsletter subscription
+// @Tags Newsletter
+// @Produce json
+// @Param token query string true "Confirmation token"
+// @Success 200 {object} map[string]string "Subscription confirmed"
+// @Failure 404 {object} map[string]string "Invalid or expired token"
+// @Router /newsletter/confirm [get]
+func (cont *NewsletterController) HandleConfirmNewsletter(ctx *gin.Context) {
+	if _, err := cont.usecase.Confirm(ctx.Query("token")); err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Subscription confirmed"})
+}
+
+// HandleUnsubscribeNewsletter godoc
+// @Summary Unsubscribe from a newsletter
+// @Description One-click unsubscribe with the token from the link in every newsletter email.
+// @Tags Newsletter
+// @Produce json
+// @Param token query string true "Unsubscribe token"
+// @Success 200 {object} map[string]string "Unsubscribed"
+// @Failure 404 {object} map[string]string "Invalid token"
+// @Router /newsletter/unsubscribe [get]
+// @Router /newsletter/unsubscribe [post]
+func (cont *NewsletterController) HandleUnsubscribeNewsletter(ctx *gin.Context) {
+	if err := cont.usecase.Unsubscribe(ctx.Query("token")); err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"message": "You will no longer receive these emails"})
+}
+
+// HandleGetMySubscribers godoc
+// @Summary List my newsletter subscribers
+// @Description Lists the readers subscribed to the current user by email, oldest first. Defaults: pageNumber=1, pageSize=50.
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Subscriber status (pending/confirmed/unsubscribed)"
+// @Param pageNumber query int false "Page number"
+// @Param pageSize query int false "Number of items per page"
+// @Success 200 {array} domain.NewsletterSubscriber "Subscribers"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /me/subscribers [get]
+func (cont *NewsletterController) HandleGetMySubscribers(ctx *gin.Context) {
+	ipage, err := strconv.Atoi(ctx.Query("pageNumber"))
+	if err != nil || ipage < 1 {
+		ipage = 1
+	}
+	ipageSize, err := strconv.Atoi(ctx.Query("pageSize"))
+	if err != nil || ipageSize < 1 {
+		ipageSize = 50
+	}
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	subscribers, err := cont.usecase.GetSubscribers(claims.ID, ctx.Query("status"), domain.PaginationInfo{Page: ipage, PageSize: ipageSize})
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, subscribers)
+}
+
+// HandleExportMySubscribers godoc
+// @Summary Export my newsletter subscribers
+// @Description Downloads all the subscribers of the current user as CSV (email, status, subscribed_at, confirmed_at).
+// @Tags Me
+// @Produce text/csv
+// @Security BearerAuth
+// @Success 200 {string} string "CSV file"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /me/subscribers/export [get]
+func (cont *NewsletterController) HandleExportMySubscribers(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	var out bytes.Buffer
+	if err := cont.usecase.ExportSubscribers(claims.ID, &out); err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Header("Content-Disposition", `attachment; filename="subscribers.csv"`)
+	ctx.Data(http.StatusOK, "text/csv; charset=utf-8", out.Bytes())
+}
+
+// HandleRemoveMySubscriber godoc
+// @Summary Remove one of my newsletter subscribers
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Param subscriberId path string true "Subscriber ID"
+// @Success 200 {object} map[string]string "Subscriber removed"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 404 {object} map[string]string "Subscriber not found"
+// @Router /me/subscribers/{subscriberId} [delete]
+func (cont *NewsletterController) HandleRemoveMySubscriber(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	if err := cont.usecase.RemoveSubscriber(claims.ID, ctx.Param("subscriberId")); err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Subscriber removed"})
+}
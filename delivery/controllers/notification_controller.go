@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	usecase "github.com/yesetoda/BlogMate/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+	usecase *usecase.NotificationUsecase
+}
+
+func NewNotificationController(uc *usecase.NotificationUsecase) *NotificationController {
+	return &NotificationController{usecase: uc}
+}
+
+// HandleGetMyNotifications godoc
+// @Summary List my notifications
+// @Description Retrieve the notifications of the current user, newest first, together with the unread count. Defaults: pageNumber=1, pageSize=10.
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Param unread query bool false "Only return unread notifications"
+// @Param pageNumber query int false "Page number"
+// @Param pageSize query int false "Number of items per page"
+// @Success 200 {object} map[string]interface{} "Notifications and unread count"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /me/notifications [get]
+func (cont *NotificationController) HandleGetMyNotifications(ctx *gin.Context) {
+	ipage, err := strconv.Atoi(ctx.Query("pageNumber"))
+	if err != nil || ipage < 1 {
+		ipage = 1
+	}
+	ipageSize, err := strconv.Atoi(ctx.Query("pageSize"))
+	if err != nil || ipageSize < 1 {
+		ipageSize = 10
+	}
+	unread, _ := strconv.ParseBool(ctx.Query("unread"))
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	notifications, err := cont.usecase.GetNotifications(claims.ID, domain.NotificationFilterOption{
+		UnreadOnly: unread,
+		Pagination: domain.PaginationInfo{Page: ipage, PageSize: ipageSize},
+	})
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	count, err := cont.usecase.CountUnread(claims.ID)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"notifications": notifications, "unread": count})
+}
+
+// HandleMarkNotificationRead godoc
+// @Summary Mark a notification as read
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Param notificationId path string true "Notification ID"
+// @Success 200 {object} map[string]interface{} "Notification marked as read"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 404 {object} map[string]string "Notification not found or already read"
+// @Router /me/notifications/{notificationId}/read [patch]
+func (cont *NotificationController) HandleMarkNotificationRead(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	updated, err := cont.usecase.MarkRead(claims.ID, []string{ctx.Param("notificationId")})
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if updated == 0 {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": "notification not found or already read"})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+// HandleMarkAllNotificationsRead godoc
+// @Summary Mark all my notifications as read
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Number of notifications marked as read"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /me/notifications/read [patch]
+func (cont *NotificationController) HandleMarkAllNotificationsRead(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	updated, err := cont.usecase.MarkRead(claims.ID, nil)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Notifications marked as read", "updated": updated})
+}
+
+// HandleGetNotificationPreferences godoc
+// @Summary Get my notification preferences
+// @Description Event types missing from the response are enabled.
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.NotificationPreferences "Notification preferences"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /me/notifications/preferences [get]
+func (cont *NotificationController) HandleGetNotificationPreferences(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	prefs, err := cont.usecase.GetPreferences(claims.ID)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, prefs)
+}
+
+// HandleUpdateNotificationPreferences godoc
+// @Summary Update my notification preferences
+// @Description Turn event types (comment, reply, reaction, follow, mention, review) on or off. Types that are not sent keep their setting.
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param preferences body domain.NotificationPreferences true "Event types to turn on or off"
+// @Success 200 {object} domain.NotificationPreferences "Updated notification preferences"
+// @Failure 400 {object} map[string]string "Invalid request payload or unknown event type"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Router /me/notifications/preferences [put]
+func (cont *NotificationController) HandleUpdateNotificationPreferences(ctx *gin.Context) {
+	var prefs domain.NotificationPreferences
+	if err := ctx.ShouldBindJSON(&prefs); err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	prefs, err = cont.usecase.UpdatePreferences(claims.ID, prefs.Events)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, prefs)
+}
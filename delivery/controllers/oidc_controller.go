@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/yesetoda/BlogMate/infrastructure"
+	usecase "github.com/yesetoda/BlogMate/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCController struct {
+	usecase *usecase.OIDCUsecase
+	users   *UserController
+}
+
+// NewOIDCController logs users in through users, so provider logins get the
+// same second factor check and tokens as password logins.
+func NewOIDCController(uc *usecase.OIDCUsecase, users *UserController) *OIDCController {
+	return &OIDCController{usecase: uc, users: users}
+}
+
+// HandleGetProviders godoc
+// @Summary List login providers
+// @Description Lists the OpenID Connect providers users can log in with.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string][]string "Provider names"
+// @Router /auth/oidc/providers [get]
+func (cont *OIDCController) HandleGetProviders(ctx *gin.Context) {
+	ctx.IndentedJSON(http.StatusOK, gin.H{"providers": cont.usecase.Providers()})
+}
+
+// HandleLogin godoc
+// @Summary Log in with a provider
+// @Description Redirects to the login page of the provider (authorization code flow with PKCE). It redirects back to the callback.
+// @Tags auth
+// @Param provider path string true "Provider name"
+// @Success 302 {string} string "Redirect to the provider"
+// @Failure 404 {object} map[string]string "Unknown provider"
+// @Router /auth/oidc/{provider}/login [get]
+func (cont *OIDCController) HandleLogin(ctx *gin.Context) {
+	authURL, err := cont.usecase.Begin(ctx.Param("provider"), "")
+	if err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// HandleCallback godoc
+// @Summary Provider callback
+// @Description Finishes a login or link at a provider. A first login creates the account, or links an existing one with the same verified email. Logins answer like /users/login, links with a message.
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param state query string true "State of the login"
+// @Param code query string true "Authorization code"
+// @Success 200 {object} map[string]string "Tokens, or the link message"
+// @Success 202 {object} domain.LoginChallengeResponse "Second factor required"
+// @Failure 400 {object} map[string]string "Login failed"
+// @Router /auth/oidc/{provider}/callback [get]
+func (cont *OIDCController) HandleCallback(ctx *gin.Context) {
+	if message := ctx.Query("error"); message != "" {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": message, "description": ctx.Query("error_description")})
+		return
+	}
+	result, err := cont.usecase.Callback(ctx.Param("provider"), ctx.Query("state"), ctx.Query("code"))
+	if err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if result.Linked {
+		ctx.IndentedJSON(http.StatusOK, gin.H{"message": ctx.Param("provider") + " account linked"})
+		return
+	}
+	cont.users.completeLogin(ctx, result.User)
+}
+
+// HandleLinkProvider godoc
+// @Summary Link a provider to my account
+// @Description Returns the URL of the provider to log in at. Its account is then linked, so the user can log in with it.
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider name"
+// @Success 200 {object} map[string]string "URL to open"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 404 {object} map[string]string "Unknown provider"
+// @Router /me/oidc/{provider}/link [post]
+func (cont *OIDCController) HandleLinkProvider(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	authURL, err := cont.usecase.Begin(ctx.Param("provider"), claims.ID)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"url": authURL})
+}
+
+// HandleGetMyIdentities godoc
+// @Summary List my linked providers
+// @Description Lists the provider accounts the current user can log in with.
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.Identity "Linked provider accounts"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /me/identities [get]
+func (cont *OIDCController) HandleGetMyIdentities(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	identities, err := cont.usecase.GetIdentities(claims.ID)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, identities)
+}
+
+// HandleUnlinkProvider godoc
+// @Summary Unlink a provider from my account
+// @Description Removes a linked provider account. The last one stays until the user has a password.
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider name"
+// @Success 200 {object} map[string]string "Unlinked"
+// @Failure 400 {object} map[string]string "Not linked, or the last way to log in"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Router /me/identities/{provider} [delete]
+func (cont *OIDCController) HandleUnlinkProvider(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	if err := cont.usecase.Unlink(claims.ID, ctx.Param("provider")); err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"message": ctx.Param("provider") + " account unlinked"})
+}
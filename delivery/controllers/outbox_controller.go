@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/yesetoda/BlogMate/domain"
+	usecase "github.com/yesetoda/BlogMate/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OutboxController struct {
+	usecase *usecase.OutboxUsecase
+}
+
+func NewOutboxController(uc *usecase.OutboxUsecase) *OutboxController {
+	return &OutboxController{usecase: uc}
+}
+
+// HandleGetOutboxMessages godoc
+// @Summary List outbox messages
+// @Description Lists the emails, webhook events and notifications waiting in the outbox, newest first. Defaults: status=dead, pageNumber=1, pageSize=20.
+// @Tags Outbox
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Message status (pending/delivered/dead)"
+// @Param pageNumber query int false "Page number"
+// @Param pageSize query int false "Number of items per page"
+// @Success 200 {array} domain.OutboxMessage "Outbox messages"
+// @Failure 400 {object} map[string]string "Invalid status"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 403 {string} string "Forbidden - admin only"
+// @Router /outbox [get]
+func (cont *OutboxController) HandleGetOutboxMessages(ctx *gin.Context) {
+	status := ctx.DefaultQuery("status", domain.OutboxDead)
+	if status != domain.OutboxPending && status != domain.OutboxDelivered && status != domain.OutboxDead {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": "status must be pending, delivered or dead"})
+		return
+	}
+	ipage, err := strconv.Atoi(ctx.Query("pageNumber"))
+	if err != nil || ipage < 1 {
+		ipage = 1
+	}
+	ipageSize, err := strconv.Atoi(ctx.Query("pageSize"))
+	if err != nil || ipageSize < 1 {
+		ipageSize = 20
+	}
+	messages, err := cont.usecase.GetMessages(status, domain.PaginationInfo{Page: ipage, PageSize: ipageSize})
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, messages)
+}
+
+// HandleRetryOutboxMessage godoc
+// @Summary Retry a dead outbox message
+// @Description Moves a dead-lettered message back to the queue with a fresh attempt count.
+// @Tags Outbox
+// @Produce json
+// @Security BearerAuth
+// @Param messageId path string true "Outbox message ID"
+// @Success 202 {object} map[string]string "Message queued again"
+// @Failure 404 {object} map[string]string "No dead message with this id"
+// @Router /outbox/{messageId}/retry [post]
+func (cont *OutboxController) HandleRetryOutboxMessage(ctx *gin.Context) {
+	if err := cont.usecase.Retry(ctx.Param("messageId")); err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusAccepted, gin.H{"message": "Message queued again"})
+}
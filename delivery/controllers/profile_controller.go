@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	usecase "github.com/yesetoda/BlogMate/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProfileController struct {
+	usecase *usecase.ProfileUsecase
+}
+
+func NewProfileController(uc *usecase.ProfileUsecase) *ProfileController {
+	return &ProfileController{usecase: uc}
+}
+
+// HandleGetPublicProfile godoc
+// @Summary Get the profile of a user
+// @Description Returns the public profile of the user with the username: display name, bio, avatar, location, website, social links and pinned posts. Credentials, tokens and the email are never included.
+// @Tags users
+// @Produce json
+// @Param id path string true "Username"
+// @Success 200 {object} domain.PublicProfile "Public profile"
+// @Failure 404 {object} map[string]string "No such user"
+// @Router /users/{id}/profile [get]
+func (cont *ProfileController) HandleGetPublicProfile(ctx *gin.Context) {
+	// the path parameter shares its name with the other /users/:id routes
+	profile, err := cont.usecase.GetPublicProfile(ctx.Param("id"))
+	if err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, profile)
+}
+
+// HandleGetMyProfile godoc
+// @Summary Get my profile
+// @Description Returns the profile of the authenticated user as they edit it
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.Profile "Profile"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /me/profile [get]
+func (cont *ProfileController) HandleGetMyProfile(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	profile, err := cont.usecase.GetMyProfile(claims.ID)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, profile)
+}
+
+// HandleUpdateMyProfile godoc
+// @Summary Update my profile
+// @Description Replaces the profile of the authenticated user. The display name is at most 50 characters, the bio 500 and the location 100. Links are http or https URLs, social links are keyed by network and at most 3 of the user's own posts can be pinned.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param profile body domain.Profile true "Profile"
+// @Success 200 {object} domain.Profile "Updated profile"
+// @Failure 400 {object} map[string]string "Invalid profile"
+// @Router /me/profile [put]
+func (cont *ProfileController) HandleUpdateMyProfile(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	var profile domain.Profile
+	if err := ctx.ShouldBindJSON(&profile); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	profile, err = cont.usecase.UpdateProfile(claims.ID, profile)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, profile)
+}
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	usecase "github.com/yesetoda/BlogMate/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleController struct {
+	usecase *usecase.RoleUsecase
+}
+
+func NewRoleController(uc *usecase.RoleUsecase) *RoleController {
+	return &RoleController{usecase: uc}
+}
+
+// RoleRequest describes a custom role.
+type RoleRequest struct {
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// AssignRolesRequest replaces the roles of a user. No roles makes them an
+// author.
+type AssignRolesRequest struct {
+	Roles []string `json:"roles"`
+}
+
+// HandleGetRoles godoc
+// @Summary List roles
+// @Description Lists the built-in roles (reader, author, editor, moderator, admin) and the custom ones with the permissions they grant.
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.Role "Roles"
+// @Failure 403 {object} map[string]string "Missing permission roles:manage"
+// @Router /roles [get]
+func (cont *RoleController) HandleGetRoles(ctx *gin.Context) {
+	roles, err := cont.usecase.GetRoles()
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, roles)
+}
+
+// HandleGetPermissions godoc
+// @Summary List permissions
+// @Description Lists the permissions roles can grant.
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string][]string "Permissions"
+// @Failure 403 {object} map[string]string "Missing permission roles:manage"
+// @Router /roles/permissions [get]
+func (cont *RoleController) HandleGetPermissions(ctx *gin.Context) {
+	ctx.IndentedJSON(http.StatusOK, gin.H{"permissions": domain.Permissions})
+}
+
+// HandleSaveRole godoc
+// @Summary Create or update a custom role
+// @Description Creates the custom role or replaces its permissions. Built-in roles cannot be changed.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Role name"
+// @Param role body RoleRequest true "Description and permissions"
+// @Success 200 {object} domain.Role "Saved role"
+// @Failure 400 {object} map[string]string "Invalid name or permissions"
+// @Failure 403 {object} map[string]string "Missing permission roles:manage"
+// @Router /roles/{name} [put]
+func (cont *RoleController) HandleSaveRole(ctx *gin.Context) {
+	var request RoleRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	role, err := cont.usecase.SaveRole(domain.Role{Name: ctx.Param("name"), Description: request.Description, Permissions: request.Permissions})
+	if err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, role)
+}
+
+// HandleDeleteRole godoc
+// @Summary Delete a custom role
+// @Description Deletes a custom role. Users who had it lose its permissions.
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Role name"
+// @Success 200 {object} map[string]string "Deleted"
+// @Failure 400 {object} map[string]string "Built-in or unknown role"
+// @Failure 403 {object} map[string]string "Missing permission roles:manage"
+// @Router /roles/{name} [delete]
+func (cont *RoleController) HandleDeleteRole(ctx *gin.Context) {
+	if err := cont.usecase.DeleteRole(ctx.Param("name")); err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Role deleted"})
+}
+
+// HandleAssignRoles godoc
+// @Summary Set the roles of a user
+// @Description Replaces the roles of a user, who is logged out to log in again with them. Nobody can change their own roles.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param roles body AssignRolesRequest true "Roles"
+// @Success 200 {object} domain.AccountUser "User with the new roles"
+// @Failure 400 {object} map[string]string "Unknown role or user"
+// @Failure 403 {object} map[string]string "Missing permission roles:manage"
+// @Router /users/{id}/roles [put]
+func (cont *RoleController) HandleAssignRoles(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	var request AssignRolesRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user, err := cont.usecase.AssignRoles(claims.ID, ctx.Param("id"), request.Roles)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, user.Account())
+}
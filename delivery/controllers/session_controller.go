@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/yesetoda/BlogMate/infrastructure"
+	usecase "github.com/yesetoda/BlogMate/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionController struct {
+	usecase *usecase.SessionUsecase
+}
+
+func NewSessionController(uc *usecase.SessionUsecase) *SessionController {
+	return &SessionController{usecase: uc}
+}
+
+// HandleGetMySessions godoc
+// @Summary List my sessions
+// @Description Lists the devices the current user is logged in on, most recently used first. The session of the token used for the request is marked as current.
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.Session "Active sessions"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /me/sessions [get]
+func (cont *SessionController) HandleGetMySessions(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	sessions, err := cont.usecase.GetSessions(claims.ID, claims.SessionId)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, sessions)
+}
+
+// HandleRevokeMySession godoc
+// @Summary Revoke one of my sessions
+// @Description Logs a device out, its refresh token stops working.
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} map[string]string "Session revoked"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 404 {object} map[string]string "Session not found or already revoked"
+// @Router /me/sessions/{sessionId} [delete]
+func (cont *SessionController) HandleRevokeMySession(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	if err := cont.usecase.Revoke(claims.ID, ctx.Param("sessionId"), usecase.SessionRevoked); err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// HandleRevokeMySessions godoc
+// @Summary Revoke all my sessions
+// @Description Logs every device out, including this one.
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Number of revoked sessions"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /me/sessions [delete]
+func (cont *SessionController) HandleRevokeMySessions(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	revoked, err := cont.usecase.RevokeAll(claims.ID, usecase.SessionRevoked)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"revoked": revoked})
+}
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yesetoda/BlogMate/infrastructure"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const streamKeepAlive = 25 * time.Second
+
+type StreamController struct {
+	broker   infrastructure.Broker
+	upgrader websocket.Upgrader
+}
+
+func NewStreamController(broker infrastructure.Broker) *StreamController {
+	return &StreamController{
+		broker: broker,
+		upgrader: websocket.Upgrader{
+			// clients authenticate with a bearer token, not cookies, so
+			// connections from other origins cannot ride on a session
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// streamTopics reads the channels a client asked for. Without any, the client
+// gets its own user channel.
+func streamTopics(ctx *gin.Context, userId string) []string {
+	topics := []string{}
+	for _, blogId := range ctx.QueryArray("blog") {
+		topics = append(topics, infrastructure.BlogTopic(blogId))
+	}
+	for _, commentId := range ctx.QueryArray("thread") {
+		topics = append(topics, infrastructure.ThreadTopic(commentId))
+	}
+	if me, _ := strconv.ParseBool(ctx.Query("me")); me || len(topics) == 0 {
+		topics = append(topics, infrastructure.UserTopic(userId))
+	}
+	return topics
+}
+
+// HandleStream godoc
+// @Summary Subscribe to live updates with Server-Sent Events
+// @Description Streams comment, reply, reaction and notification events. Subscribe to blogs with blog=, to the replies below a comment with thread= and to your own notifications with me=true (the default when nothing else is asked for). Browsers may pass the token as access_token.
+// @Tags Realtime
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param blog query []string false "Blog IDs" collectionFormat(multi)
+// @Param thread query []string false "Comment IDs whose replies to follow" collectionFormat(multi)
+// @Param me query bool false "Include the current user's channel"
+// @Param access_token query string false "Access token for clients that cannot set headers"
+// @Success 200 {object} infrastructure.Event "Stream of events"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Router /stream [get]
+func (cont *StreamController) HandleStream(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	events, unsubscribe := cont.broker.Subscribe(streamTopics(ctx, claims.ID)...)
+	defer unsubscribe()
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			ctx.SSEvent(event.Type, event)
+			return true
+		case <-keepAlive.C:
+			ctx.SSEvent("ping", gin.H{"date": time.Now()})
+			return true
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// HandleWebSocket godoc
+// @Summary Subscribe to live updates over a WebSocket
+// @Description Same channels as /stream. Every event is sent as a JSON text message; messages from the client are ignored.
+// @Tags Realtime
+// @Security BearerAuth
+// @Param blog query []string false "Blog IDs" collectionFormat(multi)
+// @Param thread query []string false "Comment IDs whose replies to follow" collectionFormat(multi)
+// @Param me query bool false "Include the current user's channel"
+// @Param access_token query string false "Access token for clients that cannot set headers"
+// @Success 101 {string} string "Switching protocols"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Router /ws [get]
+func (cont *StreamController) HandleWebSocket(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	conn, err := cont.upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	events, unsubscribe := cont.broker.Subscribe(streamTopics(ctx, claims.ID)...)
+	defer unsubscribe()
+
+	// the reader only notices when the client goes away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	usecase "github.com/yesetoda/BlogMate/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorController struct {
+	usecase *usecase.TwoFactorUsecase
+}
+
+func NewTwoFactorController(uc *usecase.TwoFactorUsecase) *TwoFactorController {
+	return &TwoFactorController{usecase: uc}
+}
+
+// TwoFactorCodeRequest confirms a step with a TOTP code.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorReauthRequest confirms a sensitive change with the password and a
+// TOTP or recovery code.
+type TwoFactorReauthRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code"`
+}
+
+func claimsUser(claims *domain.Claims) domain.User {
+	return domain.User{ID: claims.ID, Email: claims.Email, Username: claims.Username, IsAdmin: claims.IsAdmin}
+}
+
+// HandleGetMyTwoFactor godoc
+// @Summary Two-factor status
+// @Description Tells whether two-factor authentication is enabled, required for the current user, and how many recovery codes are left.
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.TwoFactorStatus "Two-factor status"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /me/2fa [get]
+func (cont *TwoFactorController) HandleGetMyTwoFactor(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	status, err := cont.usecase.Status(claimsUser(claims))
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, status)
+}
+
+// HandleEnrollTwoFactor godoc
+// @Summary Start two-factor enrollment
+// @Description Creates a TOTP secret and returns it with an otpauth:// provisioning URI to show as a QR code. It is only used after a code is verified at /me/2fa/activate.
+// @Tags Me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.TwoFactorEnrollment "Secret and provisioning URI"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 409 {object} map[string]string "Already enabled"
+// @Router /me/2fa/enroll [post]
+func (cont *TwoFactorController) HandleEnrollTwoFactor(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	enrollment, err := cont.usecase.Enroll(claimsUser(claims))
+	if err != nil {
+		ctx.IndentedJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, enrollment)
+}
+
+// HandleActivateTwoFactor godoc
+// @Summary Activate two-factor authentication
+// @Description Verifies a code of the enrolled secret, enables two-factor authentication and returns the recovery codes. They are shown only once.
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code body TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} map[string][]string "Recovery codes"
+// @Failure 400 {object} map[string]string "Invalid code"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Router /me/2fa/activate [post]
+func (cont *TwoFactorController) HandleActivateTwoFactor(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	var request TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	codes, err := cont.usecase.Activate(claims.ID, request.Code)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// HandleDisableTwoFactor godoc
+// @Summary Disable two-factor authentication
+// @Description Turns two-factor authentication off. The password and a TOTP or recovery code are required.
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param reauth body TwoFactorReauthRequest true "Password and code"
+// @Success 200 {object} map[string]string "Disabled"
+// @Failure 400 {object} map[string]string "Invalid password or code"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Router /me/2fa/disable [post]
+func (cont *TwoFactorController) HandleDisableTwoFactor(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	var request TwoFactorReauthRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := cont.usecase.Disable(claims.ID, request.Password, request.Code); err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// HandleRegenerateRecoveryCodes godoc
+// @Summary Replace my recovery codes
+// @Description Replaces the recovery codes with new ones, the old ones stop working. The password and a TOTP or recovery code are required.
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param reauth body TwoFactorReauthRequest true "Password and code"
+// @Success 200 {object} map[string][]string "Recovery codes"
+// @Failure 400 {object} map[string]string "Invalid password or code"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Router /me/2fa/recovery-codes [post]
+func (cont *TwoFactorController) HandleRegenerateRecoveryCodes(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	var request TwoFactorReauthRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	codes, err := cont.usecase.RegenerateRecoveryCodes(claims.ID, request.Password, request.Code)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// HandleResetTwoFactor godoc
+// @Summary Reset the two-factor authentication of a user
+// @Description Removes the second factor of a user who lost it, so they can log in with their password and enroll again. The admin confirms with their own password and code.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param reauth body TwoFactorReauthRequest true "Password and code of the admin"
+// @Success 200 {object} map[string]string "Reset"
+// @Failure 400 {object} map[string]string "Invalid password or code"
+// @Failure 403 {object} map[string]string "Forbidden - admin only"
+// @Router /users/{id}/2fa [delete]
+func (cont *TwoFactorController) HandleResetTwoFactor(ctx *gin.Context) {
+	claims, err := infrastructure.GetClaims(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not get the claims"})
+		return
+	}
+	var request TwoFactorReauthRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := cont.usecase.Reset(claims.ID, request.Password, request.Code, ctx.Param("id")); err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Two-factor authentication reset"})
+}
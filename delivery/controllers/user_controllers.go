@@ -1,22 +1,24 @@
 package controllers
 
 import (
-	"fmt"
+	"errors"
 	"log"
 	"net/http"
-	"time"
+	"strconv"
 
-	"github.com/yesetoda/BlogMate/config"
 	"github.com/yesetoda/BlogMate/domain"
 	"github.com/yesetoda/BlogMate/infrastructure"
+	usecase "github.com/yesetoda/BlogMate/usecases"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserController struct {
 	userUsecase domain.UserUsecase
+	sessions    *usecase.SessionUsecase
+	revoker     domain.TokenRevoker
+	twoFactor   *usecase.TwoFactorUsecase
+	loginGuard  *usecase.LoginGuardUsecase
 }
 
 func NewUserController(userUsecase domain.UserUsecase) *UserController {
@@ -26,6 +28,28 @@ func NewUserController(userUsecase domain.UserUsecase) *UserController {
 
 }
 
+// SetSessions makes logins open a session with a refresh token.
+func (c *UserController) SetSessions(sessions *usecase.SessionUsecase) {
+	c.sessions = sessions
+}
+
+// SetTwoFactor asks users who enabled two-factor authentication for a code
+// after their password.
+func (c *UserController) SetTwoFactor(twoFactor *usecase.TwoFactorUsecase) {
+	c.twoFactor = twoFactor
+}
+
+// SetLoginGuard slows down and locks out repeated failed logins and token
+// refreshes.
+func (c *UserController) SetLoginGuard(guard *usecase.LoginGuardUsecase) {
+	c.loginGuard = guard
+}
+
+// SetTokenRevoker makes logout revoke the access token it was called with.
+func (c *UserController) SetTokenRevoker(revoker domain.TokenRevoker) {
+	c.revoker = revoker
+}
+
 // Register godoc
 // @Summary      Register a new user
 // @Description  Creates a new user account
@@ -153,13 +177,15 @@ func (c *UserController) ForgetPasswordUser(ctx *gin.Context) {
 
 // LoginUser godoc
 // @Summary      Login user
-// @Description  Authenticates a user and returns a JWT access token
+// @Description  Authenticates a user and returns a JWT access token and a refresh token for a new session on this device. Users with two-factor authentication get a challenge token instead, to send with a code to /users/login/2fa.
 // @Tags         users
 // @Accept       json
 // @Produce      json
 // @Param        user body domain.User true "Login info"
-// @Success      200 {object} map[string]string "access_token"
-// @Failure      406 {object} map[string]string "error"
+// @Success      200 {object} domain.TokenPair "access_token and refresh_token"
+// @Success      202 {object} domain.LoginChallengeResponse "A second factor is required"
+// @Failure      406 {object} map[string]interface{} "error, and captcha_required once the account failed too often"
+// @Failure      429 {object} domain.LoginBlocked "Too many failed logins, retry after the given seconds"
 // @Router       /users/login [post]
 func (c *UserController) LoginUser(ctx *gin.Context) {
 	user := &domain.User{}
@@ -167,21 +193,156 @@ func (c *UserController) LoginUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": err.Error()})
 		return
 	}
+	if c.loginGuard != nil {
+		if blocked := c.loginGuard.Check(user.Username, user.Email, ctx.ClientIP()); blocked != nil {
+			refuseBlocked(ctx, blocked)
+			return
+		}
+	}
+	if c.sessions != nil || c.twoFactor != nil {
+		authenticated, err := c.userUsecase.Authenticate(user.Username, user.Password, user.Email)
+		if err != nil {
+			c.loginFailed(ctx, user, err)
+			return
+		}
+		c.completeLogin(ctx, authenticated)
+		return
+	}
 	access_token, err := c.userUsecase.LoginUser(user.Username, user.Password, user.Email)
 	if err != nil {
-		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": err.Error()})
+		c.loginFailed(ctx, user, err)
 		return
 	}
+	if c.loginGuard != nil {
+		c.loginGuard.Succeeded(user.Username, user.Email)
+	}
 	ctx.IndentedJSON(http.StatusOK, gin.H{"access_token": access_token})
 }
 
+// loginFailed counts a wrong login and answers it. A right password of an
+// account that is not activated is not counted.
+func (c *UserController) loginFailed(ctx *gin.Context, user *domain.User, err error) {
+	response := gin.H{"error": err.Error()}
+	if c.loginGuard != nil && !errors.Is(err, usecase.ErrAccountNotActivated) {
+		if c.loginGuard.Failed(user.Username, user.Email, ctx.ClientIP()) {
+			response["captcha_required"] = true
+		}
+	}
+	ctx.JSON(http.StatusNotAcceptable, response)
+}
+
+// loginSucceeded forgets the failed logins of the user once the login got
+// its tokens, not after its password alone.
+func (c *UserController) loginSucceeded(user domain.User) {
+	if c.loginGuard != nil {
+		c.loginGuard.SucceededUser(user.ID)
+	}
+}
+
+// refuseBlocked answers a login refused for too many failures.
+func refuseBlocked(ctx *gin.Context, blocked *domain.LoginBlocked) {
+	ctx.Header("Retry-After", strconv.Itoa(blocked.RetryAfter))
+	ctx.JSON(http.StatusTooManyRequests, blocked)
+}
+
+// LoginTwoFactor godoc
+// @Summary      Complete a two-factor login
+// @Description  Exchanges the challenge token of a login and a TOTP code, or an unused recovery code, for the tokens of the login. A challenge expires after 5 minutes or 5 wrong codes.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        challenge body TwoFactorLoginRequest true "Challenge token and code"
+// @Success      200 {object} domain.TokenPair "access_token and refresh_token"
+// @Failure      400 {object} map[string]string "error"
+// @Failure      401 {object} map[string]string "error"
+// @Failure      429 {object} domain.LoginBlocked "Too many failed logins, retry after the given seconds"
+// @Router       /users/login/2fa [post]
+func (c *UserController) LoginTwoFactor(ctx *gin.Context) {
+	var request TwoFactorLoginRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if c.twoFactor == nil {
+		ctx.JSON(http.StatusNotImplemented, gin.H{"error": "two-factor authentication is not enabled"})
+		return
+	}
+	user, err := c.twoFactor.CompleteLogin(request.ChallengeToken, request.Code, ctx.ClientIP())
+	var blocked *usecase.LoginBlockedError
+	if errors.As(err, &blocked) {
+		refuseBlocked(ctx, blocked.Blocked)
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	c.issueTokens(ctx, user, true)
+}
+
+// TwoFactorLoginRequest is the second step of a two-factor login.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// completeLogin answers a login that passed its first factor, with a
+// challenge for the second one when the user enabled it.
+func (c *UserController) completeLogin(ctx *gin.Context, user domain.User) {
+	if c.twoFactor != nil {
+		challenge, required, err := c.twoFactor.StartLogin(user)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if required {
+			ctx.IndentedJSON(http.StatusAccepted, challenge)
+			return
+		}
+	}
+	c.issueTokens(ctx, user, false)
+}
+
+// issueTokens answers a login with the tokens of a new session, or with an
+// access token alone when sessions are off.
+func (c *UserController) issueTokens(ctx *gin.Context, user domain.User, twoFactor bool) {
+	if c.sessions != nil {
+		tokens, err := c.sessions.StartSession(user, ctx.Request.UserAgent(), ctx.ClientIP(), twoFactor)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.loginSucceeded(user)
+		ctx.IndentedJSON(http.StatusOK, tokens)
+		return
+	}
+	accessToken, err := infrastructure.GenerateAccessToken(&user, "", twoFactor)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.loginSucceeded(user)
+	ctx.IndentedJSON(http.StatusOK, gin.H{"access_token": accessToken})
+}
+
 //logout user
 
 func (c *UserController) LogoutUser(ctx *gin.Context) {
-	email := ctx.MustGet("claims").(*domain.Claims).Email
-	err := c.userUsecase.Logout(email)
+	claims := ctx.MustGet("claims").(*domain.Claims)
+	err := c.userUsecase.Logout(claims.Email)
 	if err != nil {
 		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": err.Error()})
+		return
+	}
+	if c.sessions != nil && claims.SessionId != "" {
+		if err := c.sessions.Revoke(claims.ID, claims.SessionId, usecase.SessionLoggedOut); err != nil {
+			log.Println("revoking session", claims.SessionId, "failed:", err)
+		}
+	}
+	if c.revoker != nil && claims.Id != "" {
+		if err := c.revoker.RevokeToken(claims); err != nil {
+			log.Println("revoking token", claims.Id, "failed:", err)
+		}
 	}
 	ctx.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 
@@ -194,7 +355,7 @@ func (c *UserController) LogoutUser(ctx *gin.Context) {
 // @Produce      json
 // @Param        username query string false "Username"
 // @Param        email query string false "Email"
-// @Success      200 {array} domain.User "List of users"
+// @Success      200 {array} domain.PublicUser "List of users"
 // @Failure      404 {object} map[string]string "error"
 // @Router       /users [get]
 // logout user
@@ -207,7 +368,11 @@ func (c *UserController) GetUsers(ctx *gin.Context) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
-		ctx.IndentedJSON(http.StatusOK, users)
+		public := make([]domain.PublicUser, 0, len(users))
+		for _, user := range users {
+			public = append(public, user.Public())
+		}
+		ctx.IndentedJSON(http.StatusOK, public)
 		return
 	} else if username != "" {
 		user, err := c.userUsecase.GetByUsername(username)
@@ -215,7 +380,7 @@ func (c *UserController) GetUsers(ctx *gin.Context) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
-		ctx.IndentedJSON(http.StatusOK, user)
+		ctx.IndentedJSON(http.StatusOK, user.Public())
 		return
 	} else if email != "" {
 		user, err := c.userUsecase.GetByEmail(email)
@@ -223,7 +388,7 @@ func (c *UserController) GetUsers(ctx *gin.Context) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
-		ctx.IndentedJSON(http.StatusOK, user)
+		ctx.IndentedJSON(http.StatusOK, user.Public())
 		return
 	}
 	ctx.JSON(http.StatusNotFound, gin.H{"error": "page not found"})
@@ -235,7 +400,7 @@ func (c *UserController) GetUsers(ctx *gin.Context) {
 // @Tags         users
 // @Produce      json
 // @Param        id path string true "User ID"
-// @Success      200 {object} domain.User "User data"
+// @Success      200 {object} domain.PublicUser "User data"
 // @Failure      404 {object} map[string]string "error"
 // @Router       /users/{id} [get]
 func (c *UserController) GetUserByID(ctx *gin.Context) {
@@ -245,7 +410,7 @@ func (c *UserController) GetUserByID(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
-	ctx.IndentedJSON(http.StatusOK, user)
+	ctx.IndentedJSON(http.StatusOK, user.Public())
 }
 
 // DeleteUser godoc
@@ -275,7 +440,7 @@ func (c *UserController) DeleteUser(ctx *gin.Context) {
 // @Produce      json
 // @Param        id path string true "User ID"
 // @Param        user body domain.User true "Updated user info"
-// @Success      200 {object} domain.User "Updated user data"
+// @Success      200 {object} domain.AccountUser "Updated user data"
 // @Failure      406 {object} map[string]string "error"
 // @Failure      500 {object} map[string]string "error"
 // @Router       /users/{id} [put]
@@ -291,7 +456,7 @@ func (c *UserController) UpdateProfiles(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	ctx.IndentedJSON(http.StatusOK, updatedUser)
+	ctx.IndentedJSON(http.StatusOK, updatedUser.Account())
 }
 
 func (c *UserController) ChangePassword(ctx *gin.Context) {
@@ -310,83 +475,69 @@ func (c *UserController) ChangePassword(ctx *gin.Context) {
 
 // RefreshAccessToken godoc
 // @Summary      Refresh access token
-// @Description  Refreshes the access token using the refresh token
+// @Description  Exchanges a refresh token for a new access token and a new refresh token. Each refresh token works once, reusing one revokes its session.
 // @Tags         users
 // @Accept       json
 // @Produce      json
-// @Param        uid path string true "User ID"
-// @Param        pwd body object true "Password"
-// @Success      200 {object} map[string]string "refreshed access token"
+// @Param        token body RefreshRequest true "Refresh token"
+// @Success      200 {object} domain.TokenPair "New tokens"
+// @Failure      400 {object} map[string]string "error"
 // @Failure      401 {object} map[string]string "error"
-// @Failure      403 {object} map[string]string "error"
-// @Failure      500 {object} map[string]string "error"
-// @Router       /users/{uid}/refresh [post]
+// @Failure      429 {object} domain.LoginBlocked "Too many invalid refresh tokens from this address"
+// @Router       /users/refresh [post]
 func (c *UserController) RefreshAccessToken(ctx *gin.Context) {
-	configJwt, err := config.LoadConfig()
-	if err != nil {
-		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+	var request RefreshRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	jwtSecret := []byte(configJwt.JWT)
-	type Pass struct {
-		Password string `json:"password"`
+	if c.sessions == nil {
+		ctx.IndentedJSON(http.StatusNotImplemented, gin.H{"error": "sessions are not enabled"})
+		return
 	}
-	var NUID Pass
-	err = ctx.ShouldBindJSON(&NUID)
-	TheUser, err := c.userUsecase.GetByID(ctx.Param("uid"))
-	if err != nil {
-		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-	}
-	fmt.Println("this is the user:", TheUser)
-	refreshToken, err := jwt.ParseWithClaims(TheUser.RefreshToken, &domain.Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
-	fmt.Println("this is the refresh token", refreshToken)
-	if err == nil {
-		refreshClaims, ok := refreshToken.Claims.(*domain.Claims)
-		fmt.Println("this is the refresh claims", refreshClaims)
-
-		if refreshClaims.ExpiresAt < time.Now().Unix() {
-
-			_, err := c.userUsecase.UpdateUser(TheUser.ID, domain.User{RefreshToken: ""})
-			if err != nil {
-				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err})
-			}
-			ctx.IndentedJSON(http.StatusUnauthorized, gin.H{"error": "refresh token is expired"})
+	if c.loginGuard != nil {
+		if blocked := c.loginGuard.CheckIP(ctx.ClientIP()); blocked != nil {
+			refuseBlocked(ctx, blocked)
 			return
 		}
-		if ok && refreshToken.Valid {
-			if err != nil {
-				ctx.IndentedJSON(http.StatusUnauthorized, gin.H{"error": err})
-				return
-			}
-			// User login logic
-			fmt.Println("this is the user", TheUser)
-			fmt.Println("this is the NUID", NUID)
-			if bcrypt.CompareHashAndPassword([]byte(TheUser.Password), []byte(NUID.Password)) != nil {
-				ctx.IndentedJSON(http.StatusUnauthorized, gin.H{"error": "password is incorrect"})
-				return
-			}
-
-			newToken, refresh, err := infrastructure.GenerateToken(&TheUser, NUID.Password)
-			if err != nil {
-				ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err})
-				return
-			}
-			ctx.IndentedJSON(200, gin.H{"refreshed access token": newToken})
-			TheUser.RefreshToken = refresh
-			_, err = c.userUsecase.UpdateUser(TheUser.ID, domain.User{RefreshToken: refresh})
-			if err != nil {
-				ctx.IndentedJSON(http.StatusUnauthorized, gin.H{"error": err})
-				return
-			}
-
-		} else {
-			ctx.IndentedJSON(http.StatusUnauthorized, gin.H{"error": "Token is expired"})
+	}
+	tokens, err := c.sessions.Refresh(request.RefreshToken, ctx.Request.UserAgent(), ctx.ClientIP())
+	if err != nil {
+		if c.loginGuard != nil {
+			c.loginGuard.FailedIP(ctx.ClientIP())
 		}
+		ctx.IndentedJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
 	}
-	ctx.IndentedJSON(http.StatusForbidden, gin.H{"error": "couldn't refrsh the token"})
+	ctx.IndentedJSON(http.StatusOK, tokens)
+}
+
+// UnlockUser godoc
+// @Summary      Unlock a user
+// @Description  Lifts the lockout of a user after too many failed logins and forgets their failed logins.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "User ID"
+// @Success      200 {object} map[string]string "message"
+// @Failure      403 {object} map[string]string "Missing permission users:manage"
+// @Failure      404 {object} map[string]string "error"
+// @Router       /users/{id}/lockout [delete]
+func (c *UserController) UnlockUser(ctx *gin.Context) {
+	if c.loginGuard == nil {
+		ctx.JSON(http.StatusNotImplemented, gin.H{"error": "login protection is not enabled"})
+		return
+	}
+	if err := c.loginGuard.Unlock(ctx.Param("id")); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"message": "User unlocked"})
+}
+
+// RefreshRequest is the body of a token refresh.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 // Promote godoc
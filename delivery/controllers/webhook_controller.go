@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/yesetoda/BlogMate/domain"
+	usecase "github.com/yesetoda/BlogMate/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookController struct {
+	usecase *usecase.WebhookUsecase
+}
+
+func NewWebhookController(uc *usecase.WebhookUsecase) *WebhookController {
+	return &WebhookController{usecase: uc}
+}
+
+type webhookUpdate struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+	Active *bool    `json:"active"`
+}
+
+// HandleCreateWebhook godoc
+// @Summary Create a webhook
+// @Description Subscribe a URL to events (blog.created, blog.updated, blog.deleted, comment.created, user.registered). Deliveries are signed with HMAC-SHA256 over "<X-BlogMate-Timestamp>.<body>" and sent in X-BlogMate-Signature. The secret is generated when omitted and only returned by this call.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param webhook body domain.Webhook true "Webhook data"
+// @Success 201 {object} domain.Webhook "Created webhook including its secret"
+// @Failure 400 {object} map[string]string "Invalid url or events"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 403 {string} string "Forbidden - admin only"
+// @Router /webhooks [post]
+func (cont *WebhookController) HandleCreateWebhook(ctx *gin.Context) {
+	var webhook domain.Webhook
+	if err := ctx.ShouldBindJSON(&webhook); err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	webhook, err := cont.usecase.CreateWebhook(webhook)
+	if err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusCreated, webhook)
+}
+
+// HandleGetWebhooks godoc
+// @Summary List webhooks
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.Webhook "Webhooks without their secrets"
+// @Failure 401 {string} string "Unauthorized - invalid or missing token"
+// @Failure 403 {string} string "Forbidden - admin only"
+// @Router /webhooks [get]
+func (cont *WebhookController) HandleGetWebhooks(ctx *gin.Context) {
+	webhooks, err := cont.usecase.GetWebhooks()
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, webhooks)
+}
+
+// HandleGetWebhook godoc
+// @Summary Get a webhook
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param webhookId path string true "Webhook ID"
+// @Success 200 {object} domain.Webhook "Webhook without its secret"
+// @Failure 404 {object} map[string]string "Webhook not found"
+// @Router /webhooks/{webhookId} [get]
+func (cont *WebhookController) HandleGetWebhook(ctx *gin.Context) {
+	webhook, err := cont.usecase.GetWebhookById(ctx.Param("webhookId"))
+	if err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, webhook)
+}
+
+// HandleUpdateWebhook godoc
+// @Summary Update a webhook
+// @Description Change the url, events or secret of a webhook, or pause it with active=false.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param webhookId path string true "Webhook ID"
+// @Param webhook body domain.Webhook true "Fields to change"
+// @Success 200 {object} domain.Webhook "Updated webhook"
+// @Failure 400 {object} map[string]string "Invalid url or events"
+// @Router /webhooks/{webhookId} [patch]
+func (cont *WebhookController) HandleUpdateWebhook(ctx *gin.Context) {
+	var update webhookUpdate
+	if err := ctx.ShouldBindJSON(&update); err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	webhook, err := cont.usecase.UpdateWebhook(ctx.Param("webhookId"), domain.Webhook{
+		URL:    update.URL,
+		Events: update.Events,
+		Secret: update.Secret,
+		Active: update.Active,
+	})
+	if err != nil {
+		ctx.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, webhook)
+}
+
+// HandleDeleteWebhook godoc
+// @Summary Delete a webhook
+// @Description Deletes the webhook and its delivery log.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param webhookId path string true "Webhook ID"
+// @Success 200 {object} map[string]string "Webhook deleted"
+// @Failure 404 {object} map[string]string "Webhook not found"
+// @Router /webhooks/{webhookId} [delete]
+func (cont *WebhookController) HandleDeleteWebhook(ctx *gin.Context) {
+	if err := cont.usecase.DeleteWebhook(ctx.Param("webhookId")); err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+// HandleGetWebhookDeliveries godoc
+// @Summary List the deliveries of a webhook
+// @Description Newest first, with every attempt's status code, error and duration. Defaults: pageNumber=1, pageSize=20.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param webhookId path string true "Webhook ID"
+// @Param pageNumber query int false "Page number"
+// @Param pageSize query int false "Number of items per page"
+// @Success 200 {array} domain.WebhookDelivery "Delivery log"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /webhooks/{webhookId}/deliveries [get]
+func (cont *WebhookController) HandleGetWebhookDeliveries(ctx *gin.Context) {
+	ipage, err := strconv.Atoi(ctx.Query("pageNumber"))
+	if err != nil || ipage < 1 {
+		ipage = 1
+	}
+	ipageSize, err := strconv.Atoi(ctx.Query("pageSize"))
+	if err != nil || ipageSize < 1 {
+		ipageSize = 20
+	}
+	deliveries, err := cont.usecase.GetDeliveries(ctx.Param("webhookId"), domain.PaginationInfo{Page: ipage, PageSize: ipageSize})
+	if err != nil {
+		ctx.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, deliveries)
+}
+
+// HandleRedeliverWebhook godoc
+// @Summary Redeliver a webhook delivery
+// @Description Queues a new delivery with the same payload. The payload id stays the same so receivers can detect the duplicate.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param webhookId path string true "Webhook ID"
+// @Param deliveryId path string true "Delivery ID"
+// @Success 202 {object} domain.WebhookDelivery "Queued delivery"
+// @Failure 404 {object} map[string]string "Delivery not found"
+// @Router /webhooks/{webhookId}/deliveries/{deliveryId}/redeliver [post]
+func (cont *WebhookController) HandleRedeliverWebhook(ctx *gin.Context) {
+	delivery, err := cont.usecase.Redeliver(ctx.Param("webhookId"), ctx.Param("deliveryId"))
+	if err != nil {
+		ctx.IndentedJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.IndentedJSON(http.StatusAccepted, delivery)
+}
@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"time"
 
 	"github.com/yesetoda/BlogMate/config"
 	"github.com/yesetoda/BlogMate/delivery/controllers"
+	"github.com/yesetoda/BlogMate/domain"
 	_ "github.com/yesetoda/BlogMate/delivery/docs"
+	"github.com/yesetoda/BlogMate/gemini"
 	router "github.com/yesetoda/BlogMate/delivery/routers"
 	"github.com/yesetoda/BlogMate/infrastructure"
 	"github.com/yesetoda/BlogMate/repository"
@@ -45,22 +50,207 @@ func main() {
 	blogCollections := client.Database("Blog-Mate").Collection("Blogs")
 	userCollections := client.Database("Blog-Mate").Collection("Users")
 	commentCollections := client.Database("Blog-Mate").Collection("Comments")
+	mentionCollections := client.Database("Blog-Mate").Collection("Mentions")
+	notificationCollections := client.Database("Blog-Mate").Collection("Notifications")
+	notificationPreferenceCollections := client.Database("Blog-Mate").Collection("NotificationPreferences")
+	webhookCollections := client.Database("Blog-Mate").Collection("Webhooks")
+	webhookDeliveryCollections := client.Database("Blog-Mate").Collection("WebhookDeliveries")
+	outboxCollections := client.Database("Blog-Mate").Collection("Outbox")
+	digestSubscriptionCollections := client.Database("Blog-Mate").Collection("DigestSubscriptions")
+	digestLogCollections := client.Database("Blog-Mate").Collection("DigestLog")
+	newsletterCollections := client.Database("Blog-Mate").Collection("NewsletterSubscribers")
+	sessionCollections := client.Database("Blog-Mate").Collection("Sessions")
+	revokedTokenCollections := client.Database("Blog-Mate").Collection("RevokedTokens")
+	signingKeyCollections := client.Database("Blog-Mate").Collection("SigningKeys")
+	twoFactorCollections := client.Database("Blog-Mate").Collection("TwoFactor")
+	loginChallengeCollections := client.Database("Blog-Mate").Collection("LoginChallenges")
+	identityCollections := client.Database("Blog-Mate").Collection("Identities")
+	oidcStateCollections := client.Database("Blog-Mate").Collection("OIDCStates")
+	magicLinkCollections := client.Database("Blog-Mate").Collection("MagicLinks")
+	apiKeyCollections := client.Database("Blog-Mate").Collection("APIKeys")
+	roleCollections := client.Database("Blog-Mate").Collection("Roles")
+	loginAttemptCollections := client.Database("Blog-Mate").Collection("LoginAttempts")
+	emailChangeCollections := client.Database("Blog-Mate").Collection("EmailChanges")
+	profileCollections := client.Database("Blog-Mate").Collection("Profiles")
+	mediaCollections := client.Database("Blog-Mate").Collection("Media")
 	replyCollections := client.Database("Blog-Mate").Collection("Replies")
 	// _ = client.Database("BlogAPI").Collection("Tokens")
-	blogRepo := repository.NewBlogRepository(mongoifc.WrapClient(client), mongoifc.WrapCollection(blogCollections), mongoifc.WrapCollection(commentCollections), mongoifc.WrapCollection(replyCollections))
+	migrated, err := repository.MigrateRepliesToComments(mongoifc.WrapCollection(blogCollections), mongoifc.WrapCollection(commentCollections), mongoifc.WrapCollection(replyCollections))
+	if err != nil {
+		panic(err)
+	}
+	if migrated > 0 {
+		log.Println("migrated", migrated, "replies into threaded comments")
+	}
+	migratedAdmins, err := repository.MigrateAdminRoles(mongoifc.WrapCollection(userCollections))
+	if err != nil {
+		panic(err)
+	}
+	if migratedAdmins > 0 {
+		log.Println("gave", migratedAdmins, "admins the admin role")
+	}
+	blogRepo := repository.NewBlogRepository(mongoifc.WrapClient(client), mongoifc.WrapCollection(blogCollections), mongoifc.WrapCollection(commentCollections))
 	blogUsecase := usecase.NewBlogUsecase(blogRepo)
-	blogController := controllers.NewBlogController(*blogUsecase)
-	authController := infrastructure.NewAuthController(blogRepo)
+	var embedder infrastructure.Embedder = infrastructure.NewHashEmbedder(256)
+	if config_mongo.Gemini.ApiKey != "" && config_mongo.Gemini.EmbeddingModel != "" {
+		embedder, err = gemini.NewGeminiEmbedder(config_mongo.Gemini.ApiKey, config_mongo.Gemini.EmbeddingModel)
+		if err != nil {
+			panic(err)
+		}
+	}
+	blogUsecase.SetEmbeddings(embedder, infrastructure.NewMemoryVectorStore())
+	go func() {
+		if err := blogUsecase.IndexAllBlogs(); err != nil {
+			log.Println("indexing blogs for semantic search failed:", err)
+		}
+	}()
+	var storage infrastructure.Storage
+	switch config_mongo.Media.Storage {
+	case "s3":
+		s3 := config_mongo.Media.S3
+		storage, err = infrastructure.NewS3Storage(infrastructure.S3Config{Endpoint: s3.Endpoint, Region: s3.Region, Bucket: s3.Bucket, AccessKey: s3.AccessKey, SecretKey: s3.SecretKey, PathStyle: s3.PathStyle})
+	case "", "local":
+		mediaDir := config_mongo.Media.Dir
+		if mediaDir == "" {
+			mediaDir = "media"
+		}
+		storage, err = infrastructure.NewLocalStorage(mediaDir)
+	default:
+		err = fmt.Errorf("unknown media storage %q", config_mongo.Media.Storage)
+	}
+	if err != nil {
+		panic(err)
+	}
+	mediaBaseURL := config_mongo.Media.BaseURL
+	if mediaBaseURL == "" {
+		mediaBaseURL = config_mongo.Port
+	}
+	mediaUsecase := usecase.NewMediaUsecase(repository.NewMediaRepository(mongoifc.WrapCollection(mediaCollections)), storage, usecase.MediaSettings{MaxUploadSize: config_mongo.Media.MaxUploadSize, Quota: config_mongo.Media.Quota, BaseURL: mediaBaseURL})
+	blogUsecase.SetMedia(mediaUsecase)
+	go mediaUsecase.Run(time.Hour, nil)
+	var keyring *infrastructure.Keyring
+	if config_mongo.JWTKeys.Algorithm != infrastructure.HS256 {
+		keyring, err = infrastructure.NewKeyring(repository.NewSigningKeyRepository(mongoifc.WrapCollection(signingKeyCollections)), config_mongo.JWTKeys.Algorithm, config_mongo.JWTKeys.Rotation)
+		if err != nil {
+			panic(err)
+		}
+		if err := keyring.Load(); err != nil {
+			panic(err)
+		}
+		infrastructure.SetDefaultKeyring(keyring)
+		go keyring.Run(5*time.Minute, nil)
+	}
+	keyController := controllers.NewKeyController(keyring)
 	userRepo := repository.NewUserRepository(mongoifc.WrapCollection(userCollections))
-	userUsecase, err := usecase.NewUserUsecase(userRepo)
+	blogUsecase.SetSite(config_mongo.Port, userRepo)
+	revocationList := infrastructure.NewRevocationList(repository.NewRevocationRepository(mongoifc.WrapCollection(revokedTokenCollections)), userRepo)
+	go revocationList.Run(time.Minute, nil)
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(repository.NewAPIKeyRepository(mongoifc.WrapCollection(apiKeyCollections)), userRepo)
+	roleUsecase := usecase.NewRoleUsecase(repository.NewRoleRepository(mongoifc.WrapCollection(roleCollections)), userRepo)
+	roleUsecase.SetTokenRevoker(revocationList)
+	authOptions := []infrastructure.AuthOption{infrastructure.WithRevocationList(revocationList), infrastructure.WithAPIKeys(apiKeyUsecase), infrastructure.WithPermissions(roleUsecase)}
+	if config_mongo.TwoFactor.RequiredForAdmins {
+		authOptions = append(authOptions, infrastructure.WithAdminTwoFactor())
+	}
+	authController := infrastructure.NewAuthController(blogRepo, authOptions...)
+	webhookUsecase := usecase.NewWebhookUsecase(repository.NewWebhookRepository(mongoifc.WrapCollection(webhookCollections), mongoifc.WrapCollection(webhookDeliveryCollections)))
+	go webhookUsecase.Run(15*time.Second, nil)
+	webhookController := controllers.NewWebhookController(webhookUsecase)
+	mailer, err := infrastructure.NewMailer(config_mongo)
+	if err != nil {
+		panic(err)
+	}
+	infrastructure.SetDefaultMailer(mailer)
+	outboxUsecase := usecase.NewOutboxUsecase(repository.NewOutboxRepository(mongoifc.WrapCollection(outboxCollections)), repository.NewTransactor(mongoifc.WrapClient(client)))
+	outboxUsecase.Handle(domain.OutboxEmail, usecase.EmailOutboxHandler(mailer))
+	outboxUsecase.Handle(domain.OutboxWebhook, webhookUsecase.HandleOutboxMessage)
+	blogUsecase.SetOutbox(outboxUsecase)
+	outboxController := controllers.NewOutboxController(outboxUsecase)
+	infrastructure.SetPasswordService(infrastructure.PasswordService{Algorithm: config_mongo.Password.Hash, BcryptCost: config_mongo.Password.BcryptCost})
+	breachedPasswords, err := infrastructure.NewBreachedPasswords(config_mongo.Password.BreachedFile)
+	if err != nil {
+		panic(err)
+	}
+	passwordPolicy := usecase.DefaultPasswordPolicy
+	if config_mongo.Password.MinLength > 0 {
+		passwordPolicy.MinLength = config_mongo.Password.MinLength
+	}
+	if config_mongo.Password.CharacterClasses > 0 {
+		passwordPolicy.MinCharacterClasses = config_mongo.Password.CharacterClasses
+	}
+	if config_mongo.Password.History > 0 {
+		passwordPolicy.History = config_mongo.Password.History
+	}
+	userUsecase, err := usecase.NewUserUsecase(userRepo, usecase.WithUserOutbox(outboxUsecase), usecase.WithTokenRevoker(revocationList), usecase.WithPasswordPolicy(passwordPolicy), usecase.WithBreachedPasswords(breachedPasswords))
 	if err != nil {
 		panic(err)
 	}
 	UserController := controllers.NewUserController(userUsecase)
+	sessionUsecase := usecase.NewSessionUsecase(repository.NewSessionRepository(mongoifc.WrapCollection(sessionCollections)), userRepo)
+	UserController.SetSessions(sessionUsecase)
+	UserController.SetTokenRevoker(revocationList)
+	loginGuardUsecase := usecase.NewLoginGuardUsecase(repository.NewLoginAttemptRepository(mongoifc.WrapCollection(loginAttemptCollections)), userRepo)
+	loginGuardUsecase.SetOutbox(outboxUsecase)
+	loginGuardUsecase.SetCaptchaAfter(config_mongo.LoginGuard.CaptchaAfter)
+	UserController.SetLoginGuard(loginGuardUsecase)
+	twoFactorUsecase := usecase.NewTwoFactorUsecase(repository.NewTwoFactorRepository(mongoifc.WrapCollection(twoFactorCollections), mongoifc.WrapCollection(loginChallengeCollections)), userRepo, config_mongo.TwoFactor.Issuer, config_mongo.TwoFactor.RequiredForAdmins)
+	twoFactorUsecase.SetLoginGuard(loginGuardUsecase)
+	UserController.SetTwoFactor(twoFactorUsecase)
+	twoFactorController := controllers.NewTwoFactorController(twoFactorUsecase)
+	sessionController := controllers.NewSessionController(sessionUsecase)
+	oidcProviders := []domain.OIDCProvider{}
+	for _, provider := range config_mongo.OIDC {
+		oidcProviders = append(oidcProviders, infrastructure.NewOIDCProvider(infrastructure.OIDCConfig{
+			Name:         provider.Name,
+			Issuer:       provider.Issuer,
+			ClientId:     provider.ClientId,
+			ClientSecret: provider.ClientSecret,
+			Scopes:       provider.Scopes,
+			RedirectURL:  config_mongo.Port + "/auth/oidc/" + provider.Name + "/callback",
+		}, nil))
+	}
+	oidcUsecase := usecase.NewOIDCUsecase(repository.NewIdentityRepository(mongoifc.WrapCollection(identityCollections), mongoifc.WrapCollection(oidcStateCollections)), userRepo, oidcProviders...)
+	oidcController := controllers.NewOIDCController(oidcUsecase, UserController)
+	magicLinkUsecase := usecase.NewMagicLinkUsecase(repository.NewMagicLinkRepository(mongoifc.WrapCollection(magicLinkCollections)), userRepo)
+	magicLinkUsecase.SetOutbox(outboxUsecase)
+	magicLinkController := controllers.NewMagicLinkController(magicLinkUsecase, UserController)
+	apiKeyController := controllers.NewAPIKeyController(apiKeyUsecase)
+	roleController := controllers.NewRoleController(roleUsecase)
+	accountChangeUsecase := usecase.NewAccountChangeUsecase(repository.NewEmailChangeRepository(mongoifc.WrapCollection(emailChangeCollections)), userRepo)
+	accountChangeUsecase.SetOutbox(outboxUsecase)
+	accountChangeUsecase.SetTokenRevoker(revocationList)
+	accountChangeUsecase.SetSessions(sessionUsecase)
+	accountController := controllers.NewAccountController(accountChangeUsecase)
+	profileUsecase := usecase.NewProfileUsecase(repository.NewProfileRepository(mongoifc.WrapCollection(profileCollections)), userRepo, blogRepo)
+	profileUsecase.SetMedia(mediaUsecase)
+	profileController := controllers.NewProfileController(profileUsecase)
+	mediaController := controllers.NewMediaController(mediaUsecase)
+	mentionUsecase := usecase.NewMentionUsecase(userRepo, repository.NewMentionRepository(mongoifc.WrapCollection(mentionCollections)))
+	blogUsecase.SetMentions(mentionUsecase)
+	mentionController := controllers.NewMentionController(mentionUsecase)
+	notificationUsecase := usecase.NewNotificationUsecase(repository.NewNotificationRepository(mongoifc.WrapCollection(notificationCollections), mongoifc.WrapCollection(notificationPreferenceCollections)))
+	notificationUsecase.SetOutbox(outboxUsecase)
+	outboxUsecase.Handle(domain.OutboxNotification, notificationUsecase.HandleOutboxMessage)
+	blogUsecase.SetNotifications(notificationUsecase)
+	mentionUsecase.SetNotifications(notificationUsecase)
+	notificationController := controllers.NewNotificationController(notificationUsecase)
+	newsletterUsecase := usecase.NewNewsletterUsecase(repository.NewNewsletterRepository(mongoifc.WrapCollection(newsletterCollections)), userRepo)
+	newsletterUsecase.SetOutbox(outboxUsecase)
+	blogUsecase.SetNewsletter(newsletterUsecase)
+	newsletterController := controllers.NewNewsletterController(newsletterUsecase)
+	broker := infrastructure.NewMemoryBroker(64)
+	blogUsecase.SetBroker(broker)
+	notificationUsecase.SetBroker(broker)
+	streamController := controllers.NewStreamController(broker)
+	go outboxUsecase.Run(10*time.Second, nil)
+	digestUsecase := usecase.NewDigestUsecase(repository.NewDigestRepository(mongoifc.WrapCollection(digestSubscriptionCollections), mongoifc.WrapCollection(digestLogCollections)), blogRepo, userRepo, mailer)
+	go digestUsecase.Run(time.Hour, nil)
+	digestController := controllers.NewDigestController(digestUsecase)
+	blogController := controllers.NewBlogController(*blogUsecase)
 	prompts, err := infrastructure.LoadPrompt("prompts.json")
 	if err != nil {
 		panic(err)
 	}
-	Router := router.NewMainRouter(*UserController, *blogController, authController,*config_mongo,prompts)
+	Router := router.NewMainRouter(*UserController, *blogController, authController,*config_mongo,prompts, *mentionController, *notificationController, *streamController, *webhookController, *outboxController, *digestController, *newsletterController, *sessionController, *keyController, *twoFactorController, *oidcController, *magicLinkController, *apiKeyController, *roleController, *accountController, *profileController, *mediaController)
 	Router.GinBlogRouter()
 }
@@ -2,6 +2,7 @@ package routers
 
 import (
 	"github.com/yesetoda/BlogMate/delivery/controllers"
+	"github.com/yesetoda/BlogMate/domain"
 	"github.com/yesetoda/BlogMate/infrastructure"
 	"github.com/yesetoda/BlogMate/config"
 
@@ -18,10 +19,44 @@ type MainRouter struct {
 	handler        controllers.UserController
 	config         config.Config
 	prompts 	  infrastructure.Prompts
+	mentionController controllers.MentionController
+	notificationController controllers.NotificationController
+	streamController controllers.StreamController
+	webhookController controllers.WebhookController
+	outboxController controllers.OutboxController
+	digestController controllers.DigestController
+	newsletterController controllers.NewsletterController
+	sessionController controllers.SessionController
+	keyController controllers.KeyController
+	twoFactorController controllers.TwoFactorController
+	oidcController controllers.OIDCController
+	magicLinkController controllers.MagicLinkController
+	apiKeyController controllers.APIKeyController
+	roleController controllers.RoleController
+	accountController controllers.AccountController
+	profileController controllers.ProfileController
+	mediaController controllers.MediaController
 }
 
-func NewMainRouter(uc controllers.UserController, bc controllers.BlogController, authc infrastructure.GeneralAuthorizationController ,conf config.Config,prompts infrastructure.Prompts) *MainRouter {
+func NewMainRouter(uc controllers.UserController, bc controllers.BlogController, authc infrastructure.GeneralAuthorizationController ,conf config.Config,prompts infrastructure.Prompts, mc controllers.MentionController, nc controllers.NotificationController, sc controllers.StreamController, wc controllers.WebhookController, oc controllers.OutboxController, dc controllers.DigestController, nlc controllers.NewsletterController, sessc controllers.SessionController, keyc controllers.KeyController, tfc controllers.TwoFactorController, oidcc controllers.OIDCController, mlc controllers.MagicLinkController, akc controllers.APIKeyController, rc controllers.RoleController, acc controllers.AccountController, pc controllers.ProfileController, medc controllers.MediaController) *MainRouter {
 	return &MainRouter{
+		mediaController: medc,
+		profileController: pc,
+		accountController: acc,
+		roleController: rc,
+		apiKeyController: akc,
+		magicLinkController: mlc,
+		oidcController: oidcc,
+		twoFactorController: tfc,
+		keyController: keyc,
+		sessionController: sessc,
+		newsletterController: nlc,
+		digestController: dc,
+		outboxController: oc,
+		webhookController: wc,
+		streamController: sc,
+		mentionController: mc,
+		notificationController: nc,
 		blogController: bc,
 		authController: authc,
 		handler:        uc,
@@ -35,57 +70,156 @@ func (gr *MainRouter) GinBlogRouter() {
 	router := gin.Default()
 	router.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	AddAIRoutes(router,gr.config, gr.prompts)
+	router.GET("/.well-known/jwks.json", gr.keyController.HandleJWKS)
+	router.GET("/auth/oidc/providers", gr.oidcController.HandleGetProviders)
+	router.GET("/auth/oidc/:provider/login", gr.oidcController.HandleLogin)
+	router.GET("/auth/oidc/:provider/callback", gr.oidcController.HandleCallback)
 
 	userrouter := router.Group("/users")
 	{
 		userrouter.POST("/register", gr.handler.Register)
 		userrouter.GET("/accountVerification", gr.handler.AccountVerification)
 		userrouter.POST("/login", gr.handler.LoginUser)
+		userrouter.POST("/login/2fa", gr.handler.LoginTwoFactor)
+		userrouter.POST("/magic-link", gr.magicLinkController.HandleRequestMagicLink)
+		userrouter.POST("/magic-link/redeem", gr.magicLinkController.HandleRedeemMagicLink)
+		userrouter.GET("/email/confirm", gr.accountController.HandleConfirmEmailChange)
+		userrouter.GET("/email/revert", gr.accountController.HandleRevertEmailChange)
 		userrouter.GET("/forgetPassword", gr.handler.ForgetPassword)
 		userrouter.POST("/resetPassword", gr.handler.ResetPassword)
-		userrouter.GET("/logout", gr.handler.LogoutUser)
+		userrouter.GET("/logout", gr.authController.AuthenticationMiddleware(), gr.handler.LogoutUser)
+		userrouter.POST("/refresh", gr.handler.RefreshAccessToken)
 		userrouter.POST("/:uid/refresh", gr.handler.RefreshAccessToken)
 		userrouter.GET("/", gr.handler.GetUsers)
 		userrouter.GET("/:id", gr.handler.GetUserByID)
+		userrouter.GET("/:id/profile", gr.profileController.HandleGetPublicProfile)
 		userrouter.Use(gr.authController.AuthenticationMiddleware())
 		{
 			userrouter.PUT("/changePassword", gr.handler.ChangePassword)
-			userrouter.PUT("/changeEmail", gr.handler.UpdateProfiles)
-			userrouter.PATCH("promote/:username", gr.authController.ADMINMiddleware(), gr.handler.Promote)
-			userrouter.PATCH("demote/:username", gr.authController.ADMINMiddleware(), gr.handler.Demote)
-			userrouter.PATCH("promotebyemail/:email", gr.authController.ADMINMiddleware(), gr.handler.PromoteByEmail)
-			userrouter.PATCH("demotebyemail/:email", gr.authController.ADMINMiddleware(), gr.handler.DemoteByEmail)
-			userrouter.DELETE("/:id", gr.authController.ADMINMiddleware(), gr.handler.DeleteUser)
+			userrouter.PATCH("promote/:username", gr.authController.RequirePermission(domain.PermManageRoles), gr.handler.Promote)
+			userrouter.PATCH("demote/:username", gr.authController.RequirePermission(domain.PermManageRoles), gr.handler.Demote)
+			userrouter.PATCH("promotebyemail/:email", gr.authController.RequirePermission(domain.PermManageRoles), gr.handler.PromoteByEmail)
+			userrouter.PATCH("demotebyemail/:email", gr.authController.RequirePermission(domain.PermManageRoles), gr.handler.DemoteByEmail)
+			userrouter.PUT("/:id/roles", gr.authController.RequirePermission(domain.PermManageRoles), gr.roleController.HandleAssignRoles)
+			userrouter.DELETE("/:id", gr.authController.RequirePermission(domain.PermManageUsers), gr.handler.DeleteUser)
+			userrouter.DELETE("/:id/2fa", gr.authController.RequirePermission(domain.PermManageUsers), gr.twoFactorController.HandleResetTwoFactor)
+			userrouter.DELETE("/:id/lockout", gr.authController.RequirePermission(domain.PermManageUsers), gr.handler.UnlockUser)
 		}
 	}
+	meRouter := router.Group("/me")
+	meRouter.Use(gr.authController.AuthenticationMiddleware())
+	{
+		meRouter.GET("/mentions", gr.mentionController.HandleGetMyMentions)
+		meRouter.GET("/notifications", gr.notificationController.HandleGetMyNotifications)
+		meRouter.PATCH("/notifications/read", gr.notificationController.HandleMarkAllNotificationsRead)
+		meRouter.PATCH("/notifications/:notificationId/read", gr.notificationController.HandleMarkNotificationRead)
+		meRouter.GET("/notifications/preferences", gr.notificationController.HandleGetNotificationPreferences)
+		meRouter.PUT("/notifications/preferences", gr.notificationController.HandleUpdateNotificationPreferences)
+		meRouter.GET("/sessions", gr.sessionController.HandleGetMySessions)
+		meRouter.DELETE("/sessions", gr.sessionController.HandleRevokeMySessions)
+		meRouter.DELETE("/sessions/:sessionId", gr.sessionController.HandleRevokeMySession)
+		meRouter.GET("/2fa", gr.twoFactorController.HandleGetMyTwoFactor)
+		meRouter.POST("/2fa/enroll", gr.twoFactorController.HandleEnrollTwoFactor)
+		meRouter.POST("/2fa/activate", gr.twoFactorController.HandleActivateTwoFactor)
+		meRouter.POST("/2fa/disable", gr.twoFactorController.HandleDisableTwoFactor)
+		meRouter.POST("/2fa/recovery-codes", gr.twoFactorController.HandleRegenerateRecoveryCodes)
+		meRouter.POST("/oidc/:provider/link", gr.oidcController.HandleLinkProvider)
+		meRouter.GET("/identities", gr.oidcController.HandleGetMyIdentities)
+		meRouter.DELETE("/identities/:provider", gr.oidcController.HandleUnlinkProvider)
+		meRouter.POST("/email", gr.accountController.HandleRequestEmailChange)
+		meRouter.PUT("/username", gr.accountController.HandleChangeUsername)
+		meRouter.GET("/profile", gr.profileController.HandleGetMyProfile)
+		meRouter.PUT("/profile", gr.profileController.HandleUpdateMyProfile)
+		meRouter.GET("/media", gr.mediaController.HandleGetMyMedia)
+		meRouter.GET("/api-keys", gr.apiKeyController.HandleGetMyAPIKeys)
+		meRouter.POST("/api-keys", gr.apiKeyController.HandleCreateAPIKey)
+		meRouter.DELETE("/api-keys/:keyId", gr.apiKeyController.HandleDeleteAPIKey)
+		meRouter.GET("/digest", gr.digestController.HandleGetMyDigest)
+		meRouter.PUT("/digest", gr.digestController.HandleUpdateMyDigest)
+		meRouter.GET("/subscribers", gr.newsletterController.HandleGetMySubscribers)
+		meRouter.GET("/subscribers/export", gr.newsletterController.HandleExportMySubscribers)
+		meRouter.DELETE("/subscribers/:subscriberId", gr.newsletterController.HandleRemoveMySubscriber)
+	}
+	mediaRouter := router.Group("/media")
+	{
+		mediaRouter.GET("/:mediaId", gr.mediaController.HandleGetMedia)
+		mediaRouter.Use(gr.authController.AuthenticationMiddleware())
+		{
+			mediaRouter.POST("/", gr.mediaController.HandleUploadMedia)
+			mediaRouter.DELETE("/:mediaId", gr.mediaController.HandleDeleteMedia)
+		}
+	}
+	webhookRouter := router.Group("/webhooks")
+	webhookRouter.Use(gr.authController.AuthenticationMiddleware(), gr.authController.RequirePermission(domain.PermManageWebhooks))
+	{
+		webhookRouter.POST("/", gr.webhookController.HandleCreateWebhook)
+		webhookRouter.GET("/", gr.webhookController.HandleGetWebhooks)
+		webhookRouter.GET("/:webhookId", gr.webhookController.HandleGetWebhook)
+		webhookRouter.PATCH("/:webhookId", gr.webhookController.HandleUpdateWebhook)
+		webhookRouter.DELETE("/:webhookId", gr.webhookController.HandleDeleteWebhook)
+		webhookRouter.GET("/:webhookId/deliveries", gr.webhookController.HandleGetWebhookDeliveries)
+		webhookRouter.POST("/:webhookId/deliveries/:deliveryId/redeliver", gr.webhookController.HandleRedeliverWebhook)
+	}
+	outboxRouter := router.Group("/outbox")
+	outboxRouter.Use(gr.authController.AuthenticationMiddleware(), gr.authController.RequirePermission(domain.PermManageOutbox))
+	{
+		outboxRouter.GET("/", gr.outboxController.HandleGetOutboxMessages)
+		outboxRouter.POST("/:messageId/retry", gr.outboxController.HandleRetryOutboxMessage)
+	}
+	roleRouter := router.Group("/roles")
+	roleRouter.Use(gr.authController.AuthenticationMiddleware(), gr.authController.RequirePermission(domain.PermManageRoles))
+	{
+		roleRouter.GET("/", gr.roleController.HandleGetRoles)
+		roleRouter.GET("/permissions", gr.roleController.HandleGetPermissions)
+		roleRouter.PUT("/:name", gr.roleController.HandleSaveRole)
+		roleRouter.DELETE("/:name", gr.roleController.HandleDeleteRole)
+	}
+	router.GET("/digest/unsubscribe", gr.digestController.HandleUnsubscribeDigest)
+	router.POST("/digest/unsubscribe", gr.digestController.HandleUnsubscribeDigest)
+	newsletterRouter := router.Group("/newsletter")
+	{
+		newsletterRouter.POST("/subscribe", gr.newsletterController.HandleSubscribeNewsletter)
+		newsletterRouter.GET("/confirm", gr.newsletterController.HandleConfirmNewsletter)
+		newsletterRouter.GET("/unsubscribe", gr.newsletterController.HandleUnsubscribeNewsletter)
+		newsletterRouter.POST("/unsubscribe", gr.newsletterController.HandleUnsubscribeNewsletter)
+	}
+	router.GET("/stream", infrastructure.QueryTokenMiddleware(), gr.authController.AuthenticationMiddleware(), gr.streamController.HandleStream)
+	router.GET("/ws", infrastructure.QueryTokenMiddleware(), gr.authController.AuthenticationMiddleware(), gr.streamController.HandleWebSocket)
 	router.GET("blogs/", gr.blogController.HandleGetAllBlogs)
 	router.GET("blogs/popular", gr.blogController.HandleGetPopularBlog)
 	router.GET("blogs/filter", gr.blogController.HandleFilterBlogs)
+	router.GET("blogs/search", gr.blogController.HandleSemanticSearch)
 	router.GET("blogs/:blogId", gr.blogController.HandleGetBlogById)
+	router.GET("blogs/:blogId/related", gr.blogController.HandleGetRelatedBlogs)
+	router.GET("blogs/:blogId/similar", gr.blogController.HandleMoreLikeThis)
+	router.GET("blogs/:blogId/meta", gr.blogController.HandleGetBlogMeta)
 
 	blogRouter := router.Group("/blogs")
 	blogRouter.Use(gr.authController.AuthenticationMiddleware())
 	{
-		blogRouter.POST("/", gr.authController.USERMiddleware(), gr.blogController.HandleCreateBlog)
-		blogRouter.PATCH("/:blogId", gr.authController.OWNERMiddleware(), gr.blogController.HandleBlogUpdate)
-		blogRouter.DELETE("/:blogId", gr.authController.OWNERMiddleware(), gr.blogController.HandleBlogDelete)
+		blogRouter.POST("/", gr.authController.USERMiddleware(), gr.authController.RequirePermission(domain.PermCreateBlog), gr.blogController.HandleCreateBlog)
+		blogRouter.PATCH("/:blogId", gr.authController.RequireOwnerOrPermission(domain.PermEditAnyBlog), gr.blogController.HandleBlogUpdate)
+		blogRouter.DELETE("/:blogId", gr.authController.RequireOwnerOrPermission(domain.PermDeleteAnyBlog), gr.blogController.HandleBlogDelete)
 		blogRouter.POST("/:blogId/:type", gr.authController.USERMiddleware(), gr.blogController.HandleBlogLikeOrDislike)
 		commentRouter := blogRouter.Group("/:blogId/comments")
 		commentRouter.Use(gr.authController.USERMiddleware())
 		{
 			commentRouter.GET("/", gr.blogController.HandleGetAllComments)
-			commentRouter.POST("/", gr.blogController.HandleCommentOnBlog)
+			commentRouter.POST("/", gr.authController.RequirePermission(domain.PermComment), gr.blogController.HandleCommentOnBlog)
+			commentRouter.GET("/thread", gr.blogController.HandleGetCommentThread)
 			commentRouter.GET("/:commentId", gr.blogController.HandleGetCommentById)
-			commentRouter.DELETE("/:commentId", gr.blogController.HandleGetCommentById)
+			commentRouter.GET("/:commentId/thread", gr.blogController.HandleGetCommentThread)
+			commentRouter.DELETE("/:commentId", gr.blogController.HandleDeleteComment)
 			commentRouter.POST("/:commentId/:type", gr.blogController.HandleCommentLikeOrDislike)
 
+			// replies are child comments, these routes are kept for existing clients
 			repliesRouter := commentRouter.Group("/:commentId/replies")
 			repliesRouter.Use(gr.authController.USERMiddleware())
 			{
 				repliesRouter.GET("/", gr.blogController.HandleGetAllRepliesForComment)
-				repliesRouter.POST("/", gr.blogController.HandleReplyOnComment)
-				repliesRouter.GET("/:replyId", gr.blogController.HandleGetReplyById)
-				repliesRouter.POST("/:replyId/:type", gr.blogController.HandleReplyLikeOrDislike)
+				repliesRouter.POST("/", gr.authController.RequirePermission(domain.PermComment), gr.blogController.HandleReplyOnComment)
+				repliesRouter.GET("/:replyId", gr.blogController.HandleGetCommentById)
+				repliesRouter.POST("/:replyId/:type", gr.blogController.HandleCommentLikeOrDislike)
 			}
 		}
 	}
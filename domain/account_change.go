@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// Kinds of email changes.
+const (
+	// EmailChangeConfirm is sent to the new address to confirm it.
+	EmailChangeConfirm = "confirm"
+	// EmailChangeRevert is sent to the old address once the change is made,
+	// to undo it.
+	EmailChangeRevert = "revert"
+)
+
+// EmailChange is a pending confirmation or revert of the email of a user.
+// Only the hash of its token is stored.
+type EmailChange struct {
+	// ChangeId is the hash of the token in the emailed link.
+	ChangeId  string    `bson:"_id"`
+	UserId    string    `bson:"user_id"`
+	Kind      string    `bson:"kind"`
+	OldEmail  string    `bson:"old_email"`
+	NewEmail  string    `bson:"new_email"`
+	CreatedAt time.Time `bson:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+type EmailChangeRepository interface {
+	CreateEmailChange(change EmailChange) error
+	// TakeEmailChange removes the change of the kind and returns it, so its
+	// link works once.
+	TakeEmailChange(changeId, kind string) (EmailChange, error)
+	DeleteEmailChanges(userId, kind string) error
+}
@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// Scopes of API keys. Keys with any scope can read, writes need the scope of
+// what they change.
+const (
+	ScopeRead          = "read"
+	ScopeBlogsWrite    = "blogs:write"
+	ScopeCommentsWrite = "comments:write"
+)
+
+// APIKeyScopes are the scopes API keys can be given.
+var APIKeyScopes = []string{ScopeRead, ScopeBlogsWrite, ScopeCommentsWrite}
+
+// APIKey lets automation act as a user without their password. The key is
+// its prefix and a secret, only the hash of the secret is stored.
+type APIKey struct {
+	// KeyId is the public prefix of the key.
+	KeyId      string    `json:"id" bson:"_id"`
+	UserId     string    `json:"-" bson:"user_id"`
+	Name       string    `json:"name" bson:"name"`
+	SecretHash string    `json:"-" bson:"secret_hash"`
+	Scopes     []string  `json:"scopes" bson:"scopes"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+	// ExpiresAt is zero for keys that do not expire.
+	ExpiresAt  time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	LastUsedAt time.Time `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	LastUsedIP string    `json:"last_used_ip,omitempty" bson:"last_used_ip,omitempty"`
+}
+
+// NewAPIKey is a key just created, with the key itself shown only this once.
+type NewAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type APIKeyRepository interface {
+	CreateAPIKey(key APIKey) error
+	GetAPIKey(keyId string) (APIKey, error)
+	GetAPIKeys(userId string) ([]APIKey, error)
+	DeleteAPIKey(userId string, keyId string) (bool, error)
+	TouchAPIKey(keyId string, at time.Time, ip string) error
+}
+
+// APIKeyAuthenticator checks the API keys sent instead of access tokens and
+// returns the claims of their user, limited to the scopes of the key.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(key string, ip string) (*Claims, error)
+}
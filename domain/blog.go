@@ -1,28 +1,24 @@
 package domain
 
 import (
+	"context"
 	"time"
 )
 
-type Reply struct {
-	ReplyId  string `json:"reply_id,omitempty" bson:"reply_id,omitempty" `
-	AuthorId string `json:"author_id,omitempty" bson:"author_id,omitempty" `
-
-	BlogId    string `json:"blog_id,omitempty" bson:"blog_id,omitempty" `
-	CommentId string `json:"comment_id,omitempty" bson:"comment_id,omitempty" `
-	Content   string `json:"content,omitempty" bson:"content,omitempty" binding:"required"`
-
-	Likes    []string `json:"likes,omitempty" bson:"likes,omitempty"`
-	Dislikes []string `json:"dislikes,omitempty" bson:"dislikes,omitempty"`
-	Views    int      `json:"views,omitempty" bson:"views,omitempty"`
-}
-
+// Comment is a node of a comment thread. Top level comments have no ParentId,
+// replies point at the comment they answer. Path lists the ids from the root
+// down to the comment itself ("/root/child/comment/") so that a whole subtree
+// can be fetched with a single prefix query.
 type Comment struct {
 	CommentId string `json:"comment_id,omitempty" bson:"comment_id,omitempty" `
 	AuthorId  string `json:"author_id,omitempty" bson:"author_id,omitempty"`
 
-	BlogId  string `json:"blog_id,omitempty" bson:"blog_id,omitempty" `
-	Content string `json:"content,omitempty" bson:"content,omitempty" binding:"required"`
+	BlogId   string    `json:"blog_id,omitempty" bson:"blog_id,omitempty" `
+	ParentId string    `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	Path     string    `json:"path,omitempty" bson:"path,omitempty"`
+	Depth    int       `json:"depth" bson:"depth"`
+	Date     time.Time `json:"date,omitempty" bson:"date,omitempty"`
+	Content  string    `json:"content,omitempty" bson:"content,omitempty" binding:"required"`
 
 	Likes    []string `json:"likes,omitempty" bson:"likes,omitempty"`
 	Dislikes []string `json:"dislikes,omitempty" bson:"dislikes,omitempty"`
@@ -30,6 +26,22 @@ type Comment struct {
 	Views    int      `json:"views,omitempty" bson:"views,omitempty"`
 }
 
+type CommentNode struct {
+	Comment
+	Children []CommentNode `json:"children,omitempty"`
+}
+
+const (
+	CommentSortTop    = "top"
+	CommentSortNewest = "newest"
+	CommentSortOldest = "oldest"
+)
+
+type CommentThreadOption struct {
+	MaxDepth int
+	Sort     string
+}
+
 type Blog struct {
 	BlogId   string    `json:"blog_id,omitempty" bson:"blog_id,omitempty" `
 	AuthorId string    `json:"author_id,omitempty" bson:"author_id,omitempty" `
@@ -38,6 +50,20 @@ type Blog struct {
 	Title   string   `json:"title,omitempty" bson:"title,omitempty" binding:"required"`
 	Content string   `json:"content,omitempty" bson:"content,omitempty" binding:"required"`
 	Tags    []string `json:"tags,omitempty" bson:"tags,omitempty" binding:"required"`
+	// Media are ids of uploaded media the blog uses.
+	Media []string `json:"media,omitempty" bson:"media,omitempty"`
+
+	// CoverImage is the URL of the image shown with the blog and its shared
+	// links. Setting CoverMediaId, an uploaded image, replaces it.
+	CoverImage   string `json:"cover_image,omitempty" bson:"cover_image,omitempty"`
+	CoverMediaId string `json:"cover_media_id,omitempty" bson:"cover_media_id,omitempty"`
+	// Excerpt summarises the blog, made from its content when not given.
+	Excerpt      string `json:"excerpt,omitempty" bson:"excerpt,omitempty"`
+	CanonicalURL string `json:"canonical_url,omitempty" bson:"canonical_url,omitempty"`
+	// SEOTitle and SEODescription replace the title and excerpt in search
+	// results and link previews.
+	SEOTitle       string `json:"seo_title,omitempty" bson:"seo_title,omitempty"`
+	SEODescription string `json:"seo_description,omitempty" bson:"seo_description,omitempty"`
 
 	Likes    []string `json:"likes,omitempty" bson:"likes,omitempty"  `
 	Dislikes []string `json:"dislikes,omitempty" bson:"dislikes,omitempty"  `
@@ -64,27 +90,56 @@ type BlogRecommendation struct {
 	Tags    []string `json:"tags"`
 }
 
+type RelatedBlog struct {
+	Blog  Blog    `json:"blog"`
+	Score float64 `json:"score"`
+}
+
+// MetaTag is a <meta> tag of the head of a page.
+type MetaTag struct {
+	// Property names Open Graph tags, Name the others.
+	Property string `json:"property,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Content  string `json:"content"`
+}
+
+// BlogMeta describes a blog to search engines and to the sites its links are
+// shared on.
+type BlogMeta struct {
+	Title        string                 `json:"title"`
+	Description  string                 `json:"description"`
+	CanonicalURL string                 `json:"canonical_url"`
+	Image        string                 `json:"image,omitempty"`
+	Tags         []MetaTag              `json:"tags"`
+	JSONLD       map[string]interface{} `json:"json_ld"`
+}
+
 type BlogRepository interface {
 	CreateBlog(b Blog) (Blog, error)
+	// CreateBlogWithContext creates the blog within the transaction carried by ctx, if any.
+	CreateBlogWithContext(ctx context.Context, b Blog) (Blog, error)
 	GetBlog(opts BlogFilterOption) ([]Blog, error)
 	UpdateBlog(blogId string, updateData Blog) (Blog, error)
+	// UpdateBlogWithContext updates the blog within the transaction carried
+	// by ctx, if any, and returns it as stored after the update.
+	UpdateBlogWithContext(ctx context.Context, blogId string, updateData Blog) (Blog, error)
 	DeleteBlog(blogId, authorId string) error
+	DeleteBlogWithContext(ctx context.Context, blogId, authorId string) error
 	FindPopularBlog() ([]Blog, error)
 	GetBlogById(blogid string) (Blog, error)
 	LikeOrDislikeBlog(blogId, userId string, like int) (string, error)
 
-	AddComment(blogid string, comment Comment) error
+	AddComment(blogid string, comment Comment) (Comment, error)
+	AddCommentWithContext(ctx context.Context, blogid string, comment Comment) (Comment, error)
 	GetAllComments(blogId string,opt PaginationInfo) ([]Comment, error)
+	GetChildComments(blogId, parentId string, opt PaginationInfo) ([]Comment, error)
+	GetCommentThread(blogId, rootId string, maxDepth int) ([]Comment, error)
 	GetCommentById(blogId, commentId string) (Comment, error)
-	LikeOrDislikeComment(blogId, commentId, userId string, like int) error
+	// LikeOrDislikeComment reports whether the like or dislike was added,
+	// false when the user had already left it or for a view.
+	LikeOrDislikeComment(blogId, commentId, userId string, like int) (bool, error)
 	UpdateComment(blogId, commentId,authorId string, updateData Comment) (Comment, error) 
-	DeleteComment(blogId, commentId,authorId string) error 
-
-
-	AddReply(blogId, commentId string, reply Reply) error
-	GetAllReplies(blogId,commentId string,opt PaginationInfo) ([]Reply, error)
-	GetReplyById(blogId,commentId,replyId string) (Reply, error)
-	LikeOrDislikeReply(blogId,commentId,replyId, userId string, like int) error
-	UpdateReply(blogId, commentId, replyId,authorId string, updateData Reply) (Reply, error) 
-	DeleteReply(blogId, commentId, replyId,authorId string) error 
+	// DeleteComment deletes the comment with its replies and returns the ids
+	// of all the deleted comments.
+	DeleteComment(blogId, commentId,authorId string) ([]string, error) 
 }
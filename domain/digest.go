@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// Digest frequencies.
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// DigestSubscription is a user's choice of digest emails. Authors and Tags
+// are the author ids and tags the user follows, digests fall back to the
+// trending posts when none of them published anything in the period.
+// UnsubscribeToken lets the user opt out from the email without logging in.
+type DigestSubscription struct {
+	UserId           string   `json:"user_id" bson:"_id"`
+	Enabled          bool     `json:"enabled" bson:"enabled"`
+	Frequency        string   `json:"frequency" bson:"frequency"`
+	Authors          []string `json:"authors" bson:"authors"`
+	Tags             []string `json:"tags" bson:"tags"`
+	UnsubscribeToken string   `json:"-" bson:"unsubscribe_token,omitempty"`
+}
+
+// DigestLog records a digest sent to a user. LogId is made of the user id and
+// the period, e.g. "2026-W42", so each period is sent at most once.
+type DigestLog struct {
+	LogId     string    `json:"log_id" bson:"_id"`
+	UserId    string    `json:"user_id" bson:"user_id"`
+	Frequency string    `json:"frequency" bson:"frequency"`
+	Period    string    `json:"period" bson:"period"`
+	BlogIds   []string  `json:"blog_ids" bson:"blog_ids"`
+	SentAt    time.Time `json:"sent_at" bson:"sent_at"`
+}
+
+type DigestRepository interface {
+	// GetSubscription returns a disabled subscription for users that never
+	// subscribed.
+	GetSubscription(userId string) (DigestSubscription, error)
+	GetSubscriptionByToken(token string) (DigestSubscription, error)
+	SaveSubscription(subscription DigestSubscription) (DigestSubscription, error)
+	// GetSubscriptions returns the enabled subscriptions with the frequency.
+	GetSubscriptions(frequency string) ([]DigestSubscription, error)
+	// LogSend stores entry and reports false when it was already stored.
+	LogSend(entry DigestLog) (bool, error)
+	RemoveLog(logId string) error
+}
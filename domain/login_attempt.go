@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// LoginAttempts counts the failed logins of an account or of an IP address,
+// keyed "user:<id>", "login:<username or email>" or "ip:<address>".
+type LoginAttempts struct {
+	Key           string    `json:"key" bson:"_id"`
+	Failures      int       `json:"failures" bson:"failures"`
+	LastFailureAt time.Time `json:"last_failure_at" bson:"last_failure_at"`
+	LockedUntil   time.Time `json:"locked_until,omitempty" bson:"locked_until,omitempty"`
+	ExpiresAt     time.Time `json:"-" bson:"expires_at"`
+}
+
+// LoginBlocked is the answer to a login refused before checking the password.
+type LoginBlocked struct {
+	Error           string `json:"error"`
+	RetryAfter      int    `json:"retry_after"`
+	Locked          bool   `json:"locked,omitempty"`
+	CaptchaRequired bool   `json:"captcha_required,omitempty"`
+}
+
+type LoginAttemptRepository interface {
+	// GetLoginAttempts returns the attempts of the key, with no failures when
+	// there are none.
+	GetLoginAttempts(key string) (LoginAttempts, error)
+	// AddLoginFailure counts a failure at the time and returns the new count.
+	AddLoginFailure(key string, at, expiresAt time.Time) (LoginAttempts, error)
+	LockLoginAttempts(key string, until time.Time) error
+	DeleteLoginAttempts(key string) error
+}
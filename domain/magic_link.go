@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// MagicLink is a passwordless login sent by email. Only hashes of its tokens
+// are stored.
+type MagicLink struct {
+	// LinkId is the hash of the token in the emailed link.
+	LinkId string `bson:"_id"`
+	UserId string `bson:"user_id"`
+	// DeviceHash is the hash of the token given to the device that asked for
+	// the link, only that device can redeem it.
+	DeviceHash string    `bson:"device_hash"`
+	Device     string    `bson:"device"`
+	IP         string    `bson:"ip"`
+	CreatedAt  time.Time `bson:"created_at"`
+	ExpiresAt  time.Time `bson:"expires_at"`
+}
+
+// MagicLinkRequest is the answer to asking for a link. The device token is
+// sent back with the token of the link to redeem it.
+type MagicLinkRequest struct {
+	Message     string `json:"message"`
+	DeviceToken string `json:"device_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+type MagicLinkRepository interface {
+	CreateMagicLink(link MagicLink) error
+	// TakeMagicLink removes the link and returns it, so it is used once.
+	TakeMagicLink(linkId string) (MagicLink, error)
+}
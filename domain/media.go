@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// Media is a file uploaded by a user, such as an image for their blogs or
+// profile.
+type Media struct {
+	MediaId     string `json:"media_id" bson:"_id"`
+	OwnerId     string `json:"owner_id" bson:"owner_id"`
+	Filename    string `json:"filename" bson:"filename"`
+	ContentType string `json:"content_type" bson:"content_type"`
+	Size        int64  `json:"size" bson:"size"`
+	// Width and Height are those of images.
+	Width  int `json:"width,omitempty" bson:"width,omitempty"`
+	Height int `json:"height,omitempty" bson:"height,omitempty"`
+	// Key and ThumbnailKey locate the file and its thumbnail in the storage.
+	Key           string `json:"-" bson:"key"`
+	ThumbnailKey  string `json:"-" bson:"thumbnail_key,omitempty"`
+	ThumbnailSize int64  `json:"-" bson:"thumbnail_size,omitempty"`
+	URL           string `json:"url" bson:"-"`
+	ThumbnailURL  string `json:"thumbnail_url,omitempty" bson:"-"`
+	// References are what uses the media, "blog:<id>" or "profile:<id>".
+	// Media nothing references are deleted after a while.
+	References []string  `json:"references" bson:"references"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+}
+
+// MediaUsage is how much storage a user uses out of their quota, in bytes.
+type MediaUsage struct {
+	Used  int64 `json:"used"`
+	Quota int64 `json:"quota"`
+}
+
+type MediaRepository interface {
+	CreateMedia(media Media) error
+	GetMedia(mediaId string) (Media, error)
+	GetUserMedia(ownerId string) ([]Media, error)
+	// GetUsage sums the size of the files of the user and their thumbnails.
+	GetUsage(ownerId string) (int64, error)
+	// SetReferences makes the reference point to exactly the given media.
+	SetReferences(reference string, mediaIds []string) error
+	// GetOrphans returns media created before the time that nothing references.
+	GetOrphans(before time.Time) ([]Media, error)
+	DeleteMedia(mediaId string) error
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// Mention records that a blog or a comment mentions a user with @username.
+type Mention struct {
+	MentionId       string    `json:"mention_id" bson:"_id"`
+	MentionedUserId string    `json:"mentioned_user_id" bson:"mentioned_user_id"`
+	Username        string    `json:"username" bson:"username"`
+	AuthorId        string    `json:"author_id" bson:"author_id"`
+	BlogId          string    `json:"blog_id" bson:"blog_id"`
+	CommentId       string    `json:"comment_id,omitempty" bson:"comment_id,omitempty"`
+	Date            time.Time `json:"date" bson:"date"`
+}
+
+type MentionRepository interface {
+	// ReplaceMentions makes the stored mentions of a blog (or of one of its
+	// comments when commentId is set) equal to the given ones and returns
+	// those that were not stored before.
+	ReplaceMentions(blogId, commentId string, mentions []Mention) ([]Mention, error)
+	GetMentionsForUser(userId string, opts PaginationInfo) ([]Mention, error)
+	// DeleteMentions removes the mentions of a comment, or every mention made
+	// in a blog and its comments when commentId is empty.
+	DeleteMentions(blogId, commentId string) error
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// What a newsletter subscription follows.
+const (
+	NewsletterAuthor = "author"
+	NewsletterTag    = "tag"
+)
+
+const (
+	SubscriberPending      = "pending"
+	SubscriberConfirmed    = "confirmed"
+	SubscriberUnsubscribed = "unsubscribed"
+)
+
+// NewsletterSubscriber is a reader, usually without an account, who gets an
+// email when an author publishes or a post with a tag is published. Target is
+// the author id or the tag. Subscriptions are only active once confirmed with
+// the ConfirmToken sent to the email address.
+type NewsletterSubscriber struct {
+	SubscriberId     string    `json:"subscriber_id" bson:"_id"`
+	Email            string    `json:"email" bson:"email"`
+	Kind             string    `json:"kind" bson:"kind"`
+	Target           string    `json:"target" bson:"target"`
+	Status           string    `json:"status" bson:"status"`
+	ConfirmToken     string    `json:"-" bson:"confirm_token,omitempty"`
+	UnsubscribeToken string    `json:"-" bson:"unsubscribe_token,omitempty"`
+	CreatedAt        time.Time `json:"created_at" bson:"created_at"`
+	ConfirmedAt      time.Time `json:"confirmed_at,omitempty" bson:"confirmed_at,omitempty"`
+}
+
+// NewsletterFilter selects subscribers, empty fields match everything. A zero
+// Pagination returns all of them.
+type NewsletterFilter struct {
+	Email            string
+	Kind             string
+	Target           string
+	Status           string
+	ConfirmToken     string
+	UnsubscribeToken string
+	Pagination       PaginationInfo
+}
+
+type NewsletterRepository interface {
+	GetSubscribers(filter NewsletterFilter) ([]NewsletterSubscriber, error)
+	// GetSubscribersForBlog returns the confirmed subscribers of the author
+	// or of any of the tags.
+	GetSubscribersForBlog(authorId string, tags []string) ([]NewsletterSubscriber, error)
+	SaveSubscriber(subscriber NewsletterSubscriber) (NewsletterSubscriber, error)
+	DeleteSubscriber(subscriberId string) error
+}
@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// Notification event types.
+const (
+	NotificationComment  = "comment"
+	NotificationReply    = "reply"
+	NotificationReaction = "reaction"
+	NotificationFollow   = "follow"
+	NotificationMention  = "mention"
+	NotificationReview   = "review"
+)
+
+// NotificationTypes lists every event type a user can receive.
+var NotificationTypes = []string{
+	NotificationComment,
+	NotificationReply,
+	NotificationReaction,
+	NotificationFollow,
+	NotificationMention,
+	NotificationReview,
+}
+
+type Notification struct {
+	NotificationId string    `json:"notification_id" bson:"_id"`
+	UserId         string    `json:"user_id" bson:"user_id"`
+	ActorId        string    `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	Type           string    `json:"type" bson:"type"`
+	BlogId         string    `json:"blog_id,omitempty" bson:"blog_id,omitempty"`
+	CommentId      string    `json:"comment_id,omitempty" bson:"comment_id,omitempty"`
+	Message        string    `json:"message" bson:"message"`
+	Read           bool      `json:"read" bson:"read"`
+	Date           time.Time `json:"date" bson:"date"`
+	// Key collapses repeated events, a notification with the same key as an
+	// existing one replaces it and becomes unread again.
+	Key string `json:"-" bson:"key,omitempty"`
+}
+
+// NotificationPreferences holds the event types a user turned on or off.
+// Types that are not listed are enabled.
+type NotificationPreferences struct {
+	UserId string          `json:"-" bson:"_id"`
+	Events map[string]bool `json:"events" bson:"events"`
+}
+
+func (p NotificationPreferences) Enabled(eventType string) bool {
+	enabled, ok := p.Events[eventType]
+	return !ok || enabled
+}
+
+type NotificationFilterOption struct {
+	UnreadOnly bool
+	Pagination PaginationInfo
+}
+
+type NotificationRepository interface {
+	CreateNotification(n Notification) (Notification, error)
+	GetNotifications(userId string, opts NotificationFilterOption) ([]Notification, error)
+	CountUnread(userId string) (int64, error)
+	// MarkRead marks the given notifications of a user as read, or all of
+	// them when no id is given, and returns how many changed.
+	MarkRead(userId string, notificationIds []string) (int64, error)
+	GetPreferences(userId string) (NotificationPreferences, error)
+	UpdatePreferences(prefs NotificationPreferences) (NotificationPreferences, error)
+}
@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+// Identity links a user to their account at an OpenID Connect provider.
+type Identity struct {
+	IdentityId string    `json:"-" bson:"_id"`
+	Provider   string    `json:"provider" bson:"provider"`
+	Subject    string    `json:"subject" bson:"subject"`
+	UserId     string    `json:"user_id" bson:"user_id"`
+	Email      string    `json:"email" bson:"email"`
+	LinkedAt   time.Time `json:"linked_at" bson:"linked_at"`
+}
+
+// OIDCState is a login started at a provider, kept until its callback.
+type OIDCState struct {
+	StateId  string `bson:"_id"`
+	Provider string `bson:"provider"`
+	Nonce    string `bson:"nonce"`
+	// Verifier is the PKCE code verifier sent with the code.
+	Verifier string `bson:"verifier"`
+	// UserId is the logged in user who links the provider account, empty
+	// for a login.
+	UserId    string    `bson:"user_id,omitempty"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// OIDCClaims are the claims of a verified ID token.
+type OIDCClaims struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Nonce         string `json:"nonce"`
+}
+
+// OIDCProvider runs the authorization code flow with PKCE against one
+// OpenID Connect provider.
+type OIDCProvider interface {
+	Name() string
+	AuthCodeURL(state, nonce, codeChallenge string) (string, error)
+	// Exchange trades the code for tokens and returns the claims of the
+	// verified ID token.
+	Exchange(code, codeVerifier string) (OIDCClaims, error)
+}
+
+type IdentityRepository interface {
+	GetIdentity(provider string, subject string) (Identity, error)
+	GetIdentities(userId string) ([]Identity, error)
+	SaveIdentity(identity Identity) error
+	DeleteIdentity(userId string, provider string) (bool, error)
+	SaveState(state OIDCState) error
+	// TakeState returns the state and deletes it, so it is only used once.
+	TakeState(stateId string) (OIDCState, error)
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Outbox message kinds.
+const (
+	OutboxEmail        = "email"
+	OutboxWebhook      = "webhook"
+	OutboxNotification = "notification"
+)
+
+const (
+	OutboxPending   = "pending"
+	OutboxDelivered = "delivered"
+	OutboxDead      = "dead"
+)
+
+// OutboxMessage is a side effect of a domain change, stored with the change
+// and delivered afterwards by the dispatcher. IdempotencyKey is unique, so
+// storing the same side effect twice keeps a single message.
+type OutboxMessage struct {
+	MessageId      string    `json:"message_id" bson:"_id"`
+	Kind           string    `json:"kind" bson:"kind"`
+	IdempotencyKey string    `json:"idempotency_key" bson:"idempotency_key"`
+	Payload        string    `json:"payload" bson:"payload"`
+	Status         string    `json:"status" bson:"status"`
+	Attempts       int       `json:"attempts" bson:"attempts"`
+	LastError      string    `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	NextAttempt    time.Time `json:"next_attempt" bson:"next_attempt"`
+	LockedUntil    time.Time `json:"-" bson:"locked_until,omitempty"`
+	CreatedAt      time.Time `json:"created_at" bson:"created_at"`
+	DeliveredAt    time.Time `json:"delivered_at,omitempty" bson:"delivered_at,omitempty"`
+}
+
+// OutboxEmailPayload is the payload of an email message. Template names one
+// of the email templates, messages without one are sent as notifications.
+type OutboxEmailPayload struct {
+	To       string `json:"to"`
+	Template string `json:"template,omitempty"`
+	Locale   string `json:"locale,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Title    string `json:"title"`
+	Body     string `json:"body"`
+	Link     string `json:"link"`
+	// Unsubscribe is the link to stop emails like this one, if any.
+	Unsubscribe string `json:"unsubscribe,omitempty"`
+}
+
+type OutboxRepository interface {
+	// Enqueue stores the messages as part of the transaction carried by ctx,
+	// if any. Messages whose idempotency key is already stored are skipped.
+	Enqueue(ctx context.Context, messages []OutboxMessage) error
+	// Claim locks up to limit pending messages that are due so that no other
+	// dispatcher handles them meanwhile.
+	Claim(now time.Time, lockFor time.Duration, limit int) ([]OutboxMessage, error)
+	MarkDelivered(messageId string, at time.Time) error
+	// MarkFailed counts a failed attempt and either schedules the next one or
+	// moves the message to the dead letters.
+	MarkFailed(messageId, lastError string, nextAttempt time.Time, dead bool) error
+	GetMessages(status string, opts PaginationInfo) ([]OutboxMessage, error)
+	// Requeue makes a dead message pending again with a fresh attempt count.
+	Requeue(messageId string, at time.Time) error
+}
+
+// Transactor runs several writes as one unit. Repositories join the
+// transaction when they are given the ctx passed to fn.
+type Transactor interface {
+	WithTransaction(fn func(ctx context.Context) error) error
+}
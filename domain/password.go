@@ -0,0 +1,18 @@
+package domain
+
+// PasswordPolicy is what new passwords must satisfy.
+type PasswordPolicy struct {
+	MinLength int `json:"min_length"`
+	// MinCharacterClasses is how many of lowercase letters, uppercase
+	// letters, digits and symbols a password must mix.
+	MinCharacterClasses int `json:"min_character_classes"`
+	// History is how many of the latest passwords, the current one included,
+	// cannot be used again.
+	History int `json:"history"`
+}
+
+// BreachedPasswordChecker tells whether a password is known from a data
+// breach.
+type BreachedPasswordChecker interface {
+	IsBreached(password string) (bool, error)
+}
@@ -0,0 +1,88 @@
+package domain
+
+import "time"
+
+// Social networks profiles can link to.
+var SocialNetworks = []string{"github", "gitlab", "x", "mastodon", "linkedin", "bluesky", "youtube", "instagram", "facebook"}
+
+// Profile is what a user tells about themselves publicly.
+type Profile struct {
+	UserId      string `json:"-" bson:"_id"`
+	DisplayName string `json:"display_name" bson:"display_name"`
+	Bio         string `json:"bio" bson:"bio"`
+	AvatarURL   string `json:"avatar_url" bson:"avatar_url"`
+	// AvatarMediaId is an uploaded image used as avatar, in place of the
+	// avatar URL.
+	AvatarMediaId string `json:"avatar_media_id,omitempty" bson:"avatar_media_id,omitempty"`
+	Location      string `json:"location" bson:"location"`
+	Website       string `json:"website" bson:"website"`
+	// SocialLinks map networks to the URLs of the user on them.
+	SocialLinks map[string]string `json:"social_links" bson:"social_links"`
+	// PinnedPosts are ids of blogs of the user shown first, in order.
+	PinnedPosts []string  `json:"pinned_posts" bson:"pinned_posts"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty" bson:"updated_at"`
+}
+
+// PinnedPost summarises a pinned blog on a profile.
+type PinnedPost struct {
+	BlogId string    `json:"blog_id"`
+	Title  string    `json:"title"`
+	Date   time.Time `json:"date"`
+	Tags   []string  `json:"tags,omitempty"`
+}
+
+// PublicProfile is the profile of a user as anyone sees it.
+type PublicProfile struct {
+	Username    string            `json:"username"`
+	DisplayName string            `json:"display_name"`
+	Bio         string            `json:"bio,omitempty"`
+	AvatarURL   string            `json:"avatar_url,omitempty"`
+	Location    string            `json:"location,omitempty"`
+	Website     string            `json:"website,omitempty"`
+	SocialLinks map[string]string `json:"social_links,omitempty"`
+	PinnedPosts []PinnedPost      `json:"pinned_posts,omitempty"`
+	Roles       []string          `json:"roles,omitempty"`
+}
+
+// PublicUser is a user without their credentials, tokens or email, for
+// listings anyone can read.
+type PublicUser struct {
+	ID        string   `json:"id"`
+	Username  string   `json:"username"`
+	FirstName string   `json:"first_name"`
+	LastName  string   `json:"last_name"`
+	IsAdmin   bool     `json:"is_admin"`
+	Roles     []string `json:"roles,omitempty"`
+}
+
+// Public returns what anyone may see of the user.
+func (u User) Public() PublicUser {
+	return PublicUser{
+		ID:        u.ID,
+		Username:  u.Username,
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		IsAdmin:   u.IsAdmin,
+		Roles:     u.Roles,
+	}
+}
+
+// AccountUser is a user without their credentials or tokens, as they or an
+// admin see the account.
+type AccountUser struct {
+	PublicUser
+	Email    string `json:"email"`
+	IsActive bool   `json:"is_active"`
+	Locale   string `json:"locale,omitempty"`
+}
+
+// Account returns what the user and admins may see of the account.
+func (u User) Account() AccountUser {
+	return AccountUser{PublicUser: u.Public(), Email: u.Email, IsActive: u.IsActive, Locale: u.Locale}
+}
+
+type ProfileRepository interface {
+	// GetProfile returns the profile of the user, empty when they have none.
+	GetProfile(userId string) (Profile, error)
+	SaveProfile(profile Profile) error
+}
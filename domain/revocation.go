@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// RevokedToken is an access token that stops working before it expires. It
+// only needs to be kept until ExpiresAt.
+type RevokedToken struct {
+	TokenId   string    `json:"jti" bson:"_id"`
+	UserId    string    `json:"user_id" bson:"user_id"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at" bson:"revoked_at"`
+}
+
+type RevocationRepository interface {
+	RevokeToken(token RevokedToken) error
+	// GetRevokedTokens returns the revoked tokens that did not expire at now.
+	GetRevokedTokens(now time.Time) ([]RevokedToken, error)
+}
+
+// TokenRevoker invalidates access tokens before they expire, one at a time
+// or all the tokens of a user at once.
+type TokenRevoker interface {
+	RevokeToken(claims *Claims) error
+	RevokeUserTokens(userId string) error
+}
@@ -0,0 +1,95 @@
+package domain
+
+// Permissions name what roles allow. Reading public content needs none.
+const (
+	PermCreateBlog       = "blogs:create"
+	PermEditAnyBlog      = "blogs:edit-any"
+	PermDeleteAnyBlog    = "blogs:delete-any"
+	PermComment          = "comments:create"
+	PermDeleteAnyComment = "comments:delete-any"
+	PermManageUsers      = "users:manage"
+	PermManageRoles      = "roles:manage"
+	PermManageWebhooks   = "webhooks:manage"
+	PermManageOutbox     = "outbox:manage"
+)
+
+// Permissions are all the permissions roles can grant.
+var Permissions = []string{
+	PermCreateBlog, PermEditAnyBlog, PermDeleteAnyBlog,
+	PermComment, PermDeleteAnyComment,
+	PermManageUsers, PermManageRoles, PermManageWebhooks, PermManageOutbox,
+}
+
+// Built-in roles. Users without a role are authors, like every user was
+// before roles existed.
+const (
+	RoleReader    = "reader"
+	RoleAuthor    = "author"
+	RoleEditor    = "editor"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+	DefaultRole   = RoleAuthor
+)
+
+// Role is a named set of permissions. Built-in roles are defined here, custom
+// ones are stored.
+type Role struct {
+	Name        string   `json:"name" bson:"_id"`
+	Description string   `json:"description" bson:"description"`
+	Permissions []string `json:"permissions" bson:"permissions"`
+	BuiltIn     bool     `json:"built_in" bson:"-"`
+}
+
+var BuiltInRoles = []Role{
+	{Name: RoleReader, Description: "Reads and comments", Permissions: []string{PermComment}},
+	{Name: RoleAuthor, Description: "Writes their own blogs", Permissions: []string{PermComment, PermCreateBlog}},
+	{Name: RoleEditor, Description: "Edits and deletes any blog", Permissions: []string{PermComment, PermCreateBlog, PermEditAnyBlog, PermDeleteAnyBlog}},
+	{Name: RoleModerator, Description: "Removes abusive blogs and comments", Permissions: []string{PermComment, PermDeleteAnyBlog, PermDeleteAnyComment}},
+	{Name: RoleAdmin, Description: "Can do everything", Permissions: Permissions},
+}
+
+// BuiltInRole returns the built-in role with the name.
+func BuiltInRole(name string) (Role, bool) {
+	for _, role := range BuiltInRoles {
+		if role.Name == name {
+			role.BuiltIn = true
+			return role, true
+		}
+	}
+	return Role{}, false
+}
+
+// EffectiveRoles are the roles the user acts with: their roles, the default
+// one when they have none, and admin for admins.
+func (u User) EffectiveRoles() []string {
+	roles := append([]string{}, u.Roles...)
+	if len(roles) == 0 {
+		roles = append(roles, DefaultRole)
+	}
+	if u.IsAdmin && !containsRole(roles, RoleAdmin) {
+		roles = append(roles, RoleAdmin)
+	}
+	return roles
+}
+
+func containsRole(roles []string, name string) bool {
+	for _, role := range roles {
+		if role == name {
+			return true
+		}
+	}
+	return false
+}
+
+type RoleRepository interface {
+	// GetRoles returns the custom roles.
+	GetRoles() ([]Role, error)
+	SaveRole(role Role) error
+	DeleteRole(name string) (bool, error)
+}
+
+// PermissionChecker resolves the permissions granted by roles, built-in or
+// custom.
+type PermissionChecker interface {
+	Permissions(roles []string) ([]string, error)
+}
@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// Session is a login on one device. Its refresh token changes on every
+// refresh and only its hash is stored. UsedHashes keeps the hashes of the
+// tokens it replaced, a rotated token presented again means it was stolen
+// and the whole session is revoked.
+type Session struct {
+	SessionId     string    `json:"session_id" bson:"_id"`
+	UserId        string    `json:"user_id" bson:"user_id"`
+	TokenHash     string    `json:"-" bson:"token_hash"`
+	UsedHashes    []string  `json:"-" bson:"used_hashes"`
+	Device        string    `json:"device" bson:"device"`
+	IP            string    `json:"ip" bson:"ip"`
+	CreatedAt     time.Time `json:"created_at" bson:"created_at"`
+	LastSeen      time.Time `json:"last_seen" bson:"last_seen"`
+	ExpiresAt     time.Time `json:"expires_at" bson:"expires_at"`
+	RevokedAt     time.Time `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	RevokedReason string    `json:"revoked_reason,omitempty" bson:"revoked_reason,omitempty"`
+	// TwoFactor is set when the login passed a second factor.
+	TwoFactor bool `json:"two_factor" bson:"two_factor"`
+	// Current marks the session of the token used to list the sessions.
+	Current bool `json:"current" bson:"-"`
+}
+
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt.IsZero() && now.Before(s.ExpiresAt)
+}
+
+// TokenPair is what logging in and refreshing return.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	// ExpiresIn is the lifetime of the access token in seconds.
+	ExpiresIn int    `json:"expires_in"`
+	SessionId string `json:"session_id"`
+}
+
+type SessionRepository interface {
+	CreateSession(session Session) (Session, error)
+	// FindSession returns the session whose current or a previous refresh
+	// token has the hash.
+	FindSession(tokenHash string) (Session, error)
+	// RotateSession replaces the refresh token hash if it is still oldHash
+	// and reports whether it did.
+	RotateSession(sessionId, oldHash, newHash, device, ip string, lastSeen time.Time) (bool, error)
+	GetSessions(userId string) ([]Session, error)
+	// RevokeSession revokes a session of the user and reports whether there
+	// was an active one.
+	RevokeSession(userId, sessionId, reason string, at time.Time) (bool, error)
+	RevokeUserSessions(userId, reason string, at time.Time) (int64, error)
+}
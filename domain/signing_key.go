@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// SigningKey is a key pair that signs access tokens. A key signs new tokens
+// until RetiresAt and verifies them until ExpiresAt, so that tokens signed
+// just before a rotation stay valid.
+type SigningKey struct {
+	KeyId     string `json:"kid" bson:"_id"`
+	Algorithm string `json:"alg" bson:"algorithm"`
+	// PrivateKey is PKCS #8 DER, PublicKey is PKIX DER.
+	PrivateKey []byte    `json:"-" bson:"private_key"`
+	PublicKey  []byte    `json:"-" bson:"public_key"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+	RetiresAt  time.Time `json:"retires_at" bson:"retires_at"`
+	ExpiresAt  time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+type SigningKeyRepository interface {
+	// GetSigningKeys returns the keys that did not expire at now.
+	GetSigningKeys(now time.Time) ([]SigningKey, error)
+	SaveSigningKey(key SigningKey) error
+}
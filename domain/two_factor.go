@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+// TwoFactor is the TOTP second factor of a user. It is pending between
+// enrollment and the first verified code, and only asked for once enabled.
+type TwoFactor struct {
+	UserId  string `json:"user_id" bson:"_id"`
+	Secret  string `json:"-" bson:"secret"`
+	Enabled bool   `json:"enabled" bson:"enabled"`
+	// RecoveryCodes are the hashes of the unused recovery codes.
+	RecoveryCodes []string `json:"-" bson:"recovery_codes"`
+	// LastStep is the time step of the last accepted code, a code is never
+	// accepted twice.
+	LastStep  int64     `json:"-" bson:"last_step"`
+	EnabledAt time.Time `json:"enabled_at,omitempty" bson:"enabled_at,omitempty"`
+}
+
+// TwoFactorStatus is what a user sees of their second factor.
+type TwoFactorStatus struct {
+	Enabled           bool      `json:"enabled"`
+	Required          bool      `json:"required"`
+	RecoveryCodesLeft int       `json:"recovery_codes_left"`
+	EnabledAt         time.Time `json:"enabled_at,omitempty"`
+}
+
+// TwoFactorEnrollment is the secret of a new second factor, to add to an
+// authenticator app by hand or by the QR code of ProvisioningURI.
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// LoginChallenge is a login that passed the password check and waits for
+// the second factor.
+type LoginChallenge struct {
+	ChallengeId string    `json:"-" bson:"_id"`
+	UserId      string    `json:"user_id" bson:"user_id"`
+	Attempts    int       `json:"attempts" bson:"attempts"`
+	ExpiresAt   time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+// LoginChallengeResponse is returned by a login that needs the second factor.
+type LoginChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in"`
+}
+
+type TwoFactorRepository interface {
+	// GetTwoFactor returns an empty TwoFactor of the user when there is none.
+	GetTwoFactor(userId string) (TwoFactor, error)
+	SaveTwoFactor(twoFactor TwoFactor) error
+	DeleteTwoFactor(userId string) error
+	// UseStep records that the code of step was used, false when a code of
+	// that step or a later one was used already.
+	UseStep(userId string, step int64) (bool, error)
+	// UseRecoveryCode removes the recovery code, false when it was not there.
+	UseRecoveryCode(userId string, hash string) (bool, error)
+	CreateChallenge(challenge LoginChallenge) error
+	// CountChallengeAttempt counts a code tried against the challenge and
+	// returns it with the new count.
+	CountChallengeAttempt(challengeId string) (LoginChallenge, error)
+	DeleteChallenge(challengeId string) error
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"time"
 
 	"github.com/golang-jwt/jwt"
@@ -29,7 +30,17 @@ type User struct {
 	RefreshToken   string    `json:"-"`
 	ExpirationDate time.Time `json:"expirationtoken"`
 	IsAdmin        bool      `json:"is_admin"`
+	// Roles grant the permissions of the user, IsAdmin mirrors the admin role.
+	Roles          []string  `json:"roles,omitempty" bson:"roles,omitempty"`
 	IsActive       bool      `json:"is_active"`
+	Locale         string    `json:"locale,omitempty"`
+	// TokenVersion is bumped to invalidate every access token of the user.
+	TokenVersion int `json:"-" bson:"token_version"`
+	// PasswordHistory holds the hashes of the previous passwords, newest
+	// first, so they are not used again.
+	PasswordHistory []string `json:"-" bson:"password_history,omitempty"`
+	// UsernameChangedAt is when the username was last changed.
+	UsernameChangedAt time.Time `json:"-" bson:"username_changed_at,omitempty"`
 }
 type UserFilter struct {
 	UserId    string
@@ -46,8 +57,18 @@ type UserFilterOption struct {
 type UserRepository interface {
 	Get(opts UserFilterOption) ([]User, error)
 	Create(u *User) (User, error)
+	CreateWithContext(ctx context.Context, u *User) (User, error)
 	Update(userId string, updateData User) (User, error)
 	Delete(userId string) error
+	// BumpTokenVersion increments the token version of the user and returns it.
+	BumpTokenVersion(userId string) (int, error)
+	// SetRoles replaces the roles of the user and keeps IsAdmin in line.
+	SetRoles(userId string, roles []string) (User, error)
+	// SetEmail changes the email of the user, which must not be taken.
+	SetEmail(userId, email string) (User, error)
+	// SetUsername changes the username of the user, which must not be taken,
+	// and records when.
+	SetUsername(userId, username string, at time.Time) (User, error)
 }
 type UserUsecase interface {
 	Get() ([]User, error)
@@ -62,6 +83,8 @@ type UserUsecase interface {
 	ResetPassword(email string, token string, password string) (string, error)
 	ForgetPassword(email string) (string, error)
 	LoginUser(uname string, password string,email string) (string, error)
+	// Authenticate checks the credentials of an active user.
+	Authenticate(uname string, password string, email string) (User, error)
 	Logout(email string) error
 	DemoteUser(userId string) (User, error)
 	PromteUser(userId string) (User, error)
@@ -75,6 +98,17 @@ type Claims struct {
 	IsAdmin  bool   `json:"is_admin"`
 	Username string `json:"username"`
 	IsActive bool   `json:"is_active"`
+	// SessionId is the session the token was issued for, if any.
+	SessionId string `json:"sid,omitempty"`
+	// TokenVersion is the token version of the user when the token was issued.
+	TokenVersion int `json:"ver,omitempty"`
+	// TwoFactor is set when the login passed a second factor.
+	TwoFactor bool `json:"mfa,omitempty"`
+	// Scopes limit the requests of an API key, they are empty for access
+	// tokens.
+	Scopes []string `json:"scopes,omitempty"`
+	// Roles are the effective roles of the user when the token was issued.
+	Roles []string `json:"roles,omitempty"`
 	jwt.StandardClaims
 }
 
@@ -0,0 +1,87 @@
+package domain
+
+import "time"
+
+// Webhook events.
+const (
+	EventBlogCreated    = "blog.created"
+	EventBlogUpdated    = "blog.updated"
+	EventBlogDeleted    = "blog.deleted"
+	EventCommentCreated = "comment.created"
+	EventUserRegistered = "user.registered"
+)
+
+// WebhookEvents lists the events a webhook can subscribe to.
+var WebhookEvents = []string{
+	EventBlogCreated,
+	EventBlogUpdated,
+	EventBlogDeleted,
+	EventCommentCreated,
+	EventUserRegistered,
+}
+
+// EventEmitter receives domain events for delivery outside the process.
+type EventEmitter interface {
+	Emit(event string, data interface{}) error
+}
+
+type Webhook struct {
+	WebhookId string    `json:"webhook_id" bson:"_id"`
+	URL       string    `json:"url" bson:"url" binding:"required"`
+	Events    []string  `json:"events" bson:"events" binding:"required"`
+	Secret    string    `json:"secret,omitempty" bson:"secret"`
+	Active    *bool     `json:"active,omitempty" bson:"active"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+func (w Webhook) IsActive() bool {
+	return w.Active == nil || *w.Active
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+type WebhookAttempt struct {
+	Date       time.Time     `json:"date" bson:"date"`
+	StatusCode int           `json:"status_code,omitempty" bson:"status_code,omitempty"`
+	Error      string        `json:"error,omitempty" bson:"error,omitempty"`
+	Duration   time.Duration `json:"duration" bson:"duration"`
+}
+
+// WebhookDelivery is one event sent to one webhook. Payload keeps the exact
+// JSON body so retries and redeliveries carry the same signature input.
+type WebhookDelivery struct {
+	DeliveryId   string           `json:"delivery_id" bson:"_id"`
+	WebhookId    string           `json:"webhook_id" bson:"webhook_id"`
+	Event        string           `json:"event" bson:"event"`
+	Payload      string           `json:"payload" bson:"payload"`
+	Status       string           `json:"status" bson:"status"`
+	Attempts     []WebhookAttempt `json:"attempts" bson:"attempts"`
+	NextAttempt  time.Time        `json:"next_attempt,omitempty" bson:"next_attempt,omitempty"`
+	RedeliveryOf string           `json:"redelivery_of,omitempty" bson:"redelivery_of,omitempty"`
+	CreatedAt    time.Time        `json:"created_at" bson:"created_at"`
+	LockedUntil  time.Time        `json:"-" bson:"locked_until,omitempty"`
+}
+
+type WebhookRepository interface {
+	CreateWebhook(w Webhook) (Webhook, error)
+	GetWebhooks() ([]Webhook, error)
+	GetWebhookById(webhookId string) (Webhook, error)
+	GetWebhooksForEvent(event string) ([]Webhook, error)
+	UpdateWebhook(webhookId string, updateData Webhook) (Webhook, error)
+	DeleteWebhook(webhookId string) error
+
+	// CreateDelivery stores a delivery unless one with the same id exists.
+	CreateDelivery(d WebhookDelivery) (WebhookDelivery, error)
+	GetDeliveries(webhookId string, opts PaginationInfo) ([]WebhookDelivery, error)
+	GetDeliveryById(webhookId, deliveryId string) (WebhookDelivery, error)
+	// ClaimDueDeliveries locks up to limit pending deliveries whose next
+	// attempt is due so that no other worker sends them meanwhile.
+	ClaimDueDeliveries(now time.Time, lockFor time.Duration, limit int) ([]WebhookDelivery, error)
+	// RecordAttempt appends an attempt, sets the status and next attempt
+	// time, and releases the lock.
+	RecordAttempt(deliveryId string, attempt WebhookAttempt, status string, nextAttempt time.Time) error
+}
@@ -0,0 +1,32 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+type GeminiEmbedder struct {
+	model *genai.EmbeddingModel
+}
+
+func NewGeminiEmbedder(apiKey, modelName string) (*GeminiEmbedder, error) {
+	client, err := genai.NewClient(context.Background(), option.WithAPIKey(apiKey))
+	if err != nil {
+		return &GeminiEmbedder{}, err
+	}
+	return &GeminiEmbedder{model: client.EmbeddingModel(modelName)}, nil
+}
+
+func (g *GeminiEmbedder) Embed(text string) ([]float32, error) {
+	resp, err := g.model.EmbedContent(context.Background(), genai.Text(text))
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil || resp.Embedding == nil {
+		return nil, fmt.Errorf("no embedding returned from the model")
+	}
+	return resp.Embedding.Values, nil
+}
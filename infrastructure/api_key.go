@@ -0,0 +1,81 @@
+package infrastructure
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/yesetoda/BlogMate/domain"
+)
+
+// APIKeyPrefix starts every API key, so they are told apart from access
+// tokens and found by secret scanners.
+const APIKeyPrefix = "bm_"
+
+// NewAPIKey returns a new key, its public id and the hash of its secret.
+// Keys look like bm_<id>_<secret>.
+func NewAPIKey() (string, string, string, error) {
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+	keyId := hex.EncodeToString(id)
+	encoded := base64.RawURLEncoding.EncodeToString(secret)
+	return APIKeyPrefix + keyId + "_" + encoded, keyId, HashRefreshToken(encoded), nil
+}
+
+// ParseAPIKey splits a key into its id and the hash of its secret.
+func ParseAPIKey(key string) (string, string, bool) {
+	if !IsAPIKey(key) {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(key, APIKeyPrefix), "_", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], HashRefreshToken(parts[1]), true
+}
+
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// RequiredScope is the scope an API key needs for a request to the route,
+// e.g. /blogs/:blogId. Reads need none, and writes outside of blogs and
+// comments, like managing the account or its keys, are not open to keys.
+func RequiredScope(method, route string) (string, bool) {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return "", true
+	}
+	switch {
+	case strings.HasPrefix(route, "/blogs/:blogId/comments"):
+		return domain.ScopeCommentsWrite, true
+	case route == "/blogs" || strings.HasPrefix(route, "/blogs/"):
+		return domain.ScopeBlogsWrite, true
+	}
+	return "", false
+}
+
+// apiKeyAllows tells whether a key with the scopes may make the request.
+func apiKeyAllows(scopes []string, method, route string) bool {
+	scope, ok := RequiredScope(method, route)
+	if !ok {
+		return false
+	}
+	if scope == "" {
+		return len(scopes) > 0
+	}
+	for _, granted := range scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package infrastructure
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yesetoda/BlogMate/domain"
+)
+
+func TestAPIKeyFormat(t *testing.T) {
+	key, keyId, secretHash, err := NewAPIKey()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, APIKeyPrefix+keyId+"_"))
+	assert.NotContains(t, key, secretHash)
+
+	parsedId, parsedHash, ok := ParseAPIKey(key)
+	assert.True(t, ok)
+	assert.Equal(t, keyId, parsedId)
+	assert.Equal(t, secretHash, parsedHash)
+
+	for _, invalid := range []string{"", "bm_", "bm_abc", "bm__secret", "eyJhbGciOi.x.y"} {
+		_, _, ok := ParseAPIKey(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+// fakeAPIKeys accepts the keys in the map, with their scopes.
+type fakeAPIKeys map[string][]string
+
+func (keys fakeAPIKeys) AuthenticateAPIKey(key string, ip string) (*domain.Claims, error) {
+	scopes, ok := keys[key]
+	if !ok {
+		return nil, errors.New("invalid API key")
+	}
+	return &domain.Claims{ID: "u1", IsActive: true, Scopes: scopes}, nil
+}
+
+func TestAuthenticationMiddlewareAPIKeyScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ac := NewAuthController(nil, WithAPIKeys(fakeAPIKeys{
+		"bm_ro_secret":      {domain.ScopeRead},
+		"bm_blogs_secret":   {domain.ScopeBlogsWrite},
+		"bm_comment_secret": {domain.ScopeCommentsWrite},
+	}))
+	router := gin.New()
+	authenticated := router.Group("/", ac.AuthenticationMiddleware())
+	handler := func(c *gin.Context) {
+		claims, err := GetClaims(c)
+		assert.NoError(t, err)
+		c.String(http.StatusOK, claims.ID)
+	}
+	authenticated.GET("/blogs/:blogId", handler)
+	authenticated.POST("/blogs/", handler)
+	authenticated.POST("/blogs/:blogId/comments/", handler)
+	authenticated.POST("/me/api-keys", handler)
+
+	cases := []struct {
+		method, path, key string
+		want              int
+	}{
+		{http.MethodGet, "/blogs/b1", "bm_ro_secret", http.StatusOK},
+		{http.MethodPost, "/blogs/", "bm_ro_secret", http.StatusForbidden},
+		{http.MethodPost, "/blogs/", "bm_blogs_secret", http.StatusOK},
+		{http.MethodPost, "/blogs/b1/comments/", "bm_blogs_secret", http.StatusForbidden},
+		{http.MethodPost, "/blogs/b1/comments/", "bm_comment_secret", http.StatusOK},
+		{http.MethodPost, "/me/api-keys", "bm_blogs_secret", http.StatusForbidden},
+		{http.MethodGet, "/blogs/b1", "bm_unknown_secret", http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		for _, header := range []string{"X-API-Key", "Authorization"} {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if header == "Authorization" {
+				req.Header.Set(header, "Bearer "+tc.key)
+			} else {
+				req.Header.Set(header, tc.key)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, tc.want, recorder.Code, "%s %s with %s in %s", tc.method, tc.path, tc.key, header)
+		}
+	}
+}
@@ -9,4 +9,6 @@ type GeneralAuthorizationController interface {
 	ADMINMiddleware() gin.HandlerFunc
 	USERMiddleware() gin.HandlerFunc
 	OWNERMiddleware() gin.HandlerFunc
+	RequirePermission(permission string) gin.HandlerFunc
+	RequireOwnerOrPermission(permission string) gin.HandlerFunc
 }
@@ -12,13 +12,55 @@ import (
 )
 
 type AuthController struct {
-	blogRepo domain.BlogRepository
+	blogRepo       domain.BlogRepository
+	revocation     *RevocationList
+	adminTwoFactor bool
+	apiKeys        domain.APIKeyAuthenticator
+	permissions    domain.PermissionChecker
 }
 
-func NewAuthController(blogRepo domain.BlogRepository) GeneralAuthorizationController {
-	return &AuthController{
+// AuthOption sets an optional dependency of the auth controller.
+type AuthOption func(*AuthController)
+
+// WithRevocationList rejects the access tokens revoked in the list.
+func WithRevocationList(list *RevocationList) AuthOption {
+	return func(ac *AuthController) {
+		ac.revocation = list
+	}
+}
+
+// WithAdminTwoFactor only lets admins through ADMINMiddleware when they
+// logged in with a second factor.
+func WithAdminTwoFactor() AuthOption {
+	return func(ac *AuthController) {
+		ac.adminTwoFactor = true
+	}
+}
+
+// WithAPIKeys accepts API keys, in the X-API-Key header or as bearer
+// tokens, as an alternative to access tokens.
+func WithAPIKeys(apiKeys domain.APIKeyAuthenticator) AuthOption {
+	return func(ac *AuthController) {
+		ac.apiKeys = apiKeys
+	}
+}
+
+// WithPermissions resolves custom roles too, without it only the built-in
+// roles grant permissions.
+func WithPermissions(permissions domain.PermissionChecker) AuthOption {
+	return func(ac *AuthController) {
+		ac.permissions = permissions
+	}
+}
+
+func NewAuthController(blogRepo domain.BlogRepository, opts ...AuthOption) GeneralAuthorizationController {
+	ac := &AuthController{
 		blogRepo: blogRepo,
 	}
+	for _, opt := range opts {
+		opt(ac)
+	}
+	return ac
 }
 
 func (ac *AuthController) AuthenticationMiddleware() gin.HandlerFunc {
@@ -29,17 +71,147 @@ func (ac *AuthController) AuthenticationMiddleware() gin.HandlerFunc {
 				c.Abort()
 			}
 		}()
+		if key := apiKeyFromRequest(c); key != "" && ac.apiKeys != nil {
+			ac.authenticateAPIKey(c, key)
+			return
+		}
 		claims, err := GetClaims(c)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			c.Abort()
 			return
 		}
+		if ac.revocation != nil {
+			revoked, err := ac.revocation.IsRevoked(claims)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				c.Abort()
+				return
+			}
+			if revoked {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "token has been revoked"})
+				c.Abort()
+				return
+			}
+		}
+		if err := ac.setPermissions(c, claims); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
 		c.Set("claims", claims)
 		c.Next()
 	}
 }
 
+// authenticateAPIKey lets the request through as the user of the key when
+// its scopes allow it.
+func (ac *AuthController) authenticateAPIKey(c *gin.Context, key string) {
+	claims, err := ac.apiKeys.AuthenticateAPIKey(key, c.ClientIP())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if !apiKeyAllows(claims.Scopes, c.Request.Method, c.FullPath()) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "the scopes of the API key do not allow this request"})
+		return
+	}
+	if err := ac.setPermissions(c, claims); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Set("claims", claims)
+	c.Next()
+}
+
+func apiKeyFromRequest(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+	if token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "); IsAPIKey(token) {
+		return token
+	}
+	return ""
+}
+
+// setPermissions resolves the permissions of the roles in the claims for
+// RequirePermission and HasPermission. Tokens issued before roles existed
+// get the roles their admin flag implies.
+func (ac *AuthController) setPermissions(c *gin.Context, claims *domain.Claims) error {
+	roles := claims.Roles
+	if len(roles) == 0 {
+		roles = domain.User{IsAdmin: claims.IsAdmin}.EffectiveRoles()
+	}
+	var permissions []string
+	if ac.permissions != nil {
+		var err error
+		permissions, err = ac.permissions.Permissions(roles)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, name := range roles {
+			role, _ := domain.BuiltInRole(name)
+			permissions = append(permissions, role.Permissions...)
+		}
+	}
+	c.Set("permissions", permissions)
+	return nil
+}
+
+// HasPermission tells whether the roles of the authenticated user grant the
+// permission.
+func HasPermission(c *gin.Context, permission string) bool {
+	permissions, _ := c.Get("permissions")
+	granted, _ := permissions.([]string)
+	for _, candidate := range granted {
+		if candidate == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RequirePermission only lets users whose roles grant the permission through.
+// Admins using a permission beyond the default role must have logged in with
+// a second factor when that is required.
+func (ac *AuthController) RequirePermission(permission string) gin.HandlerFunc {
+	defaultRole, _ := domain.BuiltInRole(domain.DefaultRole)
+	elevated := true
+	for _, candidate := range defaultRole.Permissions {
+		elevated = elevated && candidate != permission
+	}
+	return func(c *gin.Context) {
+		claims, err := GetClaims(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !HasPermission(c, permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "missing permission " + permission})
+			return
+		}
+		if elevated && claims.IsAdmin && ac.adminTwoFactor && !claims.TwoFactor {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admins must log in with two-factor authentication, enroll at /me/2fa and log in again"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireOwnerOrPermission lets the author of the blog through, and anyone
+// whose roles grant the permission.
+func (ac *AuthController) RequireOwnerOrPermission(permission string) gin.HandlerFunc {
+	owner := ac.OWNERMiddleware()
+	return func(c *gin.Context) {
+		if HasPermission(c, permission) {
+			c.Next()
+			return
+		}
+		owner(c)
+	}
+}
+
 func (ac *AuthController) ADMINMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
@@ -54,6 +226,10 @@ func (ac *AuthController) ADMINMiddleware() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+		if claims.IsAdmin && ac.adminTwoFactor && !claims.TwoFactor {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admins must log in with two-factor authentication, enroll at /me/2fa and log in again"})
+			return
+		}
 		if claims.IsAdmin {
 			c.Next()
 			return
@@ -116,12 +292,14 @@ func (ac *AuthController) OWNERMiddleware() gin.HandlerFunc {
 		c.AbortWithStatusJSON(http.StatusForbidden, errors.New("unauthorized,neither an admin nor an author"))
 	}
 }
+// GetClaims returns the claims AuthenticationMiddleware set, or else those
+// of the access token of the request.
 func GetClaims(c *gin.Context) (*domain.Claims, error) {
-	configJwt, err := config.LoadConfig()
-	if err != nil {
-		return &domain.Claims{}, err
+	if claims, ok := c.Get("claims"); ok {
+		if claims, ok := claims.(*domain.Claims); ok {
+			return claims, nil
+		}
 	}
-	var jwtSecret = []byte(configJwt.JWT)
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
 		return &domain.Claims{}, errors.New("missing authorization header")
@@ -133,15 +311,45 @@ func GetClaims(c *gin.Context) (*domain.Claims, error) {
 	}
 	tokenString := TokenString[1]
 
-	token, err := jwt.ParseWithClaims(tokenString, &domain.Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+	return ParseAccessToken(tokenString)
+}
 
+// ParseAccessToken verifies an access token with the default keyring, or
+// with the JWT secret when none is set, and returns its claims.
+func ParseAccessToken(tokenString string) (*domain.Claims, error) {
+	keyfunc := func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		configJwt, err := config.LoadConfig()
+		if err != nil {
+			return nil, err
+		}
+		return []byte(configJwt.JWT), nil
+	}
+	if keyring := DefaultKeyring(); keyring != nil {
+		keyfunc = keyring.Keyfunc
+	}
+	token, err := jwt.ParseWithClaims(tokenString, &domain.Claims{}, keyfunc)
 	if err != nil {
 		return &domain.Claims{}, err
 	}
 	if claims, ok := token.Claims.(*domain.Claims); ok && token.Valid {
-		return claims, err
+		return claims, nil
 	}
 	return &domain.Claims{}, errors.New("invalid token")
 }
+
+// QueryTokenMiddleware lets clients that cannot set headers, like browser
+// EventSource and WebSocket connections, pass the access token as the
+// access_token query parameter.
+func QueryTokenMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query("access_token"); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		c.Next()
+	}
+}
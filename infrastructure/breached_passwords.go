@@ -0,0 +1,107 @@
+package infrastructure
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// breachedPasswords are the SHA-1 hashes of the most common leaked
+// passwords, one per line.
+//
+//go:embed breached/passwords.txt
+var breachedPasswords string
+
+// BreachedPasswords tells whether a password appeared in a data breach. It
+// only ever looks passwords up by the first 5 characters of their SHA-1
+// hash, like the Pwned Passwords range API, so large lists can stay on disk
+// as one range file per prefix.
+type BreachedPasswords struct {
+	// ranges maps hash prefixes to the suffixes of the loaded hashes
+	ranges map[string]map[string]bool
+	dir    string
+}
+
+// NewBreachedPasswords loads the bundled list and the one at path, if any.
+// The path is either a file of hashes or a directory of range files named
+// by prefix, e.g. 5BAA6 or 5BAA6.txt, with lines of suffixes. Lines may end
+// with ":count".
+func NewBreachedPasswords(path string) (*BreachedPasswords, error) {
+	b := &BreachedPasswords{ranges: map[string]map[string]bool{}}
+	if err := b.load(strings.NewReader(breachedPasswords)); err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return b, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		b.dir = path
+		return b, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return b, b.load(file)
+}
+
+func (b *BreachedPasswords) load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		hash := breachedLineHash(scanner.Text())
+		if len(hash) != sha1.Size*2 {
+			continue
+		}
+		if b.ranges[hash[:5]] == nil {
+			b.ranges[hash[:5]] = map[string]bool{}
+		}
+		b.ranges[hash[:5]][hash[5:]] = true
+	}
+	return scanner.Err()
+}
+
+func breachedLineHash(line string) string {
+	hash, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+	return strings.ToUpper(hash)
+}
+
+func (b *BreachedPasswords) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+	if b.ranges[prefix][suffix] {
+		return true, nil
+	}
+	if b.dir == "" {
+		return false, nil
+	}
+	for _, name := range []string{prefix, prefix + ".txt"} {
+		file, err := os.Open(filepath.Join(b.dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if breachedLineHash(scanner.Text()) == suffix {
+				return true, nil
+			}
+		}
+		return false, scanner.Err()
+	}
+	return false, nil
+}
@@ -0,0 +1,33 @@
+package infrastructure
+
+import "time"
+
+// Event is a change pushed to the clients subscribed to its topic.
+type Event struct {
+	Topic string      `json:"topic"`
+	Type  string      `json:"type"`
+	Data  interface{} `json:"data"`
+	Date  time.Time   `json:"date"`
+}
+
+// Broker fans events out to the subscribers of a topic. Subscribers that do
+// not keep up may miss events, they are expected to re-fetch.
+type Broker interface {
+	Publish(event Event)
+	// Subscribe returns a channel receiving the events of the given topics
+	// and a function that ends the subscription and closes the channel.
+	Subscribe(topics ...string) (<-chan Event, func())
+}
+
+func BlogTopic(blogId string) string {
+	return "blog:" + blogId
+}
+
+// ThreadTopic is the topic of the replies below a comment, at any depth.
+func ThreadTopic(commentId string) string {
+	return "thread:" + commentId
+}
+
+func UserTopic(userId string) string {
+	return "user:" + userId
+}
@@ -0,0 +1,146 @@
+package infrastructure
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Email templates. Each has an HTML and a plain-text version under
+// templates/email and a subject per locale.
+const (
+	VerificationEmail  = "verification"
+	PasswordResetEmail = "password_reset"
+	NotificationEmail  = "notification"
+	DigestEmail        = "digest"
+	// NewsletterConfirmationEmail renders Title as the end of "get an email
+	// when ...", e.g. "Abel publishes a post".
+	NewsletterConfirmationEmail = "newsletter_confirmation"
+	NewsletterPostEmail         = "newsletter_post"
+	// MagicLinkEmail renders Body as where the login was asked from.
+	MagicLinkEmail = "magic_link"
+	// AccountLockedEmail renders Body as until when the account is locked.
+	AccountLockedEmail = "account_locked"
+	// EmailChangeEmail asks the new address to confirm a change of email,
+	// EmailChangedEmail tells the old one and links to the revert.
+	EmailChangeEmail  = "email_change"
+	EmailChangedEmail = "email_changed"
+)
+
+const DefaultLocale = "en"
+
+//go:embed templates/email
+var emailTemplateFiles embed.FS
+
+var (
+	htmlEmailTemplates = htmltemplate.Must(htmltemplate.ParseFS(emailTemplateFiles, "templates/email/*.html"))
+	textEmailTemplates = texttemplate.Must(texttemplate.ParseFS(emailTemplateFiles, "templates/email/*.txt"))
+)
+
+var emailSubjects = map[string]map[string]string{
+	VerificationEmail: {
+		"en": "Confirm your BlogMate account",
+		"fr": "Confirmez votre compte BlogMate",
+		"am": "የBlogMate መለያዎን ያረጋግጡ",
+	},
+	PasswordResetEmail: {
+		"en": "Reset your BlogMate password",
+		"fr": "Réinitialisez votre mot de passe BlogMate",
+		"am": "የBlogMate የይለፍ ቃልዎን ይቀይሩ",
+	},
+	NotificationEmail: {
+		"en": "New activity on BlogMate",
+		"fr": "Nouvelle activité sur BlogMate",
+		"am": "በBlogMate ላይ አዲስ እንቅስቃሴ",
+	},
+	DigestEmail: {
+		"en": "Your BlogMate digest",
+		"fr": "Votre résumé BlogMate",
+		"am": "የBlogMate ማጠቃለያዎ",
+	},
+	NewsletterConfirmationEmail: {
+		"en": "Confirm your BlogMate subscription",
+		"fr": "Confirmez votre abonnement BlogMate",
+		"am": "የBlogMate ምዝገባዎን ያረጋግጡ",
+	},
+	NewsletterPostEmail: {
+		"en": "New post on BlogMate",
+		"fr": "Nouvel article sur BlogMate",
+		"am": "በBlogMate ላይ አዲስ ጽሁፍ",
+	},
+	MagicLinkEmail: {
+		"en": "Your BlogMate login link",
+		"fr": "Votre lien de connexion BlogMate",
+		"am": "የBlogMate መግቢያ ሊንክዎ",
+	},
+	AccountLockedEmail: {
+		"en": "Your BlogMate account was locked",
+		"fr": "Votre compte BlogMate a été verrouillé",
+		"am": "የBlogMate መለያዎ ተቆልፏል",
+	},
+	EmailChangeEmail: {
+		"en": "Confirm your new BlogMate email",
+		"fr": "Confirmez votre nouvel e-mail BlogMate",
+		"am": "አዲሱን የBlogMate ኢሜይልዎን ያረጋግጡ",
+	},
+	EmailChangedEmail: {
+		"en": "The email of your BlogMate account was changed",
+		"fr": "L'e-mail de votre compte BlogMate a été modifié",
+		"am": "የBlogMate መለያዎ ኢሜይል ተቀይሯል",
+	},
+}
+
+// EmailData is what the email templates render. Values are escaped in the
+// HTML version.
+type EmailData struct {
+	Name  string
+	Title string
+	Body  string
+	Link  string
+	// Items are listed by the digest template.
+	Items           []EmailItem
+	UnsubscribeLink string
+}
+
+type EmailItem struct {
+	Title   string
+	Summary string
+	Link    string
+}
+
+// RenderEmail renders the named template, with the subject in the given
+// locale, e.g. "fr" or "fr-CA", falling back to English. The recipients are
+// left to the caller.
+func RenderEmail(name, locale string, data EmailData) (Email, error) {
+	subjects, ok := emailSubjects[name]
+	if !ok {
+		return Email{}, errors.New("unknown email template " + name)
+	}
+	view := struct {
+		EmailData
+		Subject string
+	}{EmailData: data, Subject: emailSubject(subjects, locale)}
+
+	var html, text bytes.Buffer
+	if err := htmlEmailTemplates.ExecuteTemplate(&html, name+".html", view); err != nil {
+		return Email{}, err
+	}
+	if err := textEmailTemplates.ExecuteTemplate(&text, name+".txt", view); err != nil {
+		return Email{}, err
+	}
+	return Email{Subject: view.Subject, HTML: html.String(), Text: text.String()}, nil
+}
+
+func emailSubject(subjects map[string]string, locale string) string {
+	locale = strings.ToLower(locale)
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+	if subject, ok := subjects[locale]; ok {
+		return subject
+	}
+	return subjects[DefaultLocale]
+}
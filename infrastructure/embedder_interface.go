@@ -0,0 +1,20 @@
+package infrastructure
+
+// Embedder turns text into a dense vector so that similar texts end up close
+// to each other.
+type Embedder interface {
+	Embed(text string) ([]float32, error)
+}
+
+type VectorMatch struct {
+	ID    string  `json:"id"`
+	Score float64 `json:"score"`
+}
+
+// VectorStore keeps embeddings by id and answers nearest neighbour queries.
+type VectorStore interface {
+	Upsert(id string, vector []float32) error
+	Delete(id string) error
+	Get(id string) ([]float32, bool)
+	Search(vector []float32, k int, exclude ...string) ([]VectorMatch, error)
+}
@@ -0,0 +1,39 @@
+package infrastructure
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer writes every email as a file in a maildir instead of sending it,
+// so that local development can read them with a mail client or an editor.
+// Messages are written to tmp and then moved to new, as maildir readers expect.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (m *FileMailer) Send(email Email) error {
+	now := time.Now()
+	msg, err := buildMessage(m.from, email, now)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d.%s.blogmate.eml", now.UnixNano(), randomHex(6))
+	tmp := filepath.Join(m.dir, "tmp", name)
+	if err := os.WriteFile(tmp, msg, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(m.dir, "new", name))
+}
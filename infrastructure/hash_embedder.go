@@ -0,0 +1,55 @@
+package infrastructure
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+)
+
+// HashEmbedder is a deterministic, offline Embedder. Every token is hashed into
+// one of a fixed number of buckets, which makes it suitable for tests and for
+// running without an AI provider.
+type HashEmbedder struct {
+	dimensions int
+}
+
+func NewHashEmbedder(dimensions int) *HashEmbedder {
+	if dimensions <= 0 {
+		dimensions = 256
+	}
+	return &HashEmbedder{dimensions: dimensions}
+}
+
+func (h *HashEmbedder) Embed(text string) ([]float32, error) {
+	tokens := Tokenize(text)
+	if len(tokens) == 0 {
+		return nil, errors.New("nothing to embed")
+	}
+	vector := make([]float32, h.dimensions)
+	for _, t := range tokens {
+		hasher := fnv.New64a()
+		hasher.Write([]byte(t))
+		sum := hasher.Sum64()
+		sign := float32(1)
+		if sum&(1<<63) != 0 {
+			sign = -1
+		}
+		vector[sum%uint64(h.dimensions)] += sign
+	}
+	normalize(vector)
+	return vector, nil
+}
+
+func normalize(vector []float32) {
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] = float32(float64(vector[i]) / norm)
+	}
+}
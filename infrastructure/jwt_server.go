@@ -1,53 +1,73 @@
 package infrastructure
 
 import (
-	"errors"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"time"
 
 	"github.com/yesetoda/BlogMate/config"
 	"github.com/yesetoda/BlogMate/domain"
 
 	"github.com/golang-jwt/jwt"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
-func GenerateToken(user *domain.User, pwd string) (string, string, error) {
-	configjwt, err := config.LoadConfig()
-	if err != nil {
-		return "", "", err
-	}
-	jwtSecret := []byte(configjwt.JWT)
+// AccessTokenTTL is how long an access token is valid.
+const AccessTokenTTL = 10 * time.Minute
 
-	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(pwd)) != nil {
-		return "", "", errors.New("invalid username or password")
-	}
+// GenerateAccessToken signs an access token for user, issued for the session
+// when sessionId is set. twoFactor tells that the login passed a second
+// factor.
+func GenerateAccessToken(user *domain.User, sessionId string, twoFactor bool) (string, error) {
+	return signClaims(userClaims(user, sessionId, twoFactor, AccessTokenTTL))
+}
 
-	expirationTime := time.Now().Add(10 * time.Minute)
-	claims := &domain.Claims{
-		ID:       user.ID,
-		Email:    user.Email,
-		IsAdmin:  user.IsAdmin,
-		IsActive: user.IsActive,
+func userClaims(user *domain.User, sessionId string, twoFactor bool, ttl time.Duration) *domain.Claims {
+	now := time.Now()
+	return &domain.Claims{
+		ID:           user.ID,
+		Email:        user.Email,
+		IsAdmin:      user.IsAdmin,
+		IsActive:     user.IsActive,
+		SessionId:    sessionId,
+		TokenVersion: user.TokenVersion,
+		TwoFactor:    twoFactor,
+		Roles:        user.EffectiveRoles(),
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expirationTime.Unix(),
-			IssuedAt:  time.Now().Unix(),
+			Id:        randomHex(16),
+			ExpiresAt: now.Add(ttl).Unix(),
+			IssuedAt:  now.Unix(),
 		},
 	}
+}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	accessTokenString, err := accessToken.SignedString(jwtSecret)
+// signClaims signs with the default keyring, or with the JWT secret when
+// none is set.
+func signClaims(claims *domain.Claims) (string, error) {
+	if keyring := DefaultKeyring(); keyring != nil {
+		return keyring.Sign(claims)
+	}
+	configjwt, err := config.LoadConfig()
 	if err != nil {
-		return "", "", err
+		return "", err
 	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(configjwt.JWT))
+}
 
-	expirationTime = time.Now().Add(1 * time.Hour)
-	claims.ExpiresAt = expirationTime.Unix()
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	refreshTokenString, err := refreshToken.SignedString(jwtSecret)
-	if err != nil {
+// NewRefreshToken returns a random opaque refresh token and the hash to
+// store instead of it.
+func NewRefreshToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
 		return "", "", err
 	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, HashRefreshToken(token), nil
+}
 
-	return accessTokenString, refreshTokenString, nil
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,326 @@
+package infrastructure
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/yesetoda/BlogMate/domain"
+)
+
+// Algorithms the keyring signs tokens with. HS256 turns the keyring off and
+// signs with the shared JWT secret instead.
+const (
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+	HS256 = "HS256"
+)
+
+const (
+	// DefaultKeyRotation is how long a key signs tokens before the next one
+	// replaces it.
+	DefaultKeyRotation = 30 * 24 * time.Hour
+	// keyGracePeriod is how long a retired key still verifies tokens. It must
+	// be longer than the tokens it signed live.
+	keyGracePeriod = 24 * time.Hour
+	// keyReloadInterval limits how often a token signed with an unknown key,
+	// e.g. one created by another instance, reloads the keys.
+	keyReloadInterval = time.Minute
+	rsaKeyBits        = 2048
+)
+
+var (
+	keyringMu      sync.RWMutex
+	defaultKeyring *Keyring
+)
+
+// SetDefaultKeyring makes access tokens signed and verified with the keyring
+// instead of the JWT secret.
+func SetDefaultKeyring(keyring *Keyring) {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+	defaultKeyring = keyring
+}
+
+// DefaultKeyring returns the keyring set with SetDefaultKeyring, if any.
+func DefaultKeyring() *Keyring {
+	keyringMu.RLock()
+	defer keyringMu.RUnlock()
+	return defaultKeyring
+}
+
+type ringKey struct {
+	domain.SigningKey
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// Keyring signs access tokens with its newest key and verifies them with
+// any key that did not expire, found by the kid header of the token. Keys
+// are stored so that every instance signs and verifies with the same ones.
+type Keyring struct {
+	mu         sync.RWMutex
+	store      domain.SigningKeyRepository
+	algorithm  string
+	rotation   time.Duration
+	keys       map[string]*ringKey
+	signing    *ringKey
+	reloadMu   sync.Mutex
+	lastReload time.Time
+	now        func() time.Time
+}
+
+func NewKeyring(store domain.SigningKeyRepository, algorithm string, rotation time.Duration) (*Keyring, error) {
+	if algorithm == "" {
+		algorithm = RS256
+	}
+	if algorithm != RS256 && algorithm != EdDSA {
+		return nil, fmt.Errorf("unsupported signing algorithm %q, use %s or %s", algorithm, RS256, EdDSA)
+	}
+	if rotation <= 0 {
+		rotation = DefaultKeyRotation
+	}
+	return &Keyring{
+		store:     store,
+		algorithm: algorithm,
+		rotation:  rotation,
+		keys:      map[string]*ringKey{},
+		now:       time.Now,
+	}, nil
+}
+
+// Load reads the keys from the store and rotates when none of them may sign
+// anymore.
+func (k *Keyring) Load() error {
+	now := k.now()
+	stored, err := k.store.GetSigningKeys(now)
+	if err != nil {
+		return err
+	}
+	keys := map[string]*ringKey{}
+	var signing *ringKey
+	for _, key := range stored {
+		parsed, err := parseSigningKey(key)
+		if err != nil {
+			log.Println("skipping signing key", key.KeyId+":", err)
+			continue
+		}
+		keys[key.KeyId] = parsed
+		if key.Algorithm == k.algorithm && key.RetiresAt.After(now) && (signing == nil || key.CreatedAt.After(signing.CreatedAt)) {
+			signing = parsed
+		}
+	}
+	k.mu.Lock()
+	k.keys = keys
+	k.signing = signing
+	k.mu.Unlock()
+	if signing == nil {
+		return k.Rotate()
+	}
+	return nil
+}
+
+// Rotate creates a new key that signs the tokens from now on. The previous
+// keys keep verifying the tokens they signed until they expire.
+func (k *Keyring) Rotate() error {
+	now := k.now()
+	key, err := generateSigningKey(k.algorithm)
+	if err != nil {
+		return err
+	}
+	key.KeyId = randomHex(8)
+	key.CreatedAt = now
+	key.RetiresAt = now.Add(k.rotation)
+	key.ExpiresAt = key.RetiresAt.Add(keyGracePeriod)
+	parsed, err := parseSigningKey(key)
+	if err != nil {
+		return err
+	}
+	if err := k.store.SaveSigningKey(key); err != nil {
+		return err
+	}
+	k.mu.Lock()
+	k.keys[key.KeyId] = parsed
+	k.signing = parsed
+	k.mu.Unlock()
+	log.Println("rotated the token signing key, new kid", key.KeyId)
+	return nil
+}
+
+// Sign signs the claims with the current key and names it in the kid header.
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	k.mu.RLock()
+	signing := k.signing
+	k.mu.RUnlock()
+	if signing == nil {
+		return "", errors.New("no signing key, load the keyring first")
+	}
+	token := jwt.NewWithClaims(signing.method, claims)
+	token.Header["kid"] = signing.KeyId
+	return token.SignedString(signing.private)
+}
+
+// Keyfunc returns the public key that verifies the token, for jwt.Parse.
+func (k *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no key id")
+	}
+	key := k.key(kid)
+	if key == nil {
+		k.reload()
+		key = k.key(kid)
+	}
+	if key == nil || !key.ExpiresAt.After(k.now()) {
+		return nil, errors.New("unknown or expired signing key")
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.public, nil
+}
+
+func (k *Keyring) key(kid string) *ringKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys[kid]
+}
+
+// reload loads the keys again, at most once per keyReloadInterval.
+func (k *Keyring) reload() {
+	k.reloadMu.Lock()
+	defer k.reloadMu.Unlock()
+	if k.now().Sub(k.lastReload) < keyReloadInterval {
+		return
+	}
+	k.lastReload = k.now()
+	if err := k.Load(); err != nil {
+		log.Println("reloading signing keys failed:", err)
+	}
+}
+
+// JWK is a public key in the JSON Web Key format.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyId     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 keys
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys that verify tokens, newest first.
+func (k *Keyring) JWKS() JWKSet {
+	now := k.now()
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	keys := []*ringKey{}
+	for _, key := range k.keys {
+		if key.ExpiresAt.After(now) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range keys {
+		jwk := JWK{KeyId: key.KeyId, Use: "sig", Algorithm: key.Algorithm}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// Run reloads the keys every interval until stop is closed, picking up the
+// keys of the other instances and rotating when the current key retires.
+func (k *Keyring) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		if err := k.Load(); err != nil {
+			log.Println("loading signing keys failed:", err)
+		}
+	}
+}
+
+func generateSigningKey(algorithm string) (domain.SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch algorithm {
+	case RS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case EdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		err = fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return domain.SigningKey{}, err
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return domain.SigningKey{}, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return domain.SigningKey{}, err
+	}
+	return domain.SigningKey{Algorithm: algorithm, PrivateKey: privateDER, PublicKey: publicDER}, nil
+}
+
+func parseSigningKey(key domain.SigningKey) (*ringKey, error) {
+	parsed := &ringKey{SigningKey: key}
+	switch key.Algorithm {
+	case RS256:
+		parsed.method = jwt.SigningMethodRS256
+	case EdDSA:
+		parsed.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", key.Algorithm)
+	}
+	private, err := x509.ParsePKCS8PrivateKey(key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key cannot sign")
+	}
+	parsed.private = signer
+	parsed.public = signer.Public()
+	return parsed, nil
+}
@@ -0,0 +1,103 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/yesetoda/BlogMate/domain"
+)
+
+type fakeKeyStore struct {
+	keys []domain.SigningKey
+}
+
+func (s *fakeKeyStore) GetSigningKeys(now time.Time) ([]domain.SigningKey, error) {
+	keys := []domain.SigningKey{}
+	for _, key := range s.keys {
+		if key.ExpiresAt.After(now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (s *fakeKeyStore) SaveSigningKey(key domain.SigningKey) error {
+	s.keys = append(s.keys, key)
+	return nil
+}
+
+func newTestKeyring(t *testing.T, store *fakeKeyStore, algorithm string, now *time.Time) *Keyring {
+	keyring, err := NewKeyring(store, algorithm, time.Hour)
+	assert.NoError(t, err)
+	keyring.now = func() time.Time { return *now }
+	assert.NoError(t, keyring.Load())
+	return keyring
+}
+
+func TestKeyringSignAndVerify(t *testing.T) {
+	for _, algorithm := range []string{RS256, EdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			now := time.Now()
+			keyring := newTestKeyring(t, &fakeKeyStore{}, algorithm, &now)
+			claims := &domain.Claims{ID: "u1", StandardClaims: jwt.StandardClaims{ExpiresAt: now.Add(time.Minute).Unix()}}
+			signed, err := keyring.Sign(claims)
+			assert.NoError(t, err)
+
+			token, err := jwt.ParseWithClaims(signed, &domain.Claims{}, keyring.Keyfunc)
+			assert.NoError(t, err)
+			assert.Equal(t, algorithm, token.Header["alg"])
+			assert.Equal(t, "u1", token.Claims.(*domain.Claims).ID)
+
+			// a token signed with the secret is not accepted
+			hmac, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+			_, err = jwt.ParseWithClaims(hmac, &domain.Claims{}, keyring.Keyfunc)
+			assert.Error(t, err)
+		})
+	}
+	_, err := NewKeyring(&fakeKeyStore{}, HS256, 0)
+	assert.Error(t, err)
+}
+
+func TestKeyringRotation(t *testing.T) {
+	now := time.Now()
+	store := &fakeKeyStore{}
+	keyring := newTestKeyring(t, store, RS256, &now)
+	claims := &domain.Claims{ID: "u1", StandardClaims: jwt.StandardClaims{ExpiresAt: now.Add(48 * time.Hour).Unix()}}
+	old, err := keyring.Sign(claims)
+	assert.NoError(t, err)
+
+	// the first key retires after an hour and the next load rotates
+	now = now.Add(time.Hour + time.Minute)
+	assert.NoError(t, keyring.Load())
+	assert.Len(t, store.keys, 2)
+	current, err := keyring.Sign(claims)
+	assert.NoError(t, err)
+	oldToken, err := jwt.ParseWithClaims(old, &domain.Claims{}, keyring.Keyfunc)
+	assert.NoError(t, err)
+	currentToken, err := jwt.ParseWithClaims(current, &domain.Claims{}, keyring.Keyfunc)
+	assert.NoError(t, err)
+	assert.NotEqual(t, oldToken.Header["kid"], currentToken.Header["kid"])
+
+	jwks := keyring.JWKS()
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, currentToken.Header["kid"], jwks.Keys[0].KeyId)
+	assert.Equal(t, "RSA", jwks.Keys[0].KeyType)
+	assert.NotEmpty(t, jwks.Keys[0].N)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+
+	// another instance verifies with the stored keys
+	other := newTestKeyring(t, store, RS256, &now)
+	_, err = jwt.ParseWithClaims(current, &domain.Claims{}, other.Keyfunc)
+	assert.NoError(t, err)
+
+	// once the grace period is over the old key is gone
+	now = now.Add(keyGracePeriod)
+	assert.NoError(t, keyring.Load())
+	_, err = jwt.ParseWithClaims(old, &domain.Claims{}, keyring.Keyfunc)
+	assert.Error(t, err)
+	for _, key := range keyring.JWKS().Keys {
+		assert.NotEqual(t, oldToken.Header["kid"], key.KeyId)
+	}
+}
@@ -0,0 +1,16 @@
+package infrastructure
+
+// Email is a message ready to be sent, with an HTML body and its plain-text
+// alternative.
+type Email struct {
+	To      []string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Mailer sends emails. SMTPMailer delivers them, FileMailer and MemoryMailer
+// keep them so they can be read during development and in tests.
+type Mailer interface {
+	Send(email Email) error
+}
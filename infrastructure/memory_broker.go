@@ -0,0 +1,69 @@
+package infrastructure
+
+import (
+	"sync"
+	"time"
+)
+
+type brokerSubscriber struct {
+	events chan Event
+	topics []string
+}
+
+// MemoryBroker is a Broker for a single server process.
+type MemoryBroker struct {
+	mu     sync.RWMutex
+	topics map[string]map[*brokerSubscriber]struct{}
+	buffer int
+}
+
+// NewMemoryBroker creates a broker whose subscribers buffer up to buffer
+// events before new ones are dropped.
+func NewMemoryBroker(buffer int) *MemoryBroker {
+	if buffer < 1 {
+		buffer = 1
+	}
+	return &MemoryBroker{topics: map[string]map[*brokerSubscriber]struct{}{}, buffer: buffer}
+}
+
+func (b *MemoryBroker) Publish(event Event) {
+	if event.Date.IsZero() {
+		event.Date = time.Now()
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.topics[event.Topic] {
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
+
+func (b *MemoryBroker) Subscribe(topics ...string) (<-chan Event, func()) {
+	sub := &brokerSubscriber{events: make(chan Event, b.buffer), topics: topics}
+	b.mu.Lock()
+	for _, topic := range topics {
+		if b.topics[topic] == nil {
+			b.topics[topic] = map[*brokerSubscriber]struct{}{}
+		}
+		b.topics[topic][sub] = struct{}{}
+	}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			for _, topic := range sub.topics {
+				delete(b.topics[topic], sub)
+				if len(b.topics[topic]) == 0 {
+					delete(b.topics, topic)
+				}
+			}
+			close(sub.events)
+		})
+	}
+	return sub.events, unsubscribe
+}
@@ -0,0 +1,29 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBroker(t *testing.T) {
+	broker := NewMemoryBroker(2)
+	events, unsubscribe := broker.Subscribe(BlogTopic("1"), UserTopic("u1"))
+
+	broker.Publish(Event{Topic: BlogTopic("1"), Type: "comment.created"})
+	broker.Publish(Event{Topic: BlogTopic("2"), Type: "comment.created"})
+	broker.Publish(Event{Topic: UserTopic("u1"), Type: "notification.created"})
+	// the buffer is full, this one is dropped instead of blocking
+	broker.Publish(Event{Topic: BlogTopic("1"), Type: "blog.updated"})
+
+	first := <-events
+	assert.Equal(t, "comment.created", first.Type)
+	assert.False(t, first.Date.IsZero())
+	assert.Equal(t, "notification.created", (<-events).Type)
+
+	unsubscribe()
+	unsubscribe()
+	_, open := <-events
+	assert.False(t, open)
+	broker.Publish(Event{Topic: BlogTopic("1"), Type: "blog.updated"})
+}
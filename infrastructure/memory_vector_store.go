@@ -0,0 +1,78 @@
+package infrastructure
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+)
+
+// MemoryVectorStore is a brute-force VectorStore kept in process memory.
+type MemoryVectorStore struct {
+	mu      sync.RWMutex
+	vectors map[string][]float32
+}
+
+func NewMemoryVectorStore() *MemoryVectorStore {
+	return &MemoryVectorStore{vectors: map[string][]float32{}}
+}
+
+func (s *MemoryVectorStore) Upsert(id string, vector []float32) error {
+	if id == "" || len(vector) == 0 {
+		return errors.New("id and vector are required")
+	}
+	stored := make([]float32, len(vector))
+	copy(stored, vector)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vectors[id] = stored
+	return nil
+}
+
+func (s *MemoryVectorStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.vectors, id)
+	return nil
+}
+
+func (s *MemoryVectorStore) Get(id string) ([]float32, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	vector, ok := s.vectors[id]
+	return vector, ok
+}
+
+func (s *MemoryVectorStore) Search(vector []float32, k int, exclude ...string) ([]VectorMatch, error) {
+	skip := map[string]bool{}
+	for _, id := range exclude {
+		skip[id] = true
+	}
+	s.mu.RLock()
+	matches := make([]VectorMatch, 0, len(s.vectors))
+	for id, v := range s.vectors {
+		if skip[id] || len(v) != len(vector) {
+			continue
+		}
+		matches = append(matches, VectorMatch{ID: id, Score: cosine(vector, v)})
+	}
+	s.mu.RUnlock()
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if k > 0 && len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches, nil
+}
+
+func cosine(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,52 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashEmbedderIsDeterministic(t *testing.T) {
+	embedder := NewHashEmbedder(64)
+	a, err := embedder.Embed("Concurrency patterns in Go")
+	assert.NoError(t, err)
+	b, err := embedder.Embed("Concurrency patterns in Go")
+	assert.NoError(t, err)
+	assert.Equal(t, a, b)
+	assert.Len(t, a, 64)
+
+	_, err = embedder.Embed("   ")
+	assert.Error(t, err)
+}
+
+func TestMemoryVectorStoreSearch(t *testing.T) {
+	embedder := NewHashEmbedder(128)
+	store := NewMemoryVectorStore()
+	docs := map[string]string{
+		"go":     "go routines channels concurrency",
+		"golang": "channels and go routines for concurrency",
+		"bread":  "sourdough bread flour water",
+	}
+	for id, text := range docs {
+		v, err := embedder.Embed(text)
+		assert.NoError(t, err)
+		assert.NoError(t, store.Upsert(id, v))
+	}
+
+	query, _ := embedder.Embed("concurrency with go channels")
+	matches, err := store.Search(query, 2)
+	assert.NoError(t, err)
+	assert.Len(t, matches, 2)
+	assert.NotEqual(t, "bread", matches[0].ID)
+	assert.NotEqual(t, "bread", matches[1].ID)
+
+	vector, ok := store.Get("go")
+	assert.True(t, ok)
+	matches, err = store.Search(vector, 1, "go")
+	assert.NoError(t, err)
+	assert.Equal(t, "golang", matches[0].ID)
+
+	assert.NoError(t, store.Delete("golang"))
+	_, ok = store.Get("golang")
+	assert.False(t, ok)
+}
@@ -0,0 +1,26 @@
+package infrastructure
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mentionPattern matches @username when the @ starts a word, so e-mail
+// addresses are not taken for mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([A-Za-z0-9_][A-Za-z0-9_.-]*)`)
+
+// ParseMentions returns the distinct usernames mentioned in content, in the
+// order they first appear.
+func ParseMentions(content string) []string {
+	usernames := []string{}
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		username := strings.TrimRight(match[1], ".-")
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+	}
+	return usernames
+}
@@ -0,0 +1,24 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected []string
+	}{
+		{"thanks @john_doe!", []string{"john_doe"}},
+		{"@jane and @mike.jones.", []string{"jane", "mike.jones"}},
+		{"(@jane) @jane @john", []string{"jane", "john"}},
+		{"mail me at john@example.com", []string{}},
+		{"no mentions here", []string{}},
+		{"@@double", []string{}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ParseMentions(tt.content), tt.content)
+	}
+}
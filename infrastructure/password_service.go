@@ -1,12 +1,154 @@
 package infrastructure
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithms.
+const (
+	PasswordBcrypt   = "bcrypt"
+	PasswordArgon2id = "argon2id"
+)
+
+// PasswordService hashes passwords with the configured algorithm and tells
+// when a stored hash should be replaced, after the algorithm or its cost
+// changed.
+type PasswordService struct {
+	Algorithm  string
+	BcryptCost int
+	// Argon2id parameters, memory in KiB.
+	Argon2Time    uint32
+	Argon2Memory  uint32
+	Argon2Threads uint8
+}
+
+// DefaultPasswordService hashes with bcrypt at its default cost, like every
+// password stored so far.
+var DefaultPasswordService = PasswordService{
+	Algorithm:     PasswordBcrypt,
+	BcryptCost:    bcrypt.DefaultCost,
+	Argon2Time:    3,
+	Argon2Memory:  64 * 1024,
+	Argon2Threads: 2,
+}
+
+var (
+	passwordServiceMu sync.RWMutex
+	passwordService   = DefaultPasswordService
+)
+
+// SetPasswordService replaces how passwords are hashed from now on. Unset
+// fields keep their default.
+func SetPasswordService(service PasswordService) {
+	if service.Algorithm == "" {
+		service.Algorithm = DefaultPasswordService.Algorithm
+	}
+	if service.BcryptCost == 0 {
+		service.BcryptCost = DefaultPasswordService.BcryptCost
+	}
+	if service.Argon2Time == 0 {
+		service.Argon2Time = DefaultPasswordService.Argon2Time
+	}
+	if service.Argon2Memory == 0 {
+		service.Argon2Memory = DefaultPasswordService.Argon2Memory
+	}
+	if service.Argon2Threads == 0 {
+		service.Argon2Threads = DefaultPasswordService.Argon2Threads
+	}
+	passwordServiceMu.Lock()
+	defer passwordServiceMu.Unlock()
+	passwordService = service
+}
+
+func currentPasswordService() PasswordService {
+	passwordServiceMu.RLock()
+	defer passwordServiceMu.RUnlock()
+	return passwordService
+}
+
+// PasswordHasher hashes the password with the configured service.
 func PasswordHasher(Password string) (string, error) {
-	hashed, err := bcrypt.GenerateFromPassword([]byte(Password), bcrypt.DefaultCost)
+	hashedPassword, err := currentPasswordService().Hash(Password)
 	if err != nil {
 		return "Internal server error", err
 	}
-	hashedPassword := string(hashed)
 	return hashedPassword, nil
 }
+
+// VerifyPassword checks the password against its stored hash, and tells
+// whether the hash should be replaced by PasswordHasher.
+func VerifyPassword(hash, password string) (ok bool, rehash bool) {
+	return currentPasswordService().Verify(hash, password)
+}
+
+func (s PasswordService) Hash(password string) (string, error) {
+	switch s.Algorithm {
+	case PasswordBcrypt:
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), s.BcryptCost)
+		return string(hashed), err
+	case PasswordArgon2id:
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, s.Argon2Time, s.Argon2Memory, s.Argon2Threads, 32)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, s.Argon2Memory, s.Argon2Time, s.Argon2Threads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+	return "", errors.New("unknown password hashing algorithm " + s.Algorithm)
+}
+
+func (s PasswordService) Verify(hash, password string) (ok bool, rehash bool) {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := parseArgon2id(hash)
+		if err != nil {
+			return false, false
+		}
+		candidate := argon2.IDKey([]byte(password), salt, params.Argon2Time, params.Argon2Memory, params.Argon2Threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(candidate, key) != 1 {
+			return false, false
+		}
+		return true, s.Algorithm != PasswordArgon2id || params.Argon2Time != s.Argon2Time ||
+			params.Argon2Memory != s.Argon2Memory || params.Argon2Threads != s.Argon2Threads
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return false, false
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return true, err != nil || s.Algorithm != PasswordBcrypt || cost < s.BcryptCost
+}
+
+// parseArgon2id reads a hash in the $argon2id$v=19$m=..,t=..,p=..$salt$key
+// format.
+func parseArgon2id(hash string) (PasswordService, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return PasswordService{}, nil, nil, errors.New("invalid argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return PasswordService{}, nil, nil, errors.New("unsupported argon2id version")
+	}
+	params := PasswordService{Algorithm: PasswordArgon2id}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Argon2Memory, &params.Argon2Time, &params.Argon2Threads); err != nil {
+		return PasswordService{}, nil, nil, err
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return PasswordService{}, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return PasswordService{}, nil, nil, errors.New("invalid argon2id hash")
+	}
+	return params, salt, key, nil
+}
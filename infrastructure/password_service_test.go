@@ -0,0 +1,73 @@
+package infrastructure
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordServiceRehash(t *testing.T) {
+	defer SetPasswordService(DefaultPasswordService)
+	bcryptHash, err := PasswordHasher("correct horse")
+	assert.NoError(t, err)
+	ok, rehash := VerifyPassword(bcryptHash, "correct horse")
+	assert.True(t, ok)
+	assert.False(t, rehash)
+	ok, _ = VerifyPassword(bcryptHash, "wrong horse")
+	assert.False(t, ok)
+
+	SetPasswordService(PasswordService{BcryptCost: bcrypt.DefaultCost + 1})
+	_, rehash = VerifyPassword(bcryptHash, "correct horse")
+	assert.True(t, rehash, "the cost went up")
+
+	SetPasswordService(PasswordService{Algorithm: PasswordArgon2id, Argon2Memory: 8 * 1024, Argon2Time: 1})
+	_, rehash = VerifyPassword(bcryptHash, "correct horse")
+	assert.True(t, rehash, "the algorithm changed")
+	argonHash, err := PasswordHasher("correct horse")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(argonHash, "$argon2id$v=19$m=8192,t=1,p=2$"))
+	ok, rehash = VerifyPassword(argonHash, "correct horse")
+	assert.True(t, ok)
+	assert.False(t, rehash)
+	ok, _ = VerifyPassword(argonHash, "wrong horse")
+	assert.False(t, ok)
+
+	SetPasswordService(DefaultPasswordService)
+	ok, rehash = VerifyPassword(argonHash, "correct horse")
+	assert.True(t, ok, "old hashes keep working")
+	assert.True(t, rehash)
+	ok, _ = VerifyPassword("$argon2id$v=19$garbage", "correct horse")
+	assert.False(t, ok)
+}
+
+func TestBreachedPasswords(t *testing.T) {
+	bundled, err := NewBreachedPasswords("")
+	assert.NoError(t, err)
+	for password, want := range map[string]bool{"password": true, "qwerty123": true, "correct horse 42": false} {
+		breached, err := bundled.IsBreached(password)
+		assert.NoError(t, err)
+		assert.Equal(t, want, breached, password)
+	}
+
+	sum := sha1.Sum([]byte("correct horse 42"))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte("00000000000000000000000000000000000:3\n"+hash[5:]+":12\n"), 0o600))
+	ranges, err := NewBreachedPasswords(dir)
+	assert.NoError(t, err)
+	breached, err := ranges.IsBreached("correct horse 42")
+	assert.NoError(t, err)
+	assert.True(t, breached, "found in the range file of its prefix")
+	breached, err = ranges.IsBreached("password")
+	assert.NoError(t, err)
+	assert.True(t, breached, "the bundled list is still used")
+	breached, err = ranges.IsBreached("correct horse 43")
+	assert.NoError(t, err)
+	assert.False(t, breached)
+}
@@ -0,0 +1,64 @@
+package infrastructure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yesetoda/BlogMate/domain"
+)
+
+// fakePermissions grants what the built-in roles grant, and edit-any to
+// translators.
+type fakePermissions struct{}
+
+func (fakePermissions) Permissions(roles []string) ([]string, error) {
+	permissions := []string{}
+	for _, name := range roles {
+		if name == "translator" {
+			permissions = append(permissions, domain.PermEditAnyBlog)
+		}
+		role, _ := domain.BuiltInRole(name)
+		permissions = append(permissions, role.Permissions...)
+	}
+	return permissions, nil
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		name       string
+		options    []AuthOption
+		claims     domain.Claims
+		permission string
+		want       int
+	}{
+		{"users without roles are authors", nil, domain.Claims{ID: "u1"}, domain.PermCreateBlog, http.StatusOK},
+		{"authors cannot manage users", nil, domain.Claims{ID: "u1"}, domain.PermManageUsers, http.StatusForbidden},
+		{"readers cannot write blogs", nil, domain.Claims{ID: "u1", Roles: []string{domain.RoleReader}}, domain.PermCreateBlog, http.StatusForbidden},
+		{"moderators delete any comment", nil, domain.Claims{ID: "u1", Roles: []string{domain.RoleModerator}}, domain.PermDeleteAnyComment, http.StatusOK},
+		{"old admin tokens keep their rights", nil, domain.Claims{ID: "u1", IsAdmin: true}, domain.PermManageRoles, http.StatusOK},
+		{"custom roles", []AuthOption{WithPermissions(fakePermissions{})}, domain.Claims{ID: "u1", Roles: []string{"translator"}}, domain.PermEditAnyBlog, http.StatusOK},
+		{"custom roles need a checker", nil, domain.Claims{ID: "u1", Roles: []string{"translator"}}, domain.PermEditAnyBlog, http.StatusForbidden},
+		{"admins need a second factor", []AuthOption{WithAdminTwoFactor()}, domain.Claims{ID: "u1", IsAdmin: true, Roles: []string{domain.RoleAdmin}}, domain.PermManageUsers, http.StatusForbidden},
+		{"but not to write blogs", []AuthOption{WithAdminTwoFactor()}, domain.Claims{ID: "u1", IsAdmin: true, Roles: []string{domain.RoleAdmin}}, domain.PermCreateBlog, http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ac := NewAuthController(nil, tc.options...).(*AuthController)
+			router := gin.New()
+			claims := tc.claims
+			router.GET("/", func(c *gin.Context) {
+				c.Set("claims", &claims)
+				assert.NoError(t, ac.setPermissions(c, &claims))
+			}, ac.RequirePermission(tc.permission), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, tc.want, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package infrastructure
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/yesetoda/BlogMate/domain"
+)
+
+// tokenVersionTTL is how long the token version of a user is cached. Bumps
+// made by this process are seen at once, those of other instances after it.
+const tokenVersionTTL = 30 * time.Second
+
+type cachedVersion struct {
+	version   int
+	deleted   bool
+	fetchedAt time.Time
+}
+
+// RevocationList tells whether an access token was revoked. Revoked token
+// ids are kept in memory until the tokens expire and stored in Mongo so they
+// survive restarts and reach the other instances. Tokens issued before the
+// token version of their user was bumped are revoked too.
+type RevocationList struct {
+	mu       sync.RWMutex
+	revoked  map[string]time.Time
+	versions map[string]cachedVersion
+	store    domain.RevocationRepository
+	users    domain.UserRepository
+	now      func() time.Time
+}
+
+func NewRevocationList(store domain.RevocationRepository, users domain.UserRepository) *RevocationList {
+	return &RevocationList{
+		revoked:  map[string]time.Time{},
+		versions: map[string]cachedVersion{},
+		store:    store,
+		users:    users,
+		now:      time.Now,
+	}
+}
+
+// RevokeToken revokes one access token, identified by its jti.
+func (l *RevocationList) RevokeToken(claims *domain.Claims) error {
+	if claims.Id == "" {
+		return errors.New("the token has no id")
+	}
+	expiresAt := time.Unix(claims.ExpiresAt, 0)
+	l.mu.Lock()
+	l.revoked[claims.Id] = expiresAt
+	l.mu.Unlock()
+	return l.store.RevokeToken(domain.RevokedToken{
+		TokenId:   claims.Id,
+		UserId:    claims.ID,
+		ExpiresAt: expiresAt,
+		RevokedAt: l.now(),
+	})
+}
+
+// RevokeUserTokens revokes every access token issued to the user so far.
+func (l *RevocationList) RevokeUserTokens(userId string) error {
+	version, err := l.users.BumpTokenVersion(userId)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.versions[userId] = cachedVersion{version: version, fetchedAt: l.now()}
+	l.mu.Unlock()
+	return nil
+}
+
+// IsRevoked reports whether the token was revoked, by id or because its user
+// was deleted or had its tokens revoked after it was issued.
+func (l *RevocationList) IsRevoked(claims *domain.Claims) (bool, error) {
+	now := l.now()
+	l.mu.RLock()
+	expiresAt, revoked := l.revoked[claims.Id]
+	cached, cachedOk := l.versions[claims.ID]
+	l.mu.RUnlock()
+	if revoked && now.Before(expiresAt) {
+		return true, nil
+	}
+	if !cachedOk || now.Sub(cached.fetchedAt) > tokenVersionTTL {
+		users, err := l.users.Get(domain.UserFilterOption{Filter: domain.UserFilter{UserId: claims.ID}})
+		if err != nil {
+			return false, err
+		}
+		cached = cachedVersion{fetchedAt: now, deleted: len(users) == 0}
+		if len(users) > 0 {
+			cached.version = users[0].TokenVersion
+		}
+		l.mu.Lock()
+		l.versions[claims.ID] = cached
+		l.mu.Unlock()
+	}
+	return cached.deleted || claims.TokenVersion < cached.version, nil
+}
+
+// Sync drops the expired tokens and loads the ones revoked by other
+// instances.
+func (l *RevocationList) Sync() error {
+	now := l.now()
+	tokens, err := l.store.GetRevokedTokens(now)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id, expiresAt := range l.revoked {
+		if !now.Before(expiresAt) {
+			delete(l.revoked, id)
+		}
+	}
+	for userId, cached := range l.versions {
+		if now.Sub(cached.fetchedAt) > tokenVersionTTL {
+			delete(l.versions, userId)
+		}
+	}
+	for _, token := range tokens {
+		l.revoked[token.TokenId] = token.ExpiresAt
+	}
+	return nil
+}
+
+// Run syncs the list every interval until stop is closed.
+func (l *RevocationList) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := l.Sync(); err != nil {
+			log.Println("syncing revoked tokens failed:", err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
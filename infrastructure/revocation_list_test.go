@@ -0,0 +1,113 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/yesetoda/BlogMate/domain"
+)
+
+type fakeRevocationStore struct {
+	tokens []domain.RevokedToken
+}
+
+func (s *fakeRevocationStore) RevokeToken(token domain.RevokedToken) error {
+	s.tokens = append(s.tokens, token)
+	return nil
+}
+
+func (s *fakeRevocationStore) GetRevokedTokens(now time.Time) ([]domain.RevokedToken, error) {
+	tokens := []domain.RevokedToken{}
+	for _, token := range s.tokens {
+		if token.ExpiresAt.After(now) {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+type fakeVersionedUsers struct {
+	domain.UserRepository
+	users   map[string]domain.User
+	lookups int
+}
+
+func (r *fakeVersionedUsers) Get(opts domain.UserFilterOption) ([]domain.User, error) {
+	r.lookups++
+	if user, ok := r.users[opts.Filter.UserId]; ok {
+		return []domain.User{user}, nil
+	}
+	return []domain.User{}, nil
+}
+
+func (r *fakeVersionedUsers) BumpTokenVersion(userId string) (int, error) {
+	user := r.users[userId]
+	user.TokenVersion++
+	r.users[userId] = user
+	return user.TokenVersion, nil
+}
+
+func TestRevocationListRevokeToken(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	store := &fakeRevocationStore{}
+	users := &fakeVersionedUsers{users: map[string]domain.User{"u1": {ID: "u1"}}}
+	list := NewRevocationList(store, users)
+	list.now = func() time.Time { return now }
+
+	claims := &domain.Claims{ID: "u1", StandardClaims: jwt.StandardClaims{Id: "jti-1", ExpiresAt: now.Add(time.Minute).Unix()}}
+	other := &domain.Claims{ID: "u1", StandardClaims: jwt.StandardClaims{Id: "jti-2", ExpiresAt: now.Add(time.Minute).Unix()}}
+	assert.NoError(t, list.RevokeToken(claims))
+	assert.Len(t, store.tokens, 1)
+
+	revoked, err := list.IsRevoked(claims)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = list.IsRevoked(other)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	// another instance loads the revoked token from the store
+	restarted := NewRevocationList(store, users)
+	restarted.now = list.now
+	assert.NoError(t, restarted.Sync())
+	revoked, _ = restarted.IsRevoked(claims)
+	assert.True(t, revoked)
+
+	// expired tokens are dropped
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, restarted.Sync())
+	assert.Empty(t, restarted.revoked)
+
+	assert.Error(t, list.RevokeToken(&domain.Claims{ID: "u1"}))
+}
+
+func TestRevocationListTokenVersion(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	users := &fakeVersionedUsers{users: map[string]domain.User{"u1": {ID: "u1", TokenVersion: 2}}}
+	list := NewRevocationList(&fakeRevocationStore{}, users)
+	list.now = func() time.Time { return now }
+
+	old := &domain.Claims{ID: "u1", TokenVersion: 1}
+	current := &domain.Claims{ID: "u1", TokenVersion: 2}
+	revoked, err := list.IsRevoked(old)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, _ = list.IsRevoked(current)
+	assert.False(t, revoked)
+	assert.Equal(t, 1, users.lookups, "the version is cached")
+
+	assert.NoError(t, list.RevokeUserTokens("u1"))
+	revoked, _ = list.IsRevoked(current)
+	assert.True(t, revoked)
+	revoked, _ = list.IsRevoked(&domain.Claims{ID: "u1", TokenVersion: 3})
+	assert.False(t, revoked)
+
+	// tokens of deleted users are revoked once the cache expires
+	delete(users.users, "u1")
+	now = now.Add(tokenVersionTTL + time.Second)
+	revoked, err = list.IsRevoked(&domain.Claims{ID: "u1", TokenVersion: 3})
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
@@ -1,47 +1,119 @@
 package infrastructure
 
 import (
+	"errors"
 	"log"
-	"net/smtp"
+	"sync"
 
 	"github.com/yesetoda/BlogMate/config"
 )
 
-func SendEmail(toEmail string, title string, body string, link string) error {
-	cfg, err := config.LoadConfig() // Renamed variable to `cfg`
+// Email backends selected with EMAIL_BACKEND.
+const (
+	SMTPBackend   = "smtp"
+	FileBackend   = "file"
+	MemoryBackend = "memory"
+)
+
+var (
+	mailerMu      sync.Mutex
+	defaultMailer Mailer
+)
+
+// NewMailer builds the mailer configured by the EMAIL_* settings. SMTP is used
+// when no backend is set, and needs a sender address.
+func NewMailer(cfg *config.Config) (Mailer, error) {
+	switch cfg.Email.Backend {
+	case "", SMTPBackend:
+		if cfg.Email.From == "" {
+			return nil, errors.New("EMAIL_FROM is required by the smtp email backend")
+		}
+		host := cfg.Email.Host
+		if host == "" {
+			host = "smtp.gmail.com"
+		}
+		return NewSMTPMailer(SMTPConfig{
+			Host:     host,
+			Port:     cfg.Email.Port,
+			Username: cfg.Email.Username,
+			Password: cfg.Email.Key,
+			From:     cfg.Email.From,
+			TLS:      cfg.Email.TLS,
+			Timeout:  cfg.Email.Timeout,
+		}), nil
+	case FileBackend, "maildir":
+		dir := cfg.Email.CaptureDir
+		if dir == "" {
+			dir = "mail"
+		}
+		from := cfg.Email.From
+		if from == "" {
+			from = "blogmate@localhost"
+		}
+		return NewFileMailer(dir, from)
+	case MemoryBackend:
+		return NewMemoryMailer(), nil
+	}
+	return nil, errors.New("unknown email backend " + cfg.Email.Backend)
+}
+
+// SetDefaultMailer replaces the mailer used by SendEmail and
+// SendTemplatedEmail, e.g. with a MemoryMailer in tests.
+func SetDefaultMailer(mailer Mailer) {
+	mailerMu.Lock()
+	defer mailerMu.Unlock()
+	defaultMailer = mailer
+}
+
+// DefaultMailer returns the mailer set with SetDefaultMailer, or builds the
+// configured one on first use.
+func DefaultMailer() (Mailer, error) {
+	mailerMu.Lock()
+	defer mailerMu.Unlock()
+	if defaultMailer != nil {
+		return defaultMailer, nil
+	}
+	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Println("Failed to load config:", err)
-		return err // Return error to avoid using a nil/incomplete config
-	}
-
-	// Construct HTML email message
-	message := `
-	<!DOCTYPE html>
-	<html>
-	<head>
-		<meta charset="UTF-8">
-		<title>` + title + `</title>
-	</head>
-	<body>
-		<h1>` + title + `</h1>
-		<p>` + body + `</p>
-		<a href="` + link + `">Click the Link</a>
-	</body>
-	</html>
-	`
-
-	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
-	key := cfg.Email.Key
-	host := "smtp.gmail.com"
-	auth := smtp.PlainAuth("", "yeneineh.seiba@a2sv.org", key, host)
-
-	port := "587"
-	address := host + ":" + port
-	messages := []byte(mime + message)
-
-	// Send the email via Gmail's SMTP server
-	err = smtp.SendMail(address, auth, "yeneineh.seiba@a2sv.org", []string{toEmail}, messages)
+		return nil, err
+	}
+	mailer, err := NewMailer(cfg)
 	if err != nil {
+		return nil, err
+	}
+	defaultMailer = mailer
+	return mailer, nil
+}
+
+// SendTemplatedEmail renders the named template in the given locale and sends
+// it to toEmail with the default mailer.
+func SendTemplatedEmail(toEmail, template, locale string, data EmailData) error {
+	email, err := RenderEmail(template, locale, data)
+	if err != nil {
+		return err
+	}
+	email.To = []string{toEmail}
+	return sendWithDefault(email)
+}
+
+// SendEmail sends a notification email whose subject is title.
+func SendEmail(toEmail string, title string, body string, link string) error {
+	email, err := RenderEmail(NotificationEmail, DefaultLocale, EmailData{Title: title, Body: body, Link: link})
+	if err != nil {
+		return err
+	}
+	email.To = []string{toEmail}
+	email.Subject = title
+	return sendWithDefault(email)
+}
+
+func sendWithDefault(email Email) error {
+	mailer, err := DefaultMailer()
+	if err != nil {
+		return err
+	}
+	if err := mailer.Send(email); err != nil {
 		log.Println("send mail error:", err)
 		return err
 	}
@@ -1,17 +1,118 @@
 package infrastructure
 
 import (
+	"bytes"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/yesetoda/BlogMate/config"
 )
 
 func TestSendEmail(t *testing.T) {
+	mailer := NewMemoryMailer()
+	SetDefaultMailer(mailer)
+	defer SetDefaultMailer(nil)
+
 	toEmail := "abel.wendmu@a2sv.org"
 	title := "Test Email"
 	body := "This is a test email"
-	hashedpwd := "hashedpassword"
+	link := "http://localhost:8080/blogs/1"
+
+	err := SendEmail(toEmail, title, body, link)
+	assert.NoError(t, err)
+
+	sent, ok := mailer.Last()
+	assert.True(t, ok)
+	assert.Equal(t, []string{toEmail}, sent.To)
+	assert.Equal(t, title, sent.Subject)
+	assert.Contains(t, sent.HTML, `href="`+link+`"`)
+	assert.Contains(t, sent.Text, body)
+}
+
+func TestRenderEmail(t *testing.T) {
+	data := EmailData{Name: "<Abel>", Link: "http://localhost:8080/users/accountVerification/?email=a@b.c&token=x"}
+
+	email, err := RenderEmail(VerificationEmail, "fr-FR", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Confirmez votre compte BlogMate", email.Subject)
+	assert.Contains(t, email.HTML, "Hi &lt;Abel&gt;,")
+	assert.Contains(t, email.Text, "Hi <Abel>,")
+	assert.Contains(t, email.Text, data.Link)
+
+	email, err = RenderEmail(PasswordResetEmail, "de", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Reset your BlogMate password", email.Subject)
+
+	_, err = RenderEmail("unknown", "en", data)
+	assert.Error(t, err)
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	mailer, err := NewFileMailer(dir, "BlogMate <no-reply@blogmate.local>")
+	assert.NoError(t, err)
+
+	err = mailer.Send(Email{To: []string{"a@b.c"}, Subject: "Hello", HTML: "<p>Hi</p>", Text: "Hi"})
+	assert.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "new", "*.eml"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	raw, err := os.ReadFile(files[0])
+	assert.NoError(t, err)
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	assert.NoError(t, err)
+	assert.Equal(t, "a@b.c", msg.Header.Get("To"))
+	assert.Contains(t, msg.Header.Get("Content-Type"), "multipart/alternative")
+
+	err = mailer.Send(Email{Subject: "Nobody"})
+	assert.Error(t, err)
+}
+
+func TestSMTPMailerTimeout(t *testing.T) {
+	// a server that accepts the connection but never greets
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(2 * time.Second)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	mailer := NewSMTPMailer(SMTPConfig{
+		Host:    addr.IP.String(),
+		Port:    addr.Port,
+		From:    "no-reply@blogmate.local",
+		TLS:     SMTPNoTLS,
+		Timeout: 100 * time.Millisecond,
+	})
+	start := time.Now()
+	err = mailer.Send(Email{To: []string{"a@b.c"}, Subject: "Hi", Text: "Hi"})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second, "the send waits for the timeout only")
+}
+
+func TestNewMailerRequiresSender(t *testing.T) {
+	cfg := &config.Config{}
+	_, err := NewMailer(cfg)
+	assert.Error(t, err)
+
+	cfg.Email.From = "no-reply@blogmate.local"
+	mailer, err := NewMailer(cfg)
+	assert.NoError(t, err)
+	assert.IsType(t, &SMTPMailer{}, mailer)
 
-	err := SendEmail(toEmail, title, body, hashedpwd)
+	cfg.Email.Backend = MemoryBackend
+	cfg.Email.From = ""
+	_, err = NewMailer(cfg)
 	assert.NoError(t, err)
 }
@@ -0,0 +1,121 @@
+package infrastructure
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// stopWords are dropped before weighting so that similarity is driven by the
+// words that actually describe a post.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"but": true, "by": true, "for": true, "from": true, "has": true, "have": true, "how": true,
+	"i": true, "in": true, "is": true, "it": true, "its": true, "of": true, "on": true,
+	"or": true, "that": true, "the": true, "this": true, "to": true, "was": true, "we": true,
+	"what": true, "when": true, "which": true, "will": true, "with": true, "you": true, "your": true,
+}
+
+// Tokenize lowercases text and splits it into words, dropping stop words and
+// single characters.
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	tokens := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if len(f) < 2 || stopWords[f] {
+			continue
+		}
+		tokens = append(tokens, f)
+	}
+	return tokens
+}
+
+// TFIDFIndex holds a TF-IDF vector for every document of a corpus.
+type TFIDFIndex struct {
+	vectors map[string]map[string]float64
+}
+
+// NewTFIDFIndex builds an index from documents keyed by id.
+func NewTFIDFIndex(docs map[string][]string) *TFIDFIndex {
+	df := map[string]int{}
+	for _, tokens := range docs {
+		seen := map[string]bool{}
+		for _, t := range tokens {
+			if !seen[t] {
+				seen[t] = true
+				df[t]++
+			}
+		}
+	}
+
+	n := float64(len(docs))
+	vectors := make(map[string]map[string]float64, len(docs))
+	for id, tokens := range docs {
+		tf := map[string]float64{}
+		for _, t := range tokens {
+			tf[t]++
+		}
+		vec := make(map[string]float64, len(tf))
+		for t, count := range tf {
+			idf := math.Log(1+n/float64(df[t])) + 1
+			vec[t] = (count / float64(len(tokens))) * idf
+		}
+		vectors[id] = vec
+	}
+	return &TFIDFIndex{vectors: vectors}
+}
+
+// Similarity returns the cosine similarity between two indexed documents.
+func (idx *TFIDFIndex) Similarity(a, b string) float64 {
+	return CosineSimilarity(idx.vectors[a], idx.vectors[b])
+}
+
+// CosineSimilarity compares two sparse vectors.
+func CosineSimilarity(a, b map[string]float64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for t, w := range a {
+		normA += w * w
+		if v, ok := b[t]; ok {
+			dot += w * v
+		}
+	}
+	for _, w := range b {
+		normB += w * w
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// TagOverlap returns the Jaccard index of two tag sets, ignoring case.
+func TagOverlap(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	set := map[string]bool{}
+	for _, t := range a {
+		set[strings.ToLower(strings.TrimSpace(t))] = true
+	}
+	union := len(set)
+	shared := 0
+	seen := map[string]bool{}
+	for _, t := range b {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		if set[t] {
+			shared++
+		} else {
+			union++
+		}
+	}
+	return float64(shared) / float64(union)
+}
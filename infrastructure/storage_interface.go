@@ -0,0 +1,19 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrObjectNotFound is returned by storages for keys they do not hold.
+var ErrObjectNotFound = errors.New("object not found")
+
+// Storage keeps uploaded files by key. Keys are slash separated paths such as
+// "<owner>/<id>".
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object, doing nothing when there is none.
+	Delete(ctx context.Context, key string) error
+}
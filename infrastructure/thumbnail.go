@@ -0,0 +1,104 @@
+package infrastructure
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+// maxImagePixels bounds the size of images decoded, so that a small file
+// claiming huge dimensions cannot exhaust memory.
+const maxImagePixels = 40_000_000
+
+// Thumbnail is a scaled down copy of an image.
+type Thumbnail struct {
+	Data        []byte
+	ContentType string
+	// Width and Height are those of the original image.
+	Width  int
+	Height int
+}
+
+// MakeThumbnail scales a JPEG, PNG or GIF image down to fit in a square of
+// maxSide pixels, keeping its aspect ratio, and encodes it as JPEG, or PNG
+// when it may be transparent. Smaller images keep their size.
+func MakeThumbnail(data []byte, maxSide int) (Thumbnail, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Thumbnail{}, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxImagePixels {
+		return Thumbnail{}, errors.New("the image is too large")
+	}
+	var source image.Image
+	switch format {
+	case "jpeg":
+		source, err = jpeg.Decode(bytes.NewReader(data))
+	case "png":
+		source, err = png.Decode(bytes.NewReader(data))
+	case "gif":
+		// animated GIFs are thumbnailed from their first frame
+		source, err = gif.Decode(bytes.NewReader(data))
+	default:
+		return Thumbnail{}, errors.New("unsupported image format " + format)
+	}
+	if err != nil {
+		return Thumbnail{}, err
+	}
+	width, height := config.Width, config.Height
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			width, height = maxSide, max(1, config.Height*maxSide/config.Width)
+		} else {
+			width, height = max(1, config.Width*maxSide/config.Height), maxSide
+		}
+	}
+	scaled := scaleImage(source, width, height)
+	var out bytes.Buffer
+	thumbnail := Thumbnail{Width: config.Width, Height: config.Height}
+	if format == "jpeg" {
+		thumbnail.ContentType = "image/jpeg"
+		err = jpeg.Encode(&out, scaled, &jpeg.Options{Quality: 85})
+	} else {
+		thumbnail.ContentType = "image/png"
+		err = png.Encode(&out, scaled)
+	}
+	thumbnail.Data = out.Bytes()
+	return thumbnail, err
+}
+
+// scaleImage resizes the image by averaging the source pixels each target
+// pixel covers, which keeps downscaled images smooth.
+func scaleImage(source image.Image, width, height int) *image.NRGBA {
+	bounds := source.Bounds()
+	target := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBA64Model.Convert(source.At(sx, sy)).(color.NRGBA64)
+					r += uint64(c.R) * uint64(c.A)
+					g += uint64(c.G) * uint64(c.A)
+					b += uint64(c.B) * uint64(c.A)
+					a += uint64(c.A)
+					n++
+				}
+			}
+			pixel := color.NRGBA{A: uint8(a / n >> 8)}
+			if a > 0 {
+				pixel.R, pixel.G, pixel.B = uint8(r/a>>8), uint8(g/a>>8), uint8(b/a>>8)
+			}
+			target.SetNRGBA(x, y, pixel)
+		}
+	}
+	return target
+}
@@ -0,0 +1,51 @@
+package infrastructure
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeThumbnail(t *testing.T) {
+	photo := image.NewRGBA(image.Rect(0, 0, 800, 400))
+	for x := 0; x < 800; x++ {
+		for y := 0; y < 400; y++ {
+			photo.Set(x, y, color.RGBA{R: uint8(x % 2 * 255), A: 255})
+		}
+	}
+	var jpegData bytes.Buffer
+	require.NoError(t, jpeg.Encode(&jpegData, photo, nil))
+	thumbnail, err := MakeThumbnail(jpegData.Bytes(), 200)
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", thumbnail.ContentType)
+	assert.Equal(t, 800, thumbnail.Width)
+	assert.Equal(t, 400, thumbnail.Height)
+	scaled, err := jpeg.Decode(bytes.NewReader(thumbnail.Data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 200, 100), scaled.Bounds())
+	// red and black columns average to a dark red
+	r, g, _, _ := scaled.At(100, 50).RGBA()
+	assert.InDelta(t, 0x7fff, r, 0x1800)
+	assert.Less(t, g, uint32(0x2000))
+
+	icon := image.NewNRGBA(image.Rect(0, 0, 16, 32))
+	var pngData bytes.Buffer
+	require.NoError(t, png.Encode(&pngData, icon))
+	thumbnail, err = MakeThumbnail(pngData.Bytes(), 200)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", thumbnail.ContentType, "transparency is kept")
+	scaled, err = png.Decode(bytes.NewReader(thumbnail.Data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 16, 32), scaled.Bounds(), "small images are not enlarged")
+	_, _, _, a := scaled.At(0, 0).RGBA()
+	assert.Zero(t, a)
+
+	_, err = MakeThumbnail([]byte("%PDF-1.4"), 200)
+	assert.Error(t, err)
+}
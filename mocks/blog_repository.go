@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	domain "github.com/yesetoda/BlogMate/domain"
 
 	mock "github.com/stretchr/testify/mock"
@@ -13,22 +15,60 @@ type BlogRepository struct {
 	mock.Mock
 }
 
-// AddComment provides a mock function with given fields: blogId, comment
-func (_m *BlogRepository) AddComment(blogId string, comment domain.Comment) error {
-	ret := _m.Called(blogId, comment)
+// AddComment provides a mock function with given fields: blogid, comment
+func (_m *BlogRepository) AddComment(blogid string, comment domain.Comment) (domain.Comment, error) {
+	ret := _m.Called(blogid, comment)
 
 	if len(ret) == 0 {
 		panic("no return value specified for AddComment")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string, domain.Comment) error); ok {
-		r0 = rf(blogId, comment)
+	var r0 domain.Comment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, domain.Comment) (domain.Comment, error)); ok {
+		return rf(blogid, comment)
+	}
+	if rf, ok := ret.Get(0).(func(string, domain.Comment) domain.Comment); ok {
+		r0 = rf(blogid, comment)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(domain.Comment)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(string, domain.Comment) error); ok {
+		r1 = rf(blogid, comment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddCommentWithContext provides a mock function with given fields: ctx, blogid, comment
+func (_m *BlogRepository) AddCommentWithContext(ctx context.Context, blogid string, comment domain.Comment) (domain.Comment, error) {
+	ret := _m.Called(ctx, blogid, comment)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddCommentWithContext")
+	}
+
+	var r0 domain.Comment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Comment) (domain.Comment, error)); ok {
+		return rf(ctx, blogid, comment)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Comment) domain.Comment); ok {
+		r0 = rf(ctx, blogid, comment)
+	} else {
+		r0 = ret.Get(0).(domain.Comment)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.Comment) error); ok {
+		r1 = rf(ctx, blogid, comment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // CreateBlog provides a mock function with given fields: b
@@ -59,6 +99,34 @@ func (_m *BlogRepository) CreateBlog(b domain.Blog) (domain.Blog, error) {
 	return r0, r1
 }
 
+// CreateBlogWithContext provides a mock function with given fields: ctx, b
+func (_m *BlogRepository) CreateBlogWithContext(ctx context.Context, b domain.Blog) (domain.Blog, error) {
+	ret := _m.Called(ctx, b)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateBlogWithContext")
+	}
+
+	var r0 domain.Blog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Blog) (domain.Blog, error)); ok {
+		return rf(ctx, b)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Blog) domain.Blog); ok {
+		r0 = rf(ctx, b)
+	} else {
+		r0 = ret.Get(0).(domain.Blog)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Blog) error); ok {
+		r1 = rf(ctx, b)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteBlog provides a mock function with given fields: blogId, authorId
 func (_m *BlogRepository) DeleteBlog(blogId string, authorId string) error {
 	ret := _m.Called(blogId, authorId)
@@ -77,29 +145,47 @@ func (_m *BlogRepository) DeleteBlog(blogId string, authorId string) error {
 	return r0
 }
 
-// FindPopularBlog provides a mock function with given fields:
-func (_m *BlogRepository) FindPopularBlog() ([]domain.Blog, error) {
-	ret := _m.Called()
+// DeleteBlogWithContext provides a mock function with given fields: ctx, blogId, authorId
+func (_m *BlogRepository) DeleteBlogWithContext(ctx context.Context, blogId string, authorId string) error {
+	ret := _m.Called(ctx, blogId, authorId)
 
 	if len(ret) == 0 {
-		panic("no return value specified for FindPopularBlog")
+		panic("no return value specified for DeleteBlogWithContext")
 	}
 
-	var r0 []domain.Blog
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, blogId, authorId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteComment provides a mock function with given fields: blogId, commentId, authorId
+func (_m *BlogRepository) DeleteComment(blogId string, commentId string, authorId string) ([]string, error) {
+	ret := _m.Called(blogId, commentId, authorId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteComment")
+	}
+
+	var r0 []string
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]domain.Blog, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(string, string, string) ([]string, error)); ok {
+		return rf(blogId, commentId, authorId)
 	}
-	if rf, ok := ret.Get(0).(func() []domain.Blog); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(string, string, string) []string); ok {
+		r0 = rf(blogId, commentId, authorId)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]domain.Blog)
+			r0 = ret.Get(0).([]string)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(blogId, commentId, authorId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -107,29 +193,29 @@ func (_m *BlogRepository) FindPopularBlog() ([]domain.Blog, error) {
 	return r0, r1
 }
 
-// GetAllComments provides a mock function with given fields: blogId
-func (_m *BlogRepository) GetAllComments(blogId string) ([]domain.Comment, error) {
-	ret := _m.Called(blogId)
+// FindPopularBlog provides a mock function with given fields:
+func (_m *BlogRepository) FindPopularBlog() ([]domain.Blog, error) {
+	ret := _m.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetAllComments")
+		panic("no return value specified for FindPopularBlog")
 	}
 
-	var r0 []domain.Comment
+	var r0 []domain.Blog
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]domain.Comment, error)); ok {
-		return rf(blogId)
+	if rf, ok := ret.Get(0).(func() ([]domain.Blog, error)); ok {
+		return rf()
 	}
-	if rf, ok := ret.Get(0).(func(string) []domain.Comment); ok {
-		r0 = rf(blogId)
+	if rf, ok := ret.Get(0).(func() []domain.Blog); ok {
+		r0 = rf()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]domain.Comment)
+			r0 = ret.Get(0).([]domain.Blog)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(blogId)
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -137,29 +223,29 @@ func (_m *BlogRepository) GetAllComments(blogId string) ([]domain.Comment, error
 	return r0, r1
 }
 
-// GetAllRepliesForComment provides a mock function with given fields: blogId, commentId
-func (_m *BlogRepository) GetAllRepliesForComment(blogId string, commentId string) ([]domain.Reply, error) {
-	ret := _m.Called(blogId, commentId)
+// GetAllComments provides a mock function with given fields: blogId, opt
+func (_m *BlogRepository) GetAllComments(blogId string, opt domain.PaginationInfo) ([]domain.Comment, error) {
+	ret := _m.Called(blogId, opt)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetAllRepliesForComment")
+		panic("no return value specified for GetAllComments")
 	}
 
-	var r0 []domain.Reply
+	var r0 []domain.Comment
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) ([]domain.Reply, error)); ok {
-		return rf(blogId, commentId)
+	if rf, ok := ret.Get(0).(func(string, domain.PaginationInfo) ([]domain.Comment, error)); ok {
+		return rf(blogId, opt)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) []domain.Reply); ok {
-		r0 = rf(blogId, commentId)
+	if rf, ok := ret.Get(0).(func(string, domain.PaginationInfo) []domain.Comment); ok {
+		r0 = rf(blogId, opt)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]domain.Reply)
+			r0 = ret.Get(0).([]domain.Comment)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(blogId, commentId)
+	if rf, ok := ret.Get(1).(func(string, domain.PaginationInfo) error); ok {
+		r1 = rf(blogId, opt)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -225,6 +311,36 @@ func (_m *BlogRepository) GetBlogById(blogid string) (domain.Blog, error) {
 	return r0, r1
 }
 
+// GetChildComments provides a mock function with given fields: blogId, parentId, opt
+func (_m *BlogRepository) GetChildComments(blogId string, parentId string, opt domain.PaginationInfo) ([]domain.Comment, error) {
+	ret := _m.Called(blogId, parentId, opt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChildComments")
+	}
+
+	var r0 []domain.Comment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, domain.PaginationInfo) ([]domain.Comment, error)); ok {
+		return rf(blogId, parentId, opt)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, domain.PaginationInfo) []domain.Comment); ok {
+		r0 = rf(blogId, parentId, opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Comment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, domain.PaginationInfo) error); ok {
+		r1 = rf(blogId, parentId, opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCommentById provides a mock function with given fields: blogId, commentId
 func (_m *BlogRepository) GetCommentById(blogId string, commentId string) (domain.Comment, error) {
 	ret := _m.Called(blogId, commentId)
@@ -253,27 +369,29 @@ func (_m *BlogRepository) GetCommentById(blogId string, commentId string) (domai
 	return r0, r1
 }
 
-// GetReplyById provides a mock function with given fields: blogId, commentId, replyId
-func (_m *BlogRepository) GetReplyById(blogId string, commentId string, replyId string) (domain.Reply, error) {
-	ret := _m.Called(blogId, commentId, replyId)
+// GetCommentThread provides a mock function with given fields: blogId, rootId, maxDepth
+func (_m *BlogRepository) GetCommentThread(blogId string, rootId string, maxDepth int) ([]domain.Comment, error) {
+	ret := _m.Called(blogId, rootId, maxDepth)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetReplyById")
+		panic("no return value specified for GetCommentThread")
 	}
 
-	var r0 domain.Reply
+	var r0 []domain.Comment
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, string) (domain.Reply, error)); ok {
-		return rf(blogId, commentId, replyId)
+	if rf, ok := ret.Get(0).(func(string, string, int) ([]domain.Comment, error)); ok {
+		return rf(blogId, rootId, maxDepth)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, string) domain.Reply); ok {
-		r0 = rf(blogId, commentId, replyId)
+	if rf, ok := ret.Get(0).(func(string, string, int) []domain.Comment); ok {
+		r0 = rf(blogId, rootId, maxDepth)
 	} else {
-		r0 = ret.Get(0).(domain.Reply)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Comment)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
-		r1 = rf(blogId, commentId, replyId)
+	if rf, ok := ret.Get(1).(func(string, string, int) error); ok {
+		r1 = rf(blogId, rootId, maxDepth)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -282,98 +400,138 @@ func (_m *BlogRepository) GetReplyById(blogId string, commentId string, replyId
 }
 
 // LikeOrDislikeBlog provides a mock function with given fields: blogId, userId, like
-func (_m *BlogRepository) LikeOrDislikeBlog(blogId string, userId string, like int) error {
+func (_m *BlogRepository) LikeOrDislikeBlog(blogId string, userId string, like int) (string, error) {
 	ret := _m.Called(blogId, userId, like)
 
 	if len(ret) == 0 {
 		panic("no return value specified for LikeOrDislikeBlog")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, int) error); ok {
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int) (string, error)); ok {
+		return rf(blogId, userId, like)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int) string); ok {
 		r0 = rf(blogId, userId, like)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(string)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(string, string, int) error); ok {
+		r1 = rf(blogId, userId, like)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // LikeOrDislikeComment provides a mock function with given fields: blogId, commentId, userId, like
-func (_m *BlogRepository) LikeOrDislikeComment(blogId string, commentId string, userId string, like int) error {
+func (_m *BlogRepository) LikeOrDislikeComment(blogId string, commentId string, userId string, like int) (bool, error) {
 	ret := _m.Called(blogId, commentId, userId, like)
 
 	if len(ret) == 0 {
 		panic("no return value specified for LikeOrDislikeComment")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, string, int) error); ok {
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, int) (bool, error)); ok {
+		return rf(blogId, commentId, userId, like)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, int) bool); ok {
 		r0 = rf(blogId, commentId, userId, like)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(bool)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(string, string, string, int) error); ok {
+		r1 = rf(blogId, commentId, userId, like)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// LikeOrDislikeReply provides a mock function with given fields: blogId, commentId, replyId, userId, like
-func (_m *BlogRepository) LikeOrDislikeReply(blogId string, commentId string, replyId string, userId string, like int) error {
-	ret := _m.Called(blogId, commentId, replyId, userId, like)
+// UpdateBlog provides a mock function with given fields: blogId, updateData
+func (_m *BlogRepository) UpdateBlog(blogId string, updateData domain.Blog) (domain.Blog, error) {
+	ret := _m.Called(blogId, updateData)
 
 	if len(ret) == 0 {
-		panic("no return value specified for LikeOrDislikeReply")
+		panic("no return value specified for UpdateBlog")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, string, string, int) error); ok {
-		r0 = rf(blogId, commentId, replyId, userId, like)
+	var r0 domain.Blog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, domain.Blog) (domain.Blog, error)); ok {
+		return rf(blogId, updateData)
+	}
+	if rf, ok := ret.Get(0).(func(string, domain.Blog) domain.Blog); ok {
+		r0 = rf(blogId, updateData)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(domain.Blog)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(string, domain.Blog) error); ok {
+		r1 = rf(blogId, updateData)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// ReplyToComment provides a mock function with given fields: blogId, commentId, reply
-func (_m *BlogRepository) ReplyToComment(blogId string, commentId string, reply domain.Reply) error {
-	ret := _m.Called(blogId, commentId, reply)
+// UpdateBlogWithContext provides a mock function with given fields: ctx, blogId, updateData
+func (_m *BlogRepository) UpdateBlogWithContext(ctx context.Context, blogId string, updateData domain.Blog) (domain.Blog, error) {
+	ret := _m.Called(ctx, blogId, updateData)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ReplyToComment")
+		panic("no return value specified for UpdateBlogWithContext")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, domain.Reply) error); ok {
-		r0 = rf(blogId, commentId, reply)
+	var r0 domain.Blog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Blog) (domain.Blog, error)); ok {
+		return rf(ctx, blogId, updateData)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Blog) domain.Blog); ok {
+		r0 = rf(ctx, blogId, updateData)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(domain.Blog)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.Blog) error); ok {
+		r1 = rf(ctx, blogId, updateData)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// UpdateBlog provides a mock function with given fields: blogId, updateData
-func (_m *BlogRepository) UpdateBlog(blogId string, updateData domain.Blog) (domain.Blog, error) {
-	ret := _m.Called(blogId, updateData)
+// UpdateComment provides a mock function with given fields: blogId, commentId, authorId, updateData
+func (_m *BlogRepository) UpdateComment(blogId string, commentId string, authorId string, updateData domain.Comment) (domain.Comment, error) {
+	ret := _m.Called(blogId, commentId, authorId, updateData)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateBlog")
+		panic("no return value specified for UpdateComment")
 	}
 
-	var r0 domain.Blog
+	var r0 domain.Comment
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, domain.Blog) (domain.Blog, error)); ok {
-		return rf(blogId, updateData)
+	if rf, ok := ret.Get(0).(func(string, string, string, domain.Comment) (domain.Comment, error)); ok {
+		return rf(blogId, commentId, authorId, updateData)
 	}
-	if rf, ok := ret.Get(0).(func(string, domain.Blog) domain.Blog); ok {
-		r0 = rf(blogId, updateData)
+	if rf, ok := ret.Get(0).(func(string, string, string, domain.Comment) domain.Comment); ok {
+		r0 = rf(blogId, commentId, authorId, updateData)
 	} else {
-		r0 = ret.Get(0).(domain.Blog)
+		r0 = ret.Get(0).(domain.Comment)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, domain.Blog) error); ok {
-		r1 = rf(blogId, updateData)
+	if rf, ok := ret.Get(1).(func(string, string, string, domain.Comment) error); ok {
+		r1 = rf(blogId, commentId, authorId, updateData)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -73,6 +73,46 @@ func (_m *GeneralAuthorizationController) OWNERMiddleware() gin.HandlerFunc {
 	return r0
 }
 
+// RequireOwnerOrPermission provides a mock function with given fields: permission
+func (_m *GeneralAuthorizationController) RequireOwnerOrPermission(permission string) gin.HandlerFunc {
+	ret := _m.Called(permission)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequireOwnerOrPermission")
+	}
+
+	var r0 gin.HandlerFunc
+	if rf, ok := ret.Get(0).(func(string) gin.HandlerFunc); ok {
+		r0 = rf(permission)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(gin.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
+// RequirePermission provides a mock function with given fields: permission
+func (_m *GeneralAuthorizationController) RequirePermission(permission string) gin.HandlerFunc {
+	ret := _m.Called(permission)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequirePermission")
+	}
+
+	var r0 gin.HandlerFunc
+	if rf, ok := ret.Get(0).(func(string) gin.HandlerFunc); ok {
+		r0 = rf(permission)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(gin.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
 // USERMiddleware provides a mock function with given fields:
 func (_m *GeneralAuthorizationController) USERMiddleware() gin.HandlerFunc {
 	ret := _m.Called()
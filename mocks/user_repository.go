@@ -3,8 +3,12 @@
 package mocks
 
 import (
+	context "context"
+
 	domain "github.com/yesetoda/BlogMate/domain"
 
+	time "time"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -13,6 +17,34 @@ type UserRepository struct {
 	mock.Mock
 }
 
+// BumpTokenVersion provides a mock function with given fields: userId
+func (_m *UserRepository) BumpTokenVersion(userId string) (int, error) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BumpTokenVersion")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (int, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(string) int); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: u
 func (_m *UserRepository) Create(u *domain.User) (domain.User, error) {
 	ret := _m.Called(u)
@@ -41,6 +73,34 @@ func (_m *UserRepository) Create(u *domain.User) (domain.User, error) {
 	return r0, r1
 }
 
+// CreateWithContext provides a mock function with given fields: ctx, u
+func (_m *UserRepository) CreateWithContext(ctx context.Context, u *domain.User) (domain.User, error) {
+	ret := _m.Called(ctx, u)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWithContext")
+	}
+
+	var r0 domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.User) (domain.User, error)); ok {
+		return rf(ctx, u)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.User) domain.User); ok {
+		r0 = rf(ctx, u)
+	} else {
+		r0 = ret.Get(0).(domain.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.User) error); ok {
+		r1 = rf(ctx, u)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: userId
 func (_m *UserRepository) Delete(userId string) error {
 	ret := _m.Called(userId)
@@ -89,6 +149,90 @@ func (_m *UserRepository) Get(opts domain.UserFilterOption) ([]domain.User, erro
 	return r0, r1
 }
 
+// SetEmail provides a mock function with given fields: userId, email
+func (_m *UserRepository) SetEmail(userId string, email string) (domain.User, error) {
+	ret := _m.Called(userId, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetEmail")
+	}
+
+	var r0 domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (domain.User, error)); ok {
+		return rf(userId, email)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) domain.User); ok {
+		r0 = rf(userId, email)
+	} else {
+		r0 = ret.Get(0).(domain.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(userId, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetRoles provides a mock function with given fields: userId, roles
+func (_m *UserRepository) SetRoles(userId string, roles []string) (domain.User, error) {
+	ret := _m.Called(userId, roles)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRoles")
+	}
+
+	var r0 domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []string) (domain.User, error)); ok {
+		return rf(userId, roles)
+	}
+	if rf, ok := ret.Get(0).(func(string, []string) domain.User); ok {
+		r0 = rf(userId, roles)
+	} else {
+		r0 = ret.Get(0).(domain.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []string) error); ok {
+		r1 = rf(userId, roles)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetUsername provides a mock function with given fields: userId, username, at
+func (_m *UserRepository) SetUsername(userId string, username string, at time.Time) (domain.User, error) {
+	ret := _m.Called(userId, username, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUsername")
+	}
+
+	var r0 domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) (domain.User, error)); ok {
+		return rf(userId, username, at)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) domain.User); ok {
+		r0 = rf(userId, username, at)
+	} else {
+		r0 = ret.Get(0).(domain.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, time.Time) error); ok {
+		r1 = rf(userId, username, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: userId, updateData
 func (_m *UserRepository) Update(userId string, updateData domain.User) (domain.User, error) {
 	ret := _m.Called(userId, updateData)
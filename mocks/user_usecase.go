@@ -31,6 +31,62 @@ func (_m *UserUsecase) AccountVerification(uemail string, confirmationToken stri
 	return r0
 }
 
+// Authenticate provides a mock function with given fields: uname, password, email
+func (_m *UserUsecase) Authenticate(uname string, password string, email string) (domain.User, error) {
+	ret := _m.Called(uname, password, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Authenticate")
+	}
+
+	var r0 domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string) (domain.User, error)); ok {
+		return rf(uname, password, email)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) domain.User); ok {
+		r0 = rf(uname, password, email)
+	} else {
+		r0 = ret.Get(0).(domain.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(uname, password, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ChangePassword provides a mock function with given fields: email, oldPassword, newPassword
+func (_m *UserUsecase) ChangePassword(email string, oldPassword string, newPassword string) (string, error) {
+	ret := _m.Called(email, oldPassword, newPassword)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangePassword")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string) (string, error)); ok {
+		return rf(email, oldPassword, newPassword)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) string); ok {
+		r0 = rf(email, oldPassword, newPassword)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(email, oldPassword, newPassword)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: u
 func (_m *UserUsecase) Create(u *domain.User) (domain.User, error) {
 	ret := _m.Called(u)
@@ -405,12 +461,12 @@ func (_m *UserUsecase) ResetPassword(email string, token string, password string
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: userId, updateData
-func (_m *UserUsecase) Update(userId string, updateData domain.User) (domain.User, error) {
+// UpdateUser provides a mock function with given fields: userId, updateData
+func (_m *UserUsecase) UpdateUser(userId string, updateData domain.User) (domain.User, error) {
 	ret := _m.Called(userId, updateData)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Update")
+		panic("no return value specified for UpdateUser")
 	}
 
 	var r0 domain.User
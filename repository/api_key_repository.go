@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type apiKeyRepository struct {
+	keys mongoifc.Collection
+}
+
+func NewAPIKeyRepository(keys mongoifc.Collection) domain.APIKeyRepository {
+	keys.Indexes().CreateOne(context.TODO(), mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}}})
+	return &apiKeyRepository{keys: keys}
+}
+
+func (repo *apiKeyRepository) CreateAPIKey(key domain.APIKey) error {
+	_, err := repo.keys.InsertOne(context.Background(), key)
+	return err
+}
+
+func (repo *apiKeyRepository) GetAPIKey(keyId string) (domain.APIKey, error) {
+	var key domain.APIKey
+	err := repo.keys.FindOne(context.Background(), bson.M{"_id": keyId}).Decode(&key)
+	return key, err
+}
+
+func (repo *apiKeyRepository) GetAPIKeys(userId string) ([]domain.APIKey, error) {
+	ctx := context.Background()
+	cursor, err := repo.keys.Find(ctx, bson.M{"user_id": userId}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	keys := []domain.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (repo *apiKeyRepository) DeleteAPIKey(userId string, keyId string) (bool, error) {
+	res, err := repo.keys.DeleteOne(context.Background(), bson.M{"_id": keyId, "user_id": userId})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+func (repo *apiKeyRepository) TouchAPIKey(keyId string, at time.Time, ip string) error {
+	_, err := repo.keys.UpdateOne(context.Background(), bson.M{"_id": keyId}, bson.M{"$set": bson.M{"last_used_at": at, "last_used_ip": ip}})
+	return err
+}
@@ -31,7 +31,6 @@ type BlogRespositoryTestSuite struct {
 	client         *mongomocks.Client
 	bcoll          *mongomocks.Collection
 	ccoll          *mongomocks.Collection
-	rcoll          *mongomocks.Collection
 	BlogRepository domain.BlogRepository
 }
 
@@ -39,13 +38,11 @@ func (suite *BlogRespositoryTestSuite) SetupSuite() {
 	suite.client = &mongomocks.Client{}
 	suite.bcoll = &mongomocks.Collection{}
 	suite.ccoll = &mongomocks.Collection{}
-	suite.rcoll = &mongomocks.Collection{}
-	suite.BlogRepository = NewBlogRepository(suite.client, suite.bcoll, suite.ccoll, suite.rcoll)
+	suite.BlogRepository = NewBlogRepository(suite.client, suite.bcoll, suite.ccoll)
 }
 
 func (suite *BlogRespositoryTestSuite) TearDownSuite() {
 	suite.bcoll.AssertExpectations(suite.T())
-	suite.rcoll.AssertExpectations(suite.T())
 	suite.ccoll.AssertExpectations(suite.T())
 }
 
@@ -135,3 +132,13 @@ func (suite *BlogRespositoryTestSuite) TestGet() {
 // func TestBlogRepository(t *testing.T) {
 // 	suite.Run(t, new(BlogRespositoryTestSuite))
 // }
+
+func TestBlogQueriesIncludeMedia(t *testing.T) {
+	query := CreateBlogQuery(domain.Blog{Title: "title", Content: "content", Media: []string{"m1", "m2"}})
+	assert.Equal(t, []string{"m1", "m2"}, query["media"])
+	assert.NotContains(t, CreateBlogQuery(domain.Blog{Title: "title"}), "media")
+
+	assert.Equal(t, []string{"m1"}, UpdateBlogQuery(domain.Blog{Media: []string{"m1"}})["media"])
+	assert.Equal(t, []string{}, UpdateBlogQuery(domain.Blog{Media: []string{}})["media"], "an empty list removes the media")
+	assert.NotContains(t, UpdateBlogQuery(domain.Blog{Title: "title"}), "media")
+}
@@ -18,15 +18,13 @@ type MongoBlogRepository struct {
 	client            mongoifc.Client
 	BlogCollection    mongoifc.Collection
 	CommentCollection mongoifc.Collection
-	ReplyCollection   mongoifc.Collection
 }
 
-func NewBlogRepository(client mongoifc.Client, Blog mongoifc.Collection, Comment mongoifc.Collection, Reply mongoifc.Collection) domain.BlogRepository {
+func NewBlogRepository(client mongoifc.Client, Blog mongoifc.Collection, Comment mongoifc.Collection) domain.BlogRepository {
 	return &MongoBlogRepository{
 		client:            client,
 		BlogCollection:    Blog,
 		CommentCollection: Comment,
-		ReplyCollection:   Reply,
 	}
 }
 
@@ -52,6 +50,10 @@ func CreateBlogQuery(b domain.Blog) bson.M {
 	}
 	query["date"] = b.Date
 	query["tags"] = b.Tags
+	if len(b.Media) > 0 {
+		query["media"] = b.Media
+	}
+	setBlogMetaFields(query, b)
 	query["likes"] = []string{}
 	query["dislikes"] = []string{}
 	query["comments"] = 0
@@ -61,12 +63,17 @@ func CreateBlogQuery(b domain.Blog) bson.M {
 }
 
 func (r *MongoBlogRepository) CreateBlog(b domain.Blog) (domain.Blog, error) {
+	return r.CreateBlogWithContext(context.Background(), b)
+}
+
+// CreateBlogWithContext creates the blog within the transaction carried by ctx, if any.
+func (r *MongoBlogRepository) CreateBlogWithContext(ctx context.Context, b domain.Blog) (domain.Blog, error) {
 	b.BlogId = primitive.NewObjectID().Hex()
 	b.Date = time.Now()
 
 	create := CreateBlogQuery(b)
 
-	_, err := r.BlogCollection.InsertOne(context.Background(), create)
+	_, err := r.BlogCollection.InsertOne(ctx, create)
 	if err != nil {
 		return domain.Blog{}, err
 	}
@@ -182,6 +189,22 @@ func (r *MongoBlogRepository) GetBlog(opts domain.BlogFilterOption) ([]domain.Bl
 	return blogs, nil
 }
 
+// setBlogMetaFields sets the cover, excerpt and SEO fields the blog has.
+func setBlogMetaFields(query bson.M, b domain.Blog) {
+	for field, value := range map[string]string{
+		"cover_image":     b.CoverImage,
+		"cover_media_id":  b.CoverMediaId,
+		"excerpt":         b.Excerpt,
+		"canonical_url":   b.CanonicalURL,
+		"seo_title":       b.SEOTitle,
+		"seo_description": b.SEODescription,
+	} {
+		if value != "" {
+			query[field] = value
+		}
+	}
+}
+
 func UpdateBlogQuery(b domain.Blog) bson.M {
 	update := bson.M{}
 	if b.Title != "" {
@@ -198,6 +221,10 @@ func UpdateBlogQuery(b domain.Blog) bson.M {
 			update["author_id"] = id
 		}
 	}
+	if b.Media != nil {
+		update["media"] = b.Media
+	}
+	setBlogMetaFields(update, b)
 	if len(b.Tags) > 0 {
 
 		update["tags"] = b.Tags
@@ -222,6 +249,12 @@ func UpdateBlogQuery(b domain.Blog) bson.M {
 }
 
 func (r *MongoBlogRepository) UpdateBlog(strBlogId string, updateData domain.Blog) (domain.Blog, error) {
+	return r.UpdateBlogWithContext(context.Background(), strBlogId, updateData)
+}
+
+// UpdateBlogWithContext updates the blog within the transaction carried by
+// ctx, if any, and returns it as stored after the update.
+func (r *MongoBlogRepository) UpdateBlogWithContext(ctx context.Context, strBlogId string, updateData domain.Blog) (domain.Blog, error) {
 	blogId, err := IsValidObjectID(strBlogId)
 	if err != nil {
 		return domain.Blog{}, err
@@ -229,29 +262,36 @@ func (r *MongoBlogRepository) UpdateBlog(strBlogId string, updateData domain.Blo
 	filter := bson.M{"blog_id": blogId}
 	update := bson.M{"$set": UpdateBlogQuery(updateData)}
 
-	result, err := r.BlogCollection.UpdateOne(context.Background(), filter, update)
-	if err != nil || result.MatchedCount == 0 {
+	var blog domain.Blog
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = r.BlogCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&blog)
+	if err != nil {
 		return domain.Blog{}, errors.New("Failed to update blog with ID" + strBlogId + ":" + err.Error())
 	}
 
-	return updateData, nil
+	return blog, nil
 }
 
 func (r *MongoBlogRepository) DeleteBlog(blogId, authorId string) error {
+	return r.DeleteBlogWithContext(context.Background(), blogId, authorId)
+}
+
+// DeleteBlogWithContext deletes the blog within the transaction carried by ctx, if any.
+func (r *MongoBlogRepository) DeleteBlogWithContext(ctx context.Context, blogId, authorId string) error {
 	id, err := IsValidObjectID(blogId)
 	if err != nil {
 		return err
 	}
 	filter := bson.M{"blog_id": id}
 	var blog domain.Blog
-	err = r.BlogCollection.FindOne(context.Background(), filter).Decode(&blog)
+	err = r.BlogCollection.FindOne(ctx, filter).Decode(&blog)
 	if err != nil {
 		return err
 	}
 	if blog.AuthorId != authorId {
 		return errors.New("unauthorized to delete this blog")
 	}
-	result, err := r.BlogCollection.DeleteOne(context.Background(), filter)
+	result, err := r.BlogCollection.DeleteOne(ctx, filter)
 	if err != nil || result.DeletedCount == 0 {
 		return errors.New("Failed to delete blog with ID" + blogId + ":" + err.Error())
 	}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// legacyReply is the shape of the documents of the old Replies collection.
+type legacyReply struct {
+	ReplyId   primitive.ObjectID `bson:"reply_id"`
+	AuthorId  primitive.ObjectID `bson:"author_id,omitempty"`
+	BlogId    primitive.ObjectID `bson:"blog_id"`
+	CommentId primitive.ObjectID `bson:"comment_id"`
+	Content   string             `bson:"content,omitempty"`
+	Likes     bson.A             `bson:"likes,omitempty"`
+	Dislikes  bson.A             `bson:"dislikes,omitempty"`
+	Views     int                `bson:"views,omitempty"`
+}
+
+// MigrateRepliesToComments turns the documents of the old Replies collection
+// into child comments and gives comments created before threads existed their
+// path and depth. Each reply keeps its id, so running the migration again is
+// harmless. It returns the number of migrated replies.
+func MigrateRepliesToComments(blogs, comments, replies mongoifc.Collection) (int, error) {
+	ctx := context.Background()
+	_, err := comments.UpdateMany(ctx, bson.M{"path": bson.M{"$exists": false}}, mongo.Pipeline{
+		bson.D{{Key: "$set", Value: bson.M{
+			"path":  bson.M{"$concat": bson.A{"/", bson.M{"$toString": "$comment_id"}, "/"}},
+			"depth": 0,
+		}}},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("backfilling comment paths: %w", err)
+	}
+
+	cursor, err := replies.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	for cursor.Next(ctx) {
+		var reply legacyReply
+		if err := cursor.Decode(&reply); err != nil {
+			return migrated, err
+		}
+		parentPath := "/" + reply.CommentId.Hex() + "/"
+		parentDepth := 0
+		var parent struct {
+			Path  string `bson:"path"`
+			Depth int    `bson:"depth"`
+		}
+		if err := comments.FindOne(ctx, bson.M{"comment_id": reply.CommentId}).Decode(&parent); err == nil && parent.Path != "" {
+			parentPath = parent.Path
+			parentDepth = parent.Depth
+		}
+		doc := bson.M{
+			"comment_id": reply.ReplyId,
+			"blog_id":    reply.BlogId,
+			"parent_id":  reply.CommentId,
+			"path":       parentPath + reply.ReplyId.Hex() + "/",
+			"depth":      parentDepth + 1,
+			"date":       reply.ReplyId.Timestamp(),
+			"content":    reply.Content,
+			"likes":      nonNil(reply.Likes),
+			"dislikes":   nonNil(reply.Dislikes),
+			"replies":    0,
+			"views":      reply.Views,
+		}
+		if !reply.AuthorId.IsZero() {
+			doc["author_id"] = reply.AuthorId
+		}
+		result, err := comments.UpdateOne(ctx, bson.M{"comment_id": reply.ReplyId}, bson.M{"$setOnInsert": doc}, options.Update().SetUpsert(true))
+		if err != nil {
+			return migrated, fmt.Errorf("migrating reply %s: %w", reply.ReplyId.Hex(), err)
+		}
+		if result.UpsertedCount > 0 {
+			// the parent already counted the reply, only the blog total changes
+			_, err = blogs.UpdateOne(ctx, bson.M{"blog_id": reply.BlogId}, bson.M{"$inc": bson.M{"comments": 1}})
+			if err != nil {
+				return migrated, err
+			}
+		}
+		if _, err := replies.DeleteOne(ctx, bson.M{"reply_id": reply.ReplyId}); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, cursor.Err()
+}
+
+func nonNil(a bson.A) bson.A {
+	if a == nil {
+		return bson.A{}
+	}
+	return a
+}
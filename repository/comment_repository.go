@@ -5,12 +5,45 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"time"
 
+	"github.com/sv-tools/mongoifc"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// withCommentDefaults fills the thread fields of comments that were stored
+// before threads existed.
+func withCommentDefaults(c domain.Comment) domain.Comment {
+	if c.Path == "" && c.CommentId != "" {
+		c.Path = "/" + c.CommentId + "/"
+	}
+	if c.Date.IsZero() {
+		if id, err := primitive.ObjectIDFromHex(c.CommentId); err == nil {
+			c.Date = id.Timestamp()
+		}
+	}
+	return c
+}
+
+func decodeComments(ctx context.Context, cursor mongoifc.Cursor) ([]domain.Comment, error) {
+	defer cursor.Close(ctx)
+	comments := []domain.Comment{}
+	for cursor.Next(ctx) {
+		var comment domain.Comment
+		if err := cursor.Decode(&comment); err != nil {
+			return nil, err
+		}
+		comments = append(comments, withCommentDefaults(comment))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
 func (r *MongoBlogRepository) GetCommentById(blogId, commentId string) (domain.Comment, error) {
 	bid, err := IsValidObjectID(blogId)
 	if err != nil {
@@ -24,104 +57,136 @@ func (r *MongoBlogRepository) GetCommentById(blogId, commentId string) (domain.C
 		"comment_id": cid,
 		"blog_id":    bid,
 	}
-	fmt.Println("this is the filter", filter)
 	var result domain.Comment
 	err = r.CommentCollection.FindOne(context.Background(), filter).Decode(&result)
 	if err != nil {
-		fmt.Println("this is the error", err)
 		return domain.Comment{}, err
 	}
-	return result, nil
+	return withCommentDefaults(result), nil
 }
 
-func (r *MongoBlogRepository) LikeOrDislikeComment(blogId, commentId, userId string, like int) error {
+// LikeOrDislikeComment counts a view of the comment and, for a like or a
+// dislike, records the reaction in place of the opposite one. It reports
+// whether the reaction was added, false when the user had already left it.
+func (r *MongoBlogRepository) LikeOrDislikeComment(blogId, commentId, userId string, like int) (bool, error) {
 	bid, err := IsValidObjectID(blogId)
 	if err != nil {
-		return err
+		return false, err
 	}
 	cid, err := IsValidObjectID(commentId)
 	if err != nil {
-		return err
+		return false, err
 	}
 	uid, err := IsValidObjectID(userId)
 	if err != nil {
-		return err
+		return false, err
 	}
 	filter := bson.M{"comment_id": cid, "blog_id": bid}
-	update := bson.M{}
-	if like == 1 {
-		result := bson.M{}
-		likeFinder := bson.M{"comment_id": cid, "dislikes": uid}
-		err := r.CommentCollection.FindOne(context.TODO(), likeFinder).Decode(&result)
-		update["$inc"] = bson.M{"views": 1}
-		if err == nil {
-			_, err = r.CommentCollection.UpdateOne(context.TODO(), filter, bson.M{
-				"$pull": bson.M{
-					"dislikes": uid,
-				},
-			})
-			if err != nil {
-				return err
-			}
+	added := false
+	if like == 1 || like == -1 {
+		reaction, opposite := "likes", "dislikes"
+		if like == -1 {
+			reaction, opposite = "dislikes", "likes"
 		}
-		update["$addToSet"] = bson.M{"likes": uid}
-	} else if like == -1 {
-		result := bson.M{}
-		likeFinder := bson.M{"comment_id": cid, "likes": uid}
-		err := r.CommentCollection.FindOne(context.TODO(), likeFinder).Decode(&result)
+		found := bson.M{}
+		oppositeFinder := bson.M{"comment_id": cid, opposite: uid}
+		err := r.CommentCollection.FindOne(context.TODO(), oppositeFinder).Decode(&found)
 		if err == nil {
 			_, err = r.CommentCollection.UpdateOne(context.TODO(), filter, bson.M{
 				"$pull": bson.M{
-					"likes": uid,
+					opposite: uid,
 				},
 			})
 			if err != nil {
-				return err
+				return false, err
 			}
 		}
-		update["$addToSet"] = bson.M{"dislikes": uid}
+		// only a comment without the reaction yet matches, so the result
+		// tells whether it was added
+		absent := bson.M{"comment_id": cid, "blog_id": bid, reaction: bson.M{"$ne": uid}}
+		result, err := r.CommentCollection.UpdateOne(context.TODO(), absent, bson.M{"$addToSet": bson.M{reaction: uid}})
+		if err != nil {
+			return false, err
+		}
+		added = result.ModifiedCount > 0
 	}
-	_, err = r.CommentCollection.UpdateOne(context.Background(), filter, update)
+	_, err = r.CommentCollection.UpdateOne(context.Background(), filter, bson.M{"$inc": bson.M{"views": 1}})
 	if err != nil {
-		return err
+		return false, err
 	}
-	return nil
+	return added, nil
 }
 
-func (r *MongoBlogRepository) GetAllComments(blogId string, opts domain.PaginationInfo) ([]domain.Comment, error) {
-	var comments []domain.Comment
+func paginationOptions(opts domain.PaginationInfo) *options.FindOptions {
 	findOptions := options.Find()
-	bid, err := IsValidObjectID(blogId)
-	if err != nil {
-		return comments, err
-	}
 	if opts.PageSize > 0 {
 		findOptions.SetLimit(int64(opts.PageSize))
 	}
 	if opts.Page > 0 {
 		findOptions.SetSkip(int64((opts.Page - 1) * opts.PageSize))
 	}
-	cursor, err := r.CommentCollection.Find(context.Background(), bson.M{"blog_id": bid}, findOptions)
+	return findOptions
+}
 
+// GetAllComments returns the top level comments of a blog.
+func (r *MongoBlogRepository) GetAllComments(blogId string, opts domain.PaginationInfo) ([]domain.Comment, error) {
+	bid, err := IsValidObjectID(blogId)
+	if err != nil {
+		return []domain.Comment{}, err
+	}
+	filter := bson.M{"blog_id": bid, "parent_id": bson.M{"$exists": false}}
+	cursor, err := r.CommentCollection.Find(context.Background(), filter, paginationOptions(opts))
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	return decodeComments(context.Background(), cursor)
+}
 
-	for cursor.Next(context.Background()) {
-		var comment domain.Comment
-		if err := cursor.Decode(&comment); err != nil {
-			return nil, err
-		}
-		comments = append(comments, comment)
+// GetChildComments returns the direct replies to a comment.
+func (r *MongoBlogRepository) GetChildComments(blogId, parentId string, opts domain.PaginationInfo) ([]domain.Comment, error) {
+	bid, err := IsValidObjectID(blogId)
+	if err != nil {
+		return []domain.Comment{}, err
 	}
-
-	if err := cursor.Err(); err != nil {
+	pid, err := IsValidObjectID(parentId)
+	if err != nil {
+		return []domain.Comment{}, err
+	}
+	filter := bson.M{"blog_id": bid, "parent_id": pid}
+	cursor, err := r.CommentCollection.Find(context.Background(), filter, paginationOptions(opts))
+	if err != nil {
 		return nil, err
 	}
+	return decodeComments(context.Background(), cursor)
+}
 
-	return comments, nil
+// GetCommentThread returns, as a flat list, every comment of a blog (or of the
+// subtree below rootId, root included) that is at most maxDepth levels deep.
+func (r *MongoBlogRepository) GetCommentThread(blogId, rootId string, maxDepth int) ([]domain.Comment, error) {
+	bid, err := IsValidObjectID(blogId)
+	if err != nil {
+		return []domain.Comment{}, err
+	}
+	filter := bson.M{"blog_id": bid}
+	baseDepth := 0
+	if rootId != "" {
+		root, err := r.GetCommentById(blogId, rootId)
+		if err != nil {
+			return []domain.Comment{}, err
+		}
+		filter["path"] = bson.M{"$regex": "^" + regexp.QuoteMeta(root.Path)}
+		baseDepth = root.Depth
+	}
+	if maxDepth >= 0 {
+		filter["depth"] = bson.M{"$lte": baseDepth + maxDepth}
+	}
+	cursor, err := r.CommentCollection.Find(context.Background(), filter, options.Find().SetSort(bson.D{{Key: "depth", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	return decodeComments(context.Background(), cursor)
 }
+
 func CreateCommentQuery(r domain.Comment) bson.M {
 
 	query := bson.M{}
@@ -134,6 +199,16 @@ func CreateCommentQuery(r domain.Comment) bson.M {
 	} else {
 		return bson.M{}
 	}
+	if r.ParentId != "" {
+		parentId, err := IsValidObjectID(r.ParentId)
+		if err != nil {
+			return bson.M{}
+		}
+		query["parent_id"] = parentId
+	}
+	query["path"] = r.Path
+	query["depth"] = r.Depth
+	query["date"] = r.Date
 	if r.Content != "" {
 		query["content"] = r.Content
 	}
@@ -150,37 +225,62 @@ func CreateCommentQuery(r domain.Comment) bson.M {
 	query["views"] = 0
 	return query
 }
-func (r *MongoBlogRepository) AddComment(sblogId string, comment domain.Comment) error {
+
+// AddComment stores a comment on a blog. When ParentId is set the comment is a
+// reply and is placed below its parent in the thread.
+func (r *MongoBlogRepository) AddComment(sblogId string, comment domain.Comment) (domain.Comment, error) {
+	return r.AddCommentWithContext(context.Background(), sblogId, comment)
+}
+
+// AddCommentWithContext stores the comment within the transaction carried by
+// ctx, if any.
+func (r *MongoBlogRepository) AddCommentWithContext(ctx context.Context, sblogId string, comment domain.Comment) (domain.Comment, error) {
 	blogId, err := IsValidObjectID(sblogId)
 	if err != nil {
-		return fmt.Errorf("invalid blog ID: %w", err)
+		return domain.Comment{}, fmt.Errorf("invalid blog ID: %w", err)
 	}
+	cid := primitive.NewObjectID()
 	comment.BlogId = blogId.Hex()
-	comment.CommentId = primitive.NewObjectID().Hex()
-	comm := CreateCommentQuery(comment)
-	fmt.Println("this is the comment", comment)
-	fmt.Println("this is the comment bson", comm)
+	comment.CommentId = cid.Hex()
+	comment.Date = time.Now()
+	comment.Depth = 0
+	comment.Path = "/" + comment.CommentId + "/"
 
-	_, err = r.CommentCollection.InsertOne(context.Background(), comm)
-	if err != nil {
-		fmt.Println("this is the error", err)
-		return fmt.Errorf("failed to insert comment: %w", err)
+	var parentId primitive.ObjectID
+	if comment.ParentId != "" {
+		parent, err := r.GetCommentById(sblogId, comment.ParentId)
+		if err != nil {
+			return domain.Comment{}, fmt.Errorf("parent comment not found: %w", err)
+		}
+		parentId, _ = IsValidObjectID(parent.CommentId)
+		comment.Depth = parent.Depth + 1
+		comment.Path = parent.Path + comment.CommentId + "/"
 	}
 
-	blogUpdate := bson.M{"$inc": bson.M{"comments": 1}}
-	_, err = r.BlogCollection.UpdateOne(context.Background(), bson.M{"blog_id": blogId}, blogUpdate)
+	_, err = r.CommentCollection.InsertOne(ctx, CreateCommentQuery(comment))
 	if err != nil {
-		fmt.Println("this is the error", err)
-		_, delErr := r.CommentCollection.DeleteOne(context.Background(), bson.M{"comment_id": comment.CommentId})
-		if delErr != nil {
-			fmt.Println("this is the error", delErr)
+		return domain.Comment{}, fmt.Errorf("failed to insert comment: %w", err)
+	}
 
-			return fmt.Errorf("failed to update blog and rollback comment insertion: %w", delErr)
+	rollback := func(cause error) (domain.Comment, error) {
+		_, delErr := r.CommentCollection.DeleteOne(ctx, bson.M{"comment_id": cid})
+		if delErr != nil {
+			return domain.Comment{}, fmt.Errorf("failed to update counters and rollback comment insertion: %w", delErr)
+		}
+		return domain.Comment{}, cause
+	}
+	if comment.ParentId != "" {
+		_, err = r.CommentCollection.UpdateOne(ctx, bson.M{"comment_id": parentId, "blog_id": blogId}, bson.M{"$inc": bson.M{"replies": 1}})
+		if err != nil {
+			return rollback(fmt.Errorf("failed to update parent comment: %w", err))
 		}
-		return fmt.Errorf("failed to update blog: %w", err)
+	}
+	_, err = r.BlogCollection.UpdateOne(ctx, bson.M{"blog_id": blogId}, bson.M{"$inc": bson.M{"comments": 1}})
+	if err != nil {
+		return rollback(fmt.Errorf("failed to update blog: %w", err))
 	}
 
-	return nil
+	return comment, nil
 }
 
 func UpdateCommentQuery(b domain.Comment) bson.M {
@@ -209,46 +309,70 @@ func (r *MongoBlogRepository) UpdateComment(blogId, commentId, authorId string,
 		return domain.Comment{}, err
 	}
 	if comment.AuthorId != authorId {
-		return domain.Comment{}, errors.New("unauthorized to delete this comment")
+		return domain.Comment{}, errors.New("unauthorized to update this comment")
 	}
 	result, err := r.CommentCollection.UpdateOne(context.Background(), filter, update)
-	if err != nil || result.MatchedCount == 0 {
+	if err != nil {
 		return domain.Comment{}, errors.New("Failed to update Comment with ID" + commentId + ":" + err.Error())
 	}
+	if result.MatchedCount == 0 {
+		return domain.Comment{}, errors.New("Failed to update Comment with ID" + commentId)
+	}
 
-	return updateData, nil
+	comment.Content = updateData.Content
+	return withCommentDefaults(comment), nil
 }
 
-func (r *MongoBlogRepository) DeleteComment(authorId string, blogId, scommentId string) error {
+// DeleteComment deletes the comment with its whole reply subtree and returns
+// the ids of the deleted comments.
+func (r *MongoBlogRepository) DeleteComment(blogId, scommentId, authorId string) ([]string, error) {
 	cid, err := IsValidObjectID(scommentId)
 	if err != nil {
-		return fmt.Errorf("invalid comment ID: %w", err)
+		return nil, fmt.Errorf("invalid comment ID: %w", err)
 	}
 	bid, err := IsValidObjectID(blogId)
 	if err != nil {
-		return fmt.Errorf("invalid comment ID: %w", err)
+		return nil, fmt.Errorf("invalid blog ID: %w", err)
 	}
-	filter := bson.M{"comment_id": cid, "blog_id": bid}
-	var comment domain.Comment
-	err = r.CommentCollection.FindOne(context.Background(), filter).Decode(&comment)
+	comment, err := r.GetCommentById(blogId, scommentId)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if comment.AuthorId != authorId {
-		return errors.New("unauthorized to delete this comment")
+		return nil, errors.New("unauthorized to delete this comment")
 	}
 
-	_, err = r.CommentCollection.DeleteOne(context.Background(), filter)
+	filter := bson.M{"blog_id": bid, "$or": bson.A{
+		bson.M{"comment_id": cid},
+		bson.M{"path": bson.M{"$regex": "^" + regexp.QuoteMeta(comment.Path)}},
+	}}
+	cursor, err := r.CommentCollection.Find(context.Background(), filter, options.Find().SetProjection(bson.M{"comment_id": 1}))
 	if err != nil {
-		return fmt.Errorf("failed to delete comment: %w", err)
-	} else {
-		commentUpdate := bson.M{"$inc": bson.M{"comments": -1}}
-		bid, _ := IsValidObjectID(comment.BlogId)
-		_, err = r.BlogCollection.UpdateOne(context.Background(), bson.M{"comment_id": bid}, commentUpdate)
+		return nil, fmt.Errorf("failed to find the replies of the comment: %w", err)
+	}
+	subtree, err := decodeComments(context.Background(), cursor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the replies of the comment: %w", err)
+	}
+	deleted := make([]string, 0, len(subtree))
+	for _, c := range subtree {
+		deleted = append(deleted, c.CommentId)
+	}
+	result, err := r.CommentCollection.DeleteMany(context.Background(), filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete comment: %w", err)
+	}
+	if comment.ParentId != "" {
+		pid, _ := IsValidObjectID(comment.ParentId)
+		_, err = r.CommentCollection.UpdateOne(context.Background(), bson.M{"comment_id": pid, "blog_id": bid}, bson.M{"$inc": bson.M{"replies": -1}})
 		if err != nil {
-			return fmt.Errorf("failed to update blog after comment deletion: %w", err)
+			return nil, fmt.Errorf("failed to update parent comment after deletion: %w", err)
 		}
 	}
+	_, err = r.BlogCollection.UpdateOne(context.Background(), bson.M{"blog_id": bid}, bson.M{"$inc": bson.M{"comments": -result.DeletedCount}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update blog after comment deletion: %w", err)
+	}
 
-	return nil
+	return deleted, nil
 }
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type digestRepository struct {
+	subscriptions mongoifc.Collection
+	logs          mongoifc.Collection
+}
+
+func NewDigestRepository(subscriptions, logs mongoifc.Collection) domain.DigestRepository {
+	subscriptions.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "unsubscribe_token", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"unsubscribe_token": bson.M{"$exists": true}}),
+	})
+	subscriptions.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "enabled", Value: 1}, {Key: "frequency", Value: 1}},
+	})
+	return &digestRepository{subscriptions: subscriptions, logs: logs}
+}
+
+func (repo *digestRepository) GetSubscription(userId string) (domain.DigestSubscription, error) {
+	subscription := domain.DigestSubscription{UserId: userId, Frequency: domain.DigestWeekly, Authors: []string{}, Tags: []string{}}
+	err := repo.subscriptions.FindOne(context.Background(), bson.M{"_id": userId}).Decode(&subscription)
+	if err == mongo.ErrNoDocuments {
+		return subscription, nil
+	}
+	if err != nil {
+		return domain.DigestSubscription{}, err
+	}
+	return subscription, nil
+}
+
+func (repo *digestRepository) GetSubscriptionByToken(token string) (domain.DigestSubscription, error) {
+	if token == "" {
+		return domain.DigestSubscription{}, errors.New("unsubscribe token is required")
+	}
+	var subscription domain.DigestSubscription
+	err := repo.subscriptions.FindOne(context.Background(), bson.M{"unsubscribe_token": token}).Decode(&subscription)
+	if err == mongo.ErrNoDocuments {
+		return domain.DigestSubscription{}, errors.New("invalid unsubscribe token")
+	}
+	if err != nil {
+		return domain.DigestSubscription{}, err
+	}
+	return subscription, nil
+}
+
+func (repo *digestRepository) SaveSubscription(subscription domain.DigestSubscription) (domain.DigestSubscription, error) {
+	_, err := repo.subscriptions.ReplaceOne(context.Background(), bson.M{"_id": subscription.UserId}, subscription, options.Replace().SetUpsert(true))
+	if err != nil {
+		return domain.DigestSubscription{}, err
+	}
+	return subscription, nil
+}
+
+func (repo *digestRepository) GetSubscriptions(frequency string) ([]domain.DigestSubscription, error) {
+	ctx := context.Background()
+	cursor, err := repo.subscriptions.Find(ctx, bson.M{"enabled": true, "frequency": frequency})
+	if err != nil {
+		return nil, err
+	}
+	subscriptions := []domain.DigestSubscription{}
+	if err := cursor.All(ctx, &subscriptions); err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (repo *digestRepository) LogSend(entry domain.DigestLog) (bool, error) {
+	_, err := repo.logs.InsertOne(context.Background(), entry)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (repo *digestRepository) RemoveLog(logId string) error {
+	_, err := repo.logs.DeleteOne(context.Background(), bson.M{"_id": logId})
+	return err
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type emailChangeRepository struct {
+	changes mongoifc.Collection
+}
+
+func NewEmailChangeRepository(changes mongoifc.Collection) domain.EmailChangeRepository {
+	// expired links are removed by MongoDB
+	changes.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return &emailChangeRepository{changes: changes}
+}
+
+func (repo *emailChangeRepository) CreateEmailChange(change domain.EmailChange) error {
+	_, err := repo.changes.InsertOne(context.Background(), change)
+	return err
+}
+
+func (repo *emailChangeRepository) TakeEmailChange(changeId, kind string) (domain.EmailChange, error) {
+	var change domain.EmailChange
+	err := repo.changes.FindOneAndDelete(context.Background(), bson.M{"_id": changeId, "kind": kind}).Decode(&change)
+	return change, err
+}
+
+func (repo *emailChangeRepository) DeleteEmailChanges(userId, kind string) error {
+	_, err := repo.changes.DeleteMany(context.Background(), bson.M{"user_id": userId, "kind": kind})
+	return err
+}
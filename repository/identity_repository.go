@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type identityRepository struct {
+	identities mongoifc.Collection
+	states     mongoifc.Collection
+}
+
+func NewIdentityRepository(identities mongoifc.Collection, states mongoifc.Collection) domain.IdentityRepository {
+	identities.Indexes().CreateOne(context.TODO(), mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}}})
+	// abandoned logins are removed by MongoDB
+	states.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return &identityRepository{identities: identities, states: states}
+}
+
+func identityId(provider, subject string) string {
+	return provider + "|" + subject
+}
+
+func (repo *identityRepository) GetIdentity(provider string, subject string) (domain.Identity, error) {
+	var identity domain.Identity
+	err := repo.identities.FindOne(context.Background(), bson.M{"_id": identityId(provider, subject)}).Decode(&identity)
+	return identity, err
+}
+
+func (repo *identityRepository) GetIdentities(userId string) ([]domain.Identity, error) {
+	ctx := context.Background()
+	cursor, err := repo.identities.Find(ctx, bson.M{"user_id": userId}, options.Find().SetSort(bson.D{{Key: "linked_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	identities := []domain.Identity{}
+	if err := cursor.All(ctx, &identities); err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (repo *identityRepository) SaveIdentity(identity domain.Identity) error {
+	identity.IdentityId = identityId(identity.Provider, identity.Subject)
+	_, err := repo.identities.ReplaceOne(context.Background(), bson.M{"_id": identity.IdentityId}, identity, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (repo *identityRepository) DeleteIdentity(userId string, provider string) (bool, error) {
+	res, err := repo.identities.DeleteMany(context.Background(), bson.M{"user_id": userId, "provider": provider})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+func (repo *identityRepository) SaveState(state domain.OIDCState) error {
+	_, err := repo.states.InsertOne(context.Background(), state)
+	return err
+}
+
+func (repo *identityRepository) TakeState(stateId string) (domain.OIDCState, error) {
+	var state domain.OIDCState
+	err := repo.states.FindOneAndDelete(context.Background(), bson.M{"_id": stateId}).Decode(&state)
+	return state, err
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type loginAttemptRepository struct {
+	attempts mongoifc.Collection
+}
+
+func NewLoginAttemptRepository(attempts mongoifc.Collection) domain.LoginAttemptRepository {
+	// forgotten failures are removed by MongoDB
+	attempts.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return &loginAttemptRepository{attempts: attempts}
+}
+
+func (repo *loginAttemptRepository) GetLoginAttempts(key string) (domain.LoginAttempts, error) {
+	var attempts domain.LoginAttempts
+	err := repo.attempts.FindOne(context.Background(), bson.M{"_id": key}).Decode(&attempts)
+	if err == mongo.ErrNoDocuments {
+		return domain.LoginAttempts{Key: key}, nil
+	}
+	return attempts, err
+}
+
+func (repo *loginAttemptRepository) AddLoginFailure(key string, at, expiresAt time.Time) (domain.LoginAttempts, error) {
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	update := bson.M{
+		"$inc": bson.M{"failures": 1},
+		"$set": bson.M{"last_failure_at": at, "expires_at": expiresAt},
+	}
+	var attempts domain.LoginAttempts
+	err := repo.attempts.FindOneAndUpdate(context.Background(), bson.M{"_id": key}, update, opts).Decode(&attempts)
+	return attempts, err
+}
+
+func (repo *loginAttemptRepository) LockLoginAttempts(key string, until time.Time) error {
+	_, err := repo.attempts.UpdateOne(context.Background(), bson.M{"_id": key}, bson.M{"$set": bson.M{"locked_until": until}})
+	return err
+}
+
+func (repo *loginAttemptRepository) DeleteLoginAttempts(key string) error {
+	_, err := repo.attempts.DeleteOne(context.Background(), bson.M{"_id": key})
+	return err
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type magicLinkRepository struct {
+	links mongoifc.Collection
+}
+
+func NewMagicLinkRepository(links mongoifc.Collection) domain.MagicLinkRepository {
+	// expired links are removed by MongoDB
+	links.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return &magicLinkRepository{links: links}
+}
+
+func (repo *magicLinkRepository) CreateMagicLink(link domain.MagicLink) error {
+	_, err := repo.links.InsertOne(context.Background(), link)
+	return err
+}
+
+func (repo *magicLinkRepository) TakeMagicLink(linkId string) (domain.MagicLink, error) {
+	var link domain.MagicLink
+	err := repo.links.FindOneAndDelete(context.Background(), bson.M{"_id": linkId}).Decode(&link)
+	return link, err
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mediaRepository struct {
+	media mongoifc.Collection
+}
+
+func NewMediaRepository(media mongoifc.Collection) domain.MediaRepository {
+	media.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.M{"owner_id": 1}},
+		{Keys: bson.M{"references": 1}},
+	})
+	return &mediaRepository{media: media}
+}
+
+func (repo *mediaRepository) CreateMedia(media domain.Media) error {
+	if media.References == nil {
+		media.References = []string{}
+	}
+	_, err := repo.media.InsertOne(context.Background(), media)
+	return err
+}
+
+func (repo *mediaRepository) GetMedia(mediaId string) (domain.Media, error) {
+	var media domain.Media
+	err := repo.media.FindOne(context.Background(), bson.M{"_id": mediaId}).Decode(&media)
+	return media, err
+}
+
+func (repo *mediaRepository) GetUserMedia(ownerId string) ([]domain.Media, error) {
+	return repo.find(bson.M{"owner_id": ownerId}, options.Find().SetSort(bson.M{"created_at": -1}))
+}
+
+func (repo *mediaRepository) GetUsage(ownerId string) (int64, error) {
+	cursor, err := repo.media.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"owner_id": ownerId}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "used": bson.M{"$sum": bson.M{"$add": bson.A{"$size", bson.M{"$ifNull": bson.A{"$thumbnail_size", 0}}}}}}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var usage []struct {
+		Used int64 `bson:"used"`
+	}
+	if err := cursor.All(context.Background(), &usage); err != nil || len(usage) == 0 {
+		return 0, err
+	}
+	return usage[0].Used, nil
+}
+
+func (repo *mediaRepository) SetReferences(reference string, mediaIds []string) error {
+	if mediaIds == nil {
+		mediaIds = []string{}
+	}
+	_, err := repo.media.UpdateMany(context.Background(), bson.M{"references": reference, "_id": bson.M{"$nin": mediaIds}}, bson.M{"$pull": bson.M{"references": reference}})
+	if err != nil || len(mediaIds) == 0 {
+		return err
+	}
+	_, err = repo.media.UpdateMany(context.Background(), bson.M{"_id": bson.M{"$in": mediaIds}}, bson.M{"$addToSet": bson.M{"references": reference}})
+	return err
+}
+
+func (repo *mediaRepository) GetOrphans(before time.Time) ([]domain.Media, error) {
+	return repo.find(bson.M{"references": bson.M{"$size": 0}, "created_at": bson.M{"$lt": before}}, options.Find())
+}
+
+func (repo *mediaRepository) DeleteMedia(mediaId string) error {
+	_, err := repo.media.DeleteOne(context.Background(), bson.M{"_id": mediaId})
+	return err
+}
+
+func (repo *mediaRepository) find(filter bson.M, opts *options.FindOptions) ([]domain.Media, error) {
+	cursor, err := repo.media.Find(context.Background(), filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	media := []domain.Media{}
+	err = cursor.All(context.Background(), &media)
+	return media, err
+}
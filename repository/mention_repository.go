@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mentionRepository struct {
+	collection mongoifc.Collection
+}
+
+func NewMentionRepository(c mongoifc.Collection) domain.MentionRepository {
+	c.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "mentioned_user_id", Value: 1}, {Key: "date", Value: -1}},
+	})
+	c.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "blog_id", Value: 1}, {Key: "comment_id", Value: 1}},
+	})
+	return &mentionRepository{collection: c}
+}
+
+func mentionSourceFilter(blogId, commentId string) bson.M {
+	if commentId == "" {
+		return bson.M{"blog_id": blogId, "comment_id": bson.M{"$exists": false}}
+	}
+	return bson.M{"blog_id": blogId, "comment_id": commentId}
+}
+
+func (repo *mentionRepository) ReplaceMentions(blogId, commentId string, mentions []domain.Mention) ([]domain.Mention, error) {
+	ctx := context.Background()
+	filter := mentionSourceFilter(blogId, commentId)
+	cursor, err := repo.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	existing := []domain.Mention{}
+	if err := cursor.All(ctx, &existing); err != nil {
+		return nil, err
+	}
+	stored := map[string]bool{}
+	for _, m := range existing {
+		stored[m.MentionedUserId] = true
+	}
+
+	keep := []string{}
+	added := []domain.Mention{}
+	for _, m := range mentions {
+		keep = append(keep, m.MentionedUserId)
+		if stored[m.MentionedUserId] {
+			continue
+		}
+		m.MentionId = primitive.NewObjectID().Hex()
+		m.BlogId = blogId
+		m.CommentId = commentId
+		if m.Date.IsZero() {
+			m.Date = time.Now()
+		}
+		if _, err := repo.collection.InsertOne(ctx, m); err != nil {
+			return added, err
+		}
+		stored[m.MentionedUserId] = true
+		added = append(added, m)
+	}
+
+	filter["mentioned_user_id"] = bson.M{"$nin": keep}
+	if _, err := repo.collection.DeleteMany(ctx, filter); err != nil {
+		return added, err
+	}
+	return added, nil
+}
+
+func (repo *mentionRepository) GetMentionsForUser(userId string, opts domain.PaginationInfo) ([]domain.Mention, error) {
+	ctx := context.Background()
+	findOptions := paginationOptions(opts).SetSort(bson.D{{Key: "date", Value: -1}})
+	cursor, err := repo.collection.Find(ctx, bson.M{"mentioned_user_id": userId}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	mentions := []domain.Mention{}
+	if err := cursor.All(ctx, &mentions); err != nil {
+		return nil, err
+	}
+	return mentions, nil
+}
+
+func (repo *mentionRepository) DeleteMentions(blogId, commentId string) error {
+	filter := bson.M{"blog_id": blogId}
+	if commentId != "" {
+		filter["comment_id"] = commentId
+	}
+	_, err := repo.collection.DeleteMany(context.Background(), filter, options.Delete())
+	return err
+}
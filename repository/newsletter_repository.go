@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type newsletterRepository struct {
+	collection mongoifc.Collection
+}
+
+func NewNewsletterRepository(collection mongoifc.Collection) domain.NewsletterRepository {
+	collection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "email", Value: 1}, {Key: "kind", Value: 1}, {Key: "target", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	collection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "kind", Value: 1}, {Key: "target", Value: 1}, {Key: "status", Value: 1}},
+	})
+	return &newsletterRepository{collection: collection}
+}
+
+func (repo *newsletterRepository) GetSubscribers(filter domain.NewsletterFilter) ([]domain.NewsletterSubscriber, error) {
+	query := bson.M{}
+	for field, value := range map[string]string{
+		"email":             filter.Email,
+		"kind":              filter.Kind,
+		"target":            filter.Target,
+		"status":            filter.Status,
+		"confirm_token":     filter.ConfirmToken,
+		"unsubscribe_token": filter.UnsubscribeToken,
+	} {
+		if value != "" {
+			query[field] = value
+		}
+	}
+	return repo.find(query, paginationOptions(filter.Pagination))
+}
+
+func (repo *newsletterRepository) GetSubscribersForBlog(authorId string, tags []string) ([]domain.NewsletterSubscriber, error) {
+	targets := bson.A{bson.M{"kind": domain.NewsletterAuthor, "target": authorId}}
+	if len(tags) > 0 {
+		targets = append(targets, bson.M{"kind": domain.NewsletterTag, "target": bson.M{"$in": tags}})
+	}
+	return repo.find(bson.M{"status": domain.SubscriberConfirmed, "$or": targets}, options.Find())
+}
+
+func (repo *newsletterRepository) find(query bson.M, findOptions *options.FindOptions) ([]domain.NewsletterSubscriber, error) {
+	ctx := context.Background()
+	cursor, err := repo.collection.Find(ctx, query, findOptions.SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	subscribers := []domain.NewsletterSubscriber{}
+	if err := cursor.All(ctx, &subscribers); err != nil {
+		return nil, err
+	}
+	return subscribers, nil
+}
+
+func (repo *newsletterRepository) SaveSubscriber(subscriber domain.NewsletterSubscriber) (domain.NewsletterSubscriber, error) {
+	if subscriber.SubscriberId == "" {
+		subscriber.SubscriberId = primitive.NewObjectID().Hex()
+	}
+	if subscriber.CreatedAt.IsZero() {
+		subscriber.CreatedAt = time.Now()
+	}
+	_, err := repo.collection.ReplaceOne(context.Background(), bson.M{"_id": subscriber.SubscriberId}, subscriber, options.Replace().SetUpsert(true))
+	if err != nil {
+		return domain.NewsletterSubscriber{}, err
+	}
+	return subscriber, nil
+}
+
+func (repo *newsletterRepository) DeleteSubscriber(subscriberId string) error {
+	_, err := repo.collection.DeleteOne(context.Background(), bson.M{"_id": subscriberId})
+	return err
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type notificationRepository struct {
+	notifications mongoifc.Collection
+	preferences   mongoifc.Collection
+}
+
+func NewNotificationRepository(notifications, preferences mongoifc.Collection) domain.NotificationRepository {
+	notifications.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "read", Value: 1}, {Key: "date", Value: -1}},
+	})
+	notifications.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "key", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"key": bson.M{"$exists": true}}),
+	})
+	return &notificationRepository{notifications: notifications, preferences: preferences}
+}
+
+func (repo *notificationRepository) CreateNotification(n domain.Notification) (domain.Notification, error) {
+	ctx := context.Background()
+	if n.Date.IsZero() {
+		n.Date = time.Now()
+	}
+	n.Read = false
+	if n.Key == "" {
+		if n.NotificationId == "" {
+			n.NotificationId = primitive.NewObjectID().Hex()
+		}
+		_, err := repo.notifications.InsertOne(ctx, n)
+		if mongo.IsDuplicateKeyError(err) {
+			// already stored by an earlier delivery of the same notification
+			return n, nil
+		}
+		if err != nil {
+			return domain.Notification{}, err
+		}
+		return n, nil
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"actor_id":   n.ActorId,
+			"type":       n.Type,
+			"blog_id":    n.BlogId,
+			"comment_id": n.CommentId,
+			"message":    n.Message,
+			"read":       false,
+			"date":       n.Date,
+		},
+		"$setOnInsert": bson.M{"_id": notificationId(n)},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var stored domain.Notification
+	err := repo.notifications.FindOneAndUpdate(ctx, bson.M{"user_id": n.UserId, "key": n.Key}, update, opts).Decode(&stored)
+	if err != nil {
+		return domain.Notification{}, err
+	}
+	return stored, nil
+}
+
+func notificationId(n domain.Notification) string {
+	if n.NotificationId != "" {
+		return n.NotificationId
+	}
+	return primitive.NewObjectID().Hex()
+}
+
+func (repo *notificationRepository) GetNotifications(userId string, opts domain.NotificationFilterOption) ([]domain.Notification, error) {
+	ctx := context.Background()
+	filter := bson.M{"user_id": userId}
+	if opts.UnreadOnly {
+		filter["read"] = false
+	}
+	findOptions := paginationOptions(opts.Pagination).SetSort(bson.D{{Key: "date", Value: -1}})
+	cursor, err := repo.notifications.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	notifications := []domain.Notification{}
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (repo *notificationRepository) CountUnread(userId string) (int64, error) {
+	return repo.notifications.CountDocuments(context.Background(), bson.M{"user_id": userId, "read": false})
+}
+
+func (repo *notificationRepository) MarkRead(userId string, notificationIds []string) (int64, error) {
+	filter := bson.M{"user_id": userId, "read": false}
+	if len(notificationIds) > 0 {
+		filter["_id"] = bson.M{"$in": notificationIds}
+	}
+	result, err := repo.notifications.UpdateMany(context.Background(), filter, bson.M{"$set": bson.M{"read": true}})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func (repo *notificationRepository) GetPreferences(userId string) (domain.NotificationPreferences, error) {
+	prefs := domain.NotificationPreferences{UserId: userId, Events: map[string]bool{}}
+	err := repo.preferences.FindOne(context.Background(), bson.M{"_id": userId}).Decode(&prefs)
+	if err == mongo.ErrNoDocuments {
+		return prefs, nil
+	}
+	if err != nil {
+		return domain.NotificationPreferences{}, err
+	}
+	if prefs.Events == nil {
+		prefs.Events = map[string]bool{}
+	}
+	return prefs, nil
+}
+
+func (repo *notificationRepository) UpdatePreferences(prefs domain.NotificationPreferences) (domain.NotificationPreferences, error) {
+	set := bson.M{}
+	for eventType, enabled := range prefs.Events {
+		set["events."+eventType] = enabled
+	}
+	if len(set) > 0 {
+		_, err := repo.preferences.UpdateOne(context.Background(), bson.M{"_id": prefs.UserId}, bson.M{"$set": set}, options.Update().SetUpsert(true))
+		if err != nil {
+			return domain.NotificationPreferences{}, err
+		}
+	}
+	return repo.GetPreferences(prefs.UserId)
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type outboxRepository struct {
+	collection mongoifc.Collection
+}
+
+func NewOutboxRepository(c mongoifc.Collection) domain.OutboxRepository {
+	c.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "idempotency_key", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	c.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt", Value: 1}},
+	})
+	return &outboxRepository{collection: c}
+}
+
+func (repo *outboxRepository) Enqueue(ctx context.Context, messages []domain.OutboxMessage) error {
+	for _, m := range messages {
+		if m.IdempotencyKey == "" {
+			return errors.New("outbox messages need an idempotency key")
+		}
+		if m.CreatedAt.IsZero() {
+			m.CreatedAt = time.Now()
+		}
+		if m.NextAttempt.IsZero() {
+			m.NextAttempt = m.CreatedAt
+		}
+		doc := bson.M{
+			"_id":          primitive.NewObjectID().Hex(),
+			"kind":         m.Kind,
+			"payload":      m.Payload,
+			"status":       domain.OutboxPending,
+			"attempts":     0,
+			"next_attempt": m.NextAttempt,
+			"created_at":   m.CreatedAt,
+		}
+		// an upsert instead of an insert, a duplicate key error would abort
+		// the surrounding transaction
+		_, err := repo.collection.UpdateOne(ctx, bson.M{"idempotency_key": m.IdempotencyKey}, bson.M{"$setOnInsert": doc}, options.Update().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *outboxRepository) Claim(now time.Time, lockFor time.Duration, limit int) ([]domain.OutboxMessage, error) {
+	ctx := context.Background()
+	filter := bson.M{
+		"status":       domain.OutboxPending,
+		"next_attempt": bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"locked_until": bson.M{"$exists": false}},
+			bson.M{"locked_until": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"locked_until": now.Add(lockFor)}}
+	opts := options.FindOneAndUpdate().SetSort(bson.D{{Key: "next_attempt", Value: 1}}).SetReturnDocument(options.After)
+	claimed := []domain.OutboxMessage{}
+	for len(claimed) < limit {
+		var m domain.OutboxMessage
+		err := repo.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&m)
+		if err == mongo.ErrNoDocuments {
+			break
+		}
+		if err != nil {
+			return claimed, err
+		}
+		claimed = append(claimed, m)
+	}
+	return claimed, nil
+}
+
+func (repo *outboxRepository) MarkDelivered(messageId string, at time.Time) error {
+	update := bson.M{
+		"$set":   bson.M{"status": domain.OutboxDelivered, "delivered_at": at},
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"locked_until": "", "last_error": ""},
+	}
+	_, err := repo.collection.UpdateOne(context.Background(), bson.M{"_id": messageId}, update)
+	return err
+}
+
+func (repo *outboxRepository) MarkFailed(messageId, lastError string, nextAttempt time.Time, dead bool) error {
+	status := domain.OutboxPending
+	if dead {
+		status = domain.OutboxDead
+	}
+	update := bson.M{
+		"$set":   bson.M{"status": status, "last_error": lastError, "next_attempt": nextAttempt},
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"locked_until": ""},
+	}
+	_, err := repo.collection.UpdateOne(context.Background(), bson.M{"_id": messageId}, update)
+	return err
+}
+
+func (repo *outboxRepository) GetMessages(status string, opts domain.PaginationInfo) ([]domain.OutboxMessage, error) {
+	ctx := context.Background()
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	findOptions := paginationOptions(opts).SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := repo.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	messages := []domain.OutboxMessage{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (repo *outboxRepository) Requeue(messageId string, at time.Time) error {
+	update := bson.M{
+		"$set":   bson.M{"status": domain.OutboxPending, "attempts": 0, "next_attempt": at},
+		"$unset": bson.M{"locked_until": ""},
+	}
+	result, err := repo.collection.UpdateOne(context.Background(), bson.M{"_id": messageId, "status": domain.OutboxDead}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("there is no dead outbox message with the given id")
+	}
+	return nil
+}
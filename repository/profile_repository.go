@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type profileRepository struct {
+	profiles mongoifc.Collection
+}
+
+func NewProfileRepository(profiles mongoifc.Collection) domain.ProfileRepository {
+	return &profileRepository{profiles: profiles}
+}
+
+func (repo *profileRepository) GetProfile(userId string) (domain.Profile, error) {
+	var profile domain.Profile
+	err := repo.profiles.FindOne(context.Background(), bson.M{"_id": userId}).Decode(&profile)
+	if err == mongo.ErrNoDocuments {
+		return domain.Profile{UserId: userId}, nil
+	}
+	return profile, err
+}
+
+func (repo *profileRepository) SaveProfile(profile domain.Profile) error {
+	_, err := repo.profiles.ReplaceOne(context.Background(), bson.M{"_id": profile.UserId}, profile, options.Replace().SetUpsert(true))
+	return err
+}
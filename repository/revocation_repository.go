@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type revocationRepository struct {
+	collection mongoifc.Collection
+}
+
+func NewRevocationRepository(collection mongoifc.Collection) domain.RevocationRepository {
+	// revoked tokens are removed by MongoDB once they would have expired anyway
+	collection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return &revocationRepository{collection: collection}
+}
+
+func (repo *revocationRepository) RevokeToken(token domain.RevokedToken) error {
+	_, err := repo.collection.ReplaceOne(context.Background(), bson.M{"_id": token.TokenId}, token, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (repo *revocationRepository) GetRevokedTokens(now time.Time) ([]domain.RevokedToken, error) {
+	ctx := context.Background()
+	cursor, err := repo.collection.Find(ctx, bson.M{"expires_at": bson.M{"$gt": now}})
+	if err != nil {
+		return nil, err
+	}
+	tokens := []domain.RevokedToken{}
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MigrateAdminRoles gives the admin role to the admins made before roles
+// existed. Running it again is harmless. It returns the number of migrated
+// admins.
+func MigrateAdminRoles(users mongoifc.Collection) (int, error) {
+	res, err := users.UpdateMany(context.Background(),
+		bson.M{"isadmin": true, "roles": bson.M{"$ne": domain.RoleAdmin}},
+		bson.M{"$addToSet": bson.M{"roles": domain.RoleAdmin}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("migrating admins to the admin role: %w", err)
+	}
+	return int(res.ModifiedCount), nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type roleRepository struct {
+	roles mongoifc.Collection
+}
+
+func NewRoleRepository(roles mongoifc.Collection) domain.RoleRepository {
+	return &roleRepository{roles: roles}
+}
+
+func (repo *roleRepository) GetRoles() ([]domain.Role, error) {
+	ctx := context.Background()
+	cursor, err := repo.roles.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	roles := []domain.Role{}
+	if err := cursor.All(ctx, &roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (repo *roleRepository) SaveRole(role domain.Role) error {
+	_, err := repo.roles.ReplaceOne(context.Background(), bson.M{"_id": role.Name}, role, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (repo *roleRepository) DeleteRole(name string) (bool, error) {
+	res, err := repo.roles.DeleteOne(context.Background(), bson.M{"_id": name})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
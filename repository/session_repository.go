@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// sessionUsedHashes is how many replaced refresh tokens a session remembers
+// to detect their reuse.
+const sessionUsedHashes = 50
+
+type sessionRepository struct {
+	collection mongoifc.Collection
+}
+
+func NewSessionRepository(collection mongoifc.Collection) domain.SessionRepository {
+	collection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "token_hash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	collection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "used_hashes", Value: 1}},
+	})
+	collection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_seen", Value: -1}},
+	})
+	return &sessionRepository{collection: collection}
+}
+
+func (repo *sessionRepository) CreateSession(session domain.Session) (domain.Session, error) {
+	if session.SessionId == "" {
+		session.SessionId = primitive.NewObjectID().Hex()
+	}
+	if session.UsedHashes == nil {
+		session.UsedHashes = []string{}
+	}
+	_, err := repo.collection.InsertOne(context.Background(), session)
+	if err != nil {
+		return domain.Session{}, err
+	}
+	return session, nil
+}
+
+func (repo *sessionRepository) FindSession(tokenHash string) (domain.Session, error) {
+	filter := bson.M{"$or": bson.A{bson.M{"token_hash": tokenHash}, bson.M{"used_hashes": tokenHash}}}
+	var session domain.Session
+	err := repo.collection.FindOne(context.Background(), filter).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		return domain.Session{}, errors.New("session not found")
+	}
+	if err != nil {
+		return domain.Session{}, err
+	}
+	return session, nil
+}
+
+func (repo *sessionRepository) RotateSession(sessionId, oldHash, newHash, device, ip string, lastSeen time.Time) (bool, error) {
+	filter := bson.M{"_id": sessionId, "token_hash": oldHash, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{
+		"$set": bson.M{"token_hash": newHash, "device": device, "ip": ip, "last_seen": lastSeen},
+		"$push": bson.M{"used_hashes": bson.M{
+			"$each":  bson.A{oldHash},
+			"$slice": -sessionUsedHashes,
+		}},
+	}
+	result, err := repo.collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (repo *sessionRepository) GetSessions(userId string) ([]domain.Session, error) {
+	ctx := context.Background()
+	cursor, err := repo.collection.Find(ctx, bson.M{"user_id": userId}, options.Find().SetSort(bson.D{{Key: "last_seen", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	sessions := []domain.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (repo *sessionRepository) RevokeSession(userId, sessionId, reason string, at time.Time) (bool, error) {
+	filter := bson.M{"_id": sessionId, "user_id": userId, "revoked_at": bson.M{"$exists": false}}
+	result, err := repo.collection.UpdateOne(context.Background(), filter, bson.M{"$set": bson.M{"revoked_at": at, "revoked_reason": reason}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (repo *sessionRepository) RevokeUserSessions(userId, reason string, at time.Time) (int64, error) {
+	filter := bson.M{"user_id": userId, "revoked_at": bson.M{"$exists": false}}
+	result, err := repo.collection.UpdateMany(context.Background(), filter, bson.M{"$set": bson.M{"revoked_at": at, "revoked_reason": reason}})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type signingKeyRepository struct {
+	collection mongoifc.Collection
+}
+
+func NewSigningKeyRepository(collection mongoifc.Collection) domain.SigningKeyRepository {
+	return &signingKeyRepository{collection: collection}
+}
+
+func (repo *signingKeyRepository) GetSigningKeys(now time.Time) ([]domain.SigningKey, error) {
+	ctx := context.Background()
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := repo.collection.Find(ctx, bson.M{"expires_at": bson.M{"$gt": now}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	keys := []domain.SigningKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (repo *signingKeyRepository) SaveSigningKey(key domain.SigningKey) error {
+	_, err := repo.collection.ReplaceOne(context.Background(), bson.M{"_id": key.KeyId}, key, options.Replace().SetUpsert(true))
+	return err
+}
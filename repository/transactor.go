@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync/atomic"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// illegalOperation is the error code of a standalone server asked to start a
+// transaction.
+const illegalOperation = 20
+
+type mongoTransactor struct {
+	client      mongoifc.Client
+	unsupported atomic.Bool
+}
+
+// NewTransactor runs transactions on the given client. On a standalone server,
+// which has no transactions, the writes are made one after the other.
+func NewTransactor(client mongoifc.Client) domain.Transactor {
+	return &mongoTransactor{client: client}
+}
+
+func (t *mongoTransactor) WithTransaction(fn func(ctx context.Context) error) error {
+	ctx := context.Background()
+	if t.unsupported.Load() {
+		return fn(ctx)
+	}
+	session, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sc mongoifc.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && serverErr.HasErrorCode(illegalOperation) {
+		log.Println("the database does not support transactions, outbox messages are written without one")
+		t.unsupported.Store(true)
+		return fn(ctx)
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type twoFactorRepository struct {
+	twoFactors mongoifc.Collection
+	challenges mongoifc.Collection
+}
+
+func NewTwoFactorRepository(twoFactors mongoifc.Collection, challenges mongoifc.Collection) domain.TwoFactorRepository {
+	// expired challenges are removed by MongoDB
+	challenges.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return &twoFactorRepository{twoFactors: twoFactors, challenges: challenges}
+}
+
+func (repo *twoFactorRepository) GetTwoFactor(userId string) (domain.TwoFactor, error) {
+	var twoFactor domain.TwoFactor
+	err := repo.twoFactors.FindOne(context.Background(), bson.M{"_id": userId}).Decode(&twoFactor)
+	if err == mongo.ErrNoDocuments {
+		return domain.TwoFactor{UserId: userId}, nil
+	}
+	return twoFactor, err
+}
+
+func (repo *twoFactorRepository) SaveTwoFactor(twoFactor domain.TwoFactor) error {
+	_, err := repo.twoFactors.ReplaceOne(context.Background(), bson.M{"_id": twoFactor.UserId}, twoFactor, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (repo *twoFactorRepository) DeleteTwoFactor(userId string) error {
+	_, err := repo.twoFactors.DeleteOne(context.Background(), bson.M{"_id": userId})
+	return err
+}
+
+func (repo *twoFactorRepository) UseStep(userId string, step int64) (bool, error) {
+	res, err := repo.twoFactors.UpdateOne(context.Background(),
+		bson.M{"_id": userId, "last_step": bson.M{"$lt": step}},
+		bson.M{"$set": bson.M{"last_step": step}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (repo *twoFactorRepository) UseRecoveryCode(userId string, hash string) (bool, error) {
+	res, err := repo.twoFactors.UpdateOne(context.Background(),
+		bson.M{"_id": userId, "recovery_codes": hash},
+		bson.M{"$pull": bson.M{"recovery_codes": hash}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (repo *twoFactorRepository) CreateChallenge(challenge domain.LoginChallenge) error {
+	_, err := repo.challenges.InsertOne(context.Background(), challenge)
+	return err
+}
+
+func (repo *twoFactorRepository) CountChallengeAttempt(challengeId string) (domain.LoginChallenge, error) {
+	var challenge domain.LoginChallenge
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := repo.challenges.FindOneAndUpdate(context.Background(), bson.M{"_id": challengeId}, bson.M{"$inc": bson.M{"attempts": 1}}, opts).Decode(&challenge)
+	return challenge, err
+}
+
+func (repo *twoFactorRepository) DeleteChallenge(challengeId string) error {
+	_, err := repo.challenges.DeleteOne(context.Background(), bson.M{"_id": challengeId})
+	return err
+}
@@ -4,7 +4,7 @@ import (
 	"github.com/yesetoda/BlogMate/domain"
 	"context"
 	"fmt"
-	"log"
+	"time"
 
 	"github.com/sv-tools/mongoifc"
 	"go.mongodb.org/mongo-driver/bson"
@@ -110,17 +110,23 @@ func (repo *userRepository) Get(opts domain.UserFilterOption) ([]domain.User, er
 }
 
 func (repo *userRepository) Create(u *domain.User) (domain.User, error) {
+	return repo.CreateWithContext(context.TODO(), u)
+}
+
+// CreateWithContext creates the user within the transaction carried by ctx, if any.
+func (repo *userRepository) CreateWithContext(ctx context.Context, u *domain.User) (domain.User, error) {
 	u.ID = primitive.NewObjectID().Hex()
-	cnt, err := repo.collection.CountDocuments(context.Background(), bson.M{})
+	cnt, err := repo.collection.CountDocuments(ctx, bson.M{})
 	if err != nil {
 		return domain.User{}, fmt.Errorf("failed to create user: %v", err)
 	}
 	if cnt == 0 {
 		u.IsAdmin = true
+		u.Roles = []string{domain.RoleAdmin}
 		u.VerifyToken = ""
 		u.IsActive = true
 	}
-	_, err = repo.collection.InsertOne(context.TODO(), &u, options.InsertOne())
+	_, err = repo.collection.InsertOne(ctx, &u, options.InsertOne())
 	if mongo.IsDuplicateKeyError(err) {
 		return domain.User{}, fmt.Errorf("user with the same username or email already exists")
 	}
@@ -132,7 +138,6 @@ func (repo *userRepository) Create(u *domain.User) (domain.User, error) {
 
 func (repo *userRepository) Update(userId string, updateData domain.User) (domain.User, error) {
 	user, err := repo.getByID(userId)
-	log.Println(user.IsAdmin, updateData)
 	if err != nil {
 		return domain.User{}, err
 	}
@@ -145,10 +150,14 @@ func (repo *userRepository) Update(userId string, updateData domain.User) (domai
 	}
 	//in every request of this function must pass the isadmin value
 	user.IsAdmin = updateData.IsAdmin
+	user.Roles = withAdminRole(user.Roles, user.IsAdmin)
 
 	if updateData.Password != "" {
 		user.Password = updateData.Password
 	}
+	if updateData.PasswordHistory != nil {
+		user.PasswordHistory = updateData.PasswordHistory
+	}
 	if updateData.LastName != "" {
 		user.LastName = updateData.LastName
 	}
@@ -156,6 +165,9 @@ func (repo *userRepository) Update(userId string, updateData domain.User) (domai
 		user.FirstName = updateData.FirstName
 	}
 
+	if updateData.Locale != "" {
+		user.Locale = updateData.Locale
+	}
 	if updateData.IsActive {
 		user.IsActive = true
 	}
@@ -169,6 +181,62 @@ func (repo *userRepository) Update(userId string, updateData domain.User) (domai
 	return user, nil
 }
 
+// withAdminRole adds the admin role to the roles of admins and removes it
+// from everyone else.
+func withAdminRole(roles []string, isAdmin bool) []string {
+	var kept []string
+	for _, role := range roles {
+		if role != domain.RoleAdmin {
+			kept = append(kept, role)
+		}
+	}
+	if isAdmin {
+		kept = append(kept, domain.RoleAdmin)
+	}
+	return kept
+}
+
+func (repo *userRepository) SetRoles(userId string, roles []string) (domain.User, error) {
+	isAdmin := false
+	for _, role := range roles {
+		isAdmin = isAdmin || role == domain.RoleAdmin
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var user domain.User
+	err := repo.collection.FindOneAndUpdate(context.TODO(), bson.M{"_id": userId}, bson.M{"$set": bson.M{"roles": roles, "isadmin": isAdmin}}, opts).Decode(&user)
+	return user, err
+}
+
+func (repo *userRepository) SetEmail(userId, email string) (domain.User, error) {
+	return repo.set(userId, bson.M{"email": email}, "email")
+}
+
+func (repo *userRepository) SetUsername(userId, username string, at time.Time) (domain.User, error) {
+	return repo.set(userId, bson.M{"username": username, "username_changed_at": at}, "username")
+}
+
+// set updates the fields of the user, the unique indexes refusing an email
+// or username that is taken.
+func (repo *userRepository) set(userId string, fields bson.M, unique string) (domain.User, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var user domain.User
+	err := repo.collection.FindOneAndUpdate(context.TODO(), bson.M{"_id": userId}, bson.M{"$set": fields}, opts).Decode(&user)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.User{}, fmt.Errorf("this %s is already taken", unique)
+	}
+	return user, err
+}
+
+func (repo *userRepository) BumpTokenVersion(userId string) (int, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var user domain.User
+	err := repo.collection.FindOneAndUpdate(context.TODO(), bson.M{"_id": userId}, bson.M{"$inc": bson.M{"token_version": 1}}, opts).Decode(&user)
+	if err != nil {
+		return 0, err
+	}
+	return user.TokenVersion, nil
+}
+
 func (repo *userRepository) Delete(userId string) error {
 	res, err := repo.collection.DeleteOne(context.TODO(), bson.D{{"_id", userId}}, options.Delete())
 	if res.DeletedCount == 0 {
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"github.com/yesetoda/BlogMate/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type webhookRepository struct {
+	webhooks   mongoifc.Collection
+	deliveries mongoifc.Collection
+}
+
+func NewWebhookRepository(webhooks, deliveries mongoifc.Collection) domain.WebhookRepository {
+	webhooks.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "events", Value: 1}},
+	})
+	deliveries.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt", Value: 1}},
+	})
+	deliveries.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	return &webhookRepository{webhooks: webhooks, deliveries: deliveries}
+}
+
+func (repo *webhookRepository) CreateWebhook(w domain.Webhook) (domain.Webhook, error) {
+	w.WebhookId = primitive.NewObjectID().Hex()
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = time.Now()
+	}
+	if _, err := repo.webhooks.InsertOne(context.Background(), w); err != nil {
+		return domain.Webhook{}, err
+	}
+	return w, nil
+}
+
+func (repo *webhookRepository) findWebhooks(filter bson.M) ([]domain.Webhook, error) {
+	ctx := context.Background()
+	cursor, err := repo.webhooks.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	webhooks := []domain.Webhook{}
+	if err := cursor.All(ctx, &webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (repo *webhookRepository) GetWebhooks() ([]domain.Webhook, error) {
+	return repo.findWebhooks(bson.M{})
+}
+
+func (repo *webhookRepository) GetWebhooksForEvent(event string) ([]domain.Webhook, error) {
+	return repo.findWebhooks(bson.M{"events": event, "active": bson.M{"$ne": false}})
+}
+
+func (repo *webhookRepository) GetWebhookById(webhookId string) (domain.Webhook, error) {
+	var w domain.Webhook
+	err := repo.webhooks.FindOne(context.Background(), bson.M{"_id": webhookId}).Decode(&w)
+	if err == mongo.ErrNoDocuments {
+		return domain.Webhook{}, errors.New("there is no webhook with the given id")
+	}
+	return w, err
+}
+
+func (repo *webhookRepository) UpdateWebhook(webhookId string, updateData domain.Webhook) (domain.Webhook, error) {
+	set := bson.M{}
+	if updateData.URL != "" {
+		set["url"] = updateData.URL
+	}
+	if updateData.Events != nil {
+		set["events"] = updateData.Events
+	}
+	if updateData.Secret != "" {
+		set["secret"] = updateData.Secret
+	}
+	if updateData.Active != nil {
+		set["active"] = *updateData.Active
+	}
+	if len(set) > 0 {
+		result, err := repo.webhooks.UpdateOne(context.Background(), bson.M{"_id": webhookId}, bson.M{"$set": set})
+		if err != nil {
+			return domain.Webhook{}, err
+		}
+		if result.MatchedCount == 0 {
+			return domain.Webhook{}, errors.New("there is no webhook with the given id")
+		}
+	}
+	return repo.GetWebhookById(webhookId)
+}
+
+func (repo *webhookRepository) DeleteWebhook(webhookId string) error {
+	ctx := context.Background()
+	result, err := repo.webhooks.DeleteOne(ctx, bson.M{"_id": webhookId})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("there is no webhook with the given id")
+	}
+	_, err = repo.deliveries.DeleteMany(ctx, bson.M{"webhook_id": webhookId})
+	return err
+}
+
+func (repo *webhookRepository) CreateDelivery(d domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	if d.DeliveryId == "" {
+		d.DeliveryId = primitive.NewObjectID().Hex()
+	}
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = time.Now()
+	}
+	if d.Status == "" {
+		d.Status = domain.DeliveryPending
+	}
+	if d.Attempts == nil {
+		d.Attempts = []domain.WebhookAttempt{}
+	}
+	// an existing delivery with the same id is kept as it is
+	_, err := repo.deliveries.UpdateOne(context.Background(), bson.M{"_id": d.DeliveryId}, bson.M{"$setOnInsert": d}, options.Update().SetUpsert(true))
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return d, nil
+}
+
+func (repo *webhookRepository) GetDeliveries(webhookId string, opts domain.PaginationInfo) ([]domain.WebhookDelivery, error) {
+	ctx := context.Background()
+	findOptions := paginationOptions(opts).SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := repo.deliveries.Find(ctx, bson.M{"webhook_id": webhookId}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	deliveries := []domain.WebhookDelivery{}
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (repo *webhookRepository) GetDeliveryById(webhookId, deliveryId string) (domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	err := repo.deliveries.FindOne(context.Background(), bson.M{"_id": deliveryId, "webhook_id": webhookId}).Decode(&d)
+	if err == mongo.ErrNoDocuments {
+		return domain.WebhookDelivery{}, errors.New("there is no delivery with the given id")
+	}
+	return d, err
+}
+
+func (repo *webhookRepository) ClaimDueDeliveries(now time.Time, lockFor time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	ctx := context.Background()
+	filter := bson.M{
+		"status":       domain.DeliveryPending,
+		"next_attempt": bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"locked_until": bson.M{"$exists": false}},
+			bson.M{"locked_until": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"locked_until": now.Add(lockFor)}}
+	opts := options.FindOneAndUpdate().SetSort(bson.D{{Key: "next_attempt", Value: 1}}).SetReturnDocument(options.After)
+	claimed := []domain.WebhookDelivery{}
+	for len(claimed) < limit {
+		var d domain.WebhookDelivery
+		err := repo.deliveries.FindOneAndUpdate(ctx, filter, update, opts).Decode(&d)
+		if err == mongo.ErrNoDocuments {
+			break
+		}
+		if err != nil {
+			return claimed, err
+		}
+		claimed = append(claimed, d)
+	}
+	return claimed, nil
+}
+
+func (repo *webhookRepository) RecordAttempt(deliveryId string, attempt domain.WebhookAttempt, status string, nextAttempt time.Time) error {
+	update := bson.M{
+		"$push":  bson.M{"attempts": attempt},
+		"$set":   bson.M{"status": status, "next_attempt": nextAttempt},
+		"$unset": bson.M{"locked_until": ""},
+	}
+	_, err := repo.deliveries.UpdateOne(context.Background(), bson.M{"_id": deliveryId}, update)
+	return err
+}
@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yesetoda/BlogMate/config"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+)
+
+const (
+	// EmailChangeTTL is how long the link confirming a new email works.
+	EmailChangeTTL = 24 * time.Hour
+	// EmailRevertTTL is how long the old address can undo a change.
+	EmailRevertTTL = 7 * 24 * time.Hour
+	// UsernameChangeInterval is how often a user can change their username.
+	UsernameChangeInterval = 30 * 24 * time.Hour
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{3,30}$`)
+
+// reservedUsernames could be mistaken for the site or its staff, or clash
+// with routes.
+var reservedUsernames = []string{
+	"admin", "administrator", "root", "system", "support", "help", "security",
+	"moderator", "staff", "official", "blogmate", "api", "me", "users", "blogs",
+	"roles", "auth", "login", "logout", "register", "null", "undefined",
+}
+
+var errInvalidEmailChange = errors.New("the link is invalid or expired")
+
+// AccountChangeUsecase changes the email of users, once the new address is
+// confirmed, and their username.
+type AccountChangeUsecase struct {
+	emailChangeRepository domain.EmailChangeRepository
+	userRepository        domain.UserRepository
+	outbox                *OutboxUsecase
+	revoker               domain.TokenRevoker
+	sessions              *SessionUsecase
+	now                   func() time.Time
+}
+
+func NewAccountChangeUsecase(changes domain.EmailChangeRepository, users domain.UserRepository) *AccountChangeUsecase {
+	return &AccountChangeUsecase{emailChangeRepository: changes, userRepository: users, now: time.Now}
+}
+
+// SetOutbox sends the emails through the outbox instead of inline.
+func (uc *AccountChangeUsecase) SetOutbox(outbox *OutboxUsecase) {
+	uc.outbox = outbox
+}
+
+// SetTokenRevoker revokes the access tokens of users whose email or username
+// changes, as their tokens carry them.
+func (uc *AccountChangeUsecase) SetTokenRevoker(revoker domain.TokenRevoker) {
+	uc.revoker = revoker
+}
+
+// SetSessions ends the sessions of users who revert an email change, as
+// someone else changed it.
+func (uc *AccountChangeUsecase) SetSessions(sessions *SessionUsecase) {
+	uc.sessions = sessions
+}
+
+// RequestEmailChange checks the password of the user and emails a link to the
+// new address. The email changes once the link is opened.
+func (uc *AccountChangeUsecase) RequestEmailChange(userId, password, newEmail string) error {
+	user, err := uc.checkPassword(userId, password)
+	if err != nil {
+		return err
+	}
+	newEmail = strings.TrimSpace(newEmail)
+	address, err := mail.ParseAddress(newEmail)
+	if err != nil || address.Address != newEmail {
+		return errors.New("invalid email address")
+	}
+	if strings.EqualFold(newEmail, user.Email) {
+		return errors.New("this is already your email")
+	}
+	if uc.taken(domain.UserFilter{Email: newEmail}, userId) {
+		return errors.New("this email is already taken")
+	}
+	// only the latest request can be confirmed
+	if err := uc.emailChangeRepository.DeleteEmailChanges(userId, domain.EmailChangeConfirm); err != nil {
+		return err
+	}
+	token, hash, err := uc.createEmailChange(user, domain.EmailChangeConfirm, user.Email, newEmail, EmailChangeTTL)
+	if err != nil {
+		return err
+	}
+	return uc.sendEmail(newEmail, user, infrastructure.EmailChangeEmail, "email-change:"+hash,
+		fmt.Sprintf("You asked to change the email of your BlogMate account %s to this address.", user.Username),
+		"/users/email/confirm?token="+token)
+}
+
+// ConfirmEmailChange changes the email of the user who asked for it, and
+// tells their old address how to undo it.
+func (uc *AccountChangeUsecase) ConfirmEmailChange(token string) (domain.User, error) {
+	change, err := uc.takeEmailChange(token, domain.EmailChangeConfirm)
+	if err != nil {
+		return domain.User{}, err
+	}
+	user, err := uc.setEmail(change.UserId, change.OldEmail, change.NewEmail)
+	if err != nil {
+		return domain.User{}, err
+	}
+	revertToken, hash, err := uc.createEmailChange(user, domain.EmailChangeRevert, change.OldEmail, change.NewEmail, EmailRevertTTL)
+	if err != nil {
+		return user, err
+	}
+	err = uc.sendEmail(change.OldEmail, user, infrastructure.EmailChangedEmail, "email-changed:"+hash,
+		fmt.Sprintf("The email of your BlogMate account %s was changed to %s.", user.Username, change.NewEmail),
+		"/users/email/revert?token="+revertToken)
+	return user, err
+}
+
+// RevertEmailChange gives the user back the email they had before a change,
+// cancels their pending changes and logs them out everywhere.
+func (uc *AccountChangeUsecase) RevertEmailChange(token string) (domain.User, error) {
+	change, err := uc.takeEmailChange(token, domain.EmailChangeRevert)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if err := uc.emailChangeRepository.DeleteEmailChanges(change.UserId, domain.EmailChangeConfirm); err != nil {
+		return domain.User{}, err
+	}
+	user, err := uc.setEmail(change.UserId, change.NewEmail, change.OldEmail)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if uc.sessions != nil {
+		if _, err := uc.sessions.RevokeAll(user.ID, SessionEmailReverted); err != nil {
+			return user, err
+		}
+	}
+	return user, nil
+}
+
+// ChangeUsername checks the password of the user and changes their username,
+// at most once per UsernameChangeInterval.
+func (uc *AccountChangeUsecase) ChangeUsername(userId, password, username string) (domain.User, error) {
+	user, err := uc.checkPassword(userId, password)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if !usernamePattern.MatchString(username) {
+		return domain.User{}, errors.New("usernames are 3 to 30 letters, digits or underscores")
+	}
+	for _, reserved := range reservedUsernames {
+		if strings.EqualFold(username, reserved) {
+			return domain.User{}, errors.New("this username is reserved")
+		}
+	}
+	if username == user.Username {
+		return domain.User{}, errors.New("this is already your username")
+	}
+	now := uc.now()
+	if next := user.UsernameChangedAt.Add(UsernameChangeInterval); !user.UsernameChangedAt.IsZero() && now.Before(next) {
+		return domain.User{}, fmt.Errorf("you can change your username again on %s", next.UTC().Format("2 January 2006"))
+	}
+	if uc.taken(domain.UserFilter{Username: username}, userId) {
+		return domain.User{}, errors.New("this username is already taken")
+	}
+	user, err = uc.userRepository.SetUsername(userId, username, now)
+	if err != nil {
+		return domain.User{}, err
+	}
+	return user, uc.revokeTokens(userId)
+}
+
+func (uc *AccountChangeUsecase) checkPassword(userId, password string) (domain.User, error) {
+	users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{UserId: userId}})
+	if err != nil || len(users) == 0 || users[0].ID == "" {
+		return domain.User{}, errors.New("user not found")
+	}
+	if ok, _ := infrastructure.VerifyPassword(users[0].Password, password); !ok {
+		return domain.User{}, errors.New("invalid password")
+	}
+	return users[0], nil
+}
+
+// taken tells whether another user has the email or username of the filter.
+func (uc *AccountChangeUsecase) taken(filter domain.UserFilter, userId string) bool {
+	users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: filter})
+	return err == nil && len(users) > 0 && users[0].ID != "" && users[0].ID != userId
+}
+
+// createEmailChange stores the change and returns its token and hash.
+func (uc *AccountChangeUsecase) createEmailChange(user domain.User, kind, oldEmail, newEmail string, ttl time.Duration) (string, string, error) {
+	token, hash, err := infrastructure.NewRefreshToken()
+	if err != nil {
+		return "", "", err
+	}
+	now := uc.now()
+	err = uc.emailChangeRepository.CreateEmailChange(domain.EmailChange{
+		ChangeId:  hash,
+		UserId:    user.ID,
+		Kind:      kind,
+		OldEmail:  oldEmail,
+		NewEmail:  newEmail,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	})
+	return token, hash, err
+}
+
+func (uc *AccountChangeUsecase) takeEmailChange(token, kind string) (domain.EmailChange, error) {
+	if token == "" {
+		return domain.EmailChange{}, errInvalidEmailChange
+	}
+	change, err := uc.emailChangeRepository.TakeEmailChange(infrastructure.HashRefreshToken(token), kind)
+	if err != nil || !change.ExpiresAt.After(uc.now()) {
+		return domain.EmailChange{}, errInvalidEmailChange
+	}
+	return change, nil
+}
+
+// setEmail changes the email of the user from one address to the other,
+// unless it changed since, and logs them out.
+func (uc *AccountChangeUsecase) setEmail(userId, from, to string) (domain.User, error) {
+	users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{UserId: userId}})
+	if err != nil || len(users) == 0 || users[0].ID == "" || users[0].Email != from {
+		return domain.User{}, errInvalidEmailChange
+	}
+	user, err := uc.userRepository.SetEmail(userId, to)
+	if err != nil {
+		return domain.User{}, err
+	}
+	return user, uc.revokeTokens(userId)
+}
+
+func (uc *AccountChangeUsecase) revokeTokens(userId string) error {
+	if uc.revoker == nil {
+		return nil
+	}
+	return uc.revoker.RevokeUserTokens(userId)
+}
+
+func (uc *AccountChangeUsecase) sendEmail(to string, user domain.User, template, key, body, path string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	payload := domain.OutboxEmailPayload{
+		To:       to,
+		Template: template,
+		Locale:   user.Locale,
+		Name:     displayName(user),
+		Body:     body,
+		Link:     cfg.Port + path,
+	}
+	if uc.outbox != nil {
+		return uc.outbox.Enqueue(context.Background(), domain.OutboxEmail, key, payload)
+	}
+	data := infrastructure.EmailData{Name: payload.Name, Body: payload.Body, Link: payload.Link}
+	return infrastructure.SendTemplatedEmail(payload.To, payload.Template, payload.Locale, data)
+}
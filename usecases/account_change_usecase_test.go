@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+// linkToken reads the token of the link to path in the email.
+func linkToken(t *testing.T, email infrastructure.Email, path string) string {
+	start := strings.Index(email.Text, path+"?")
+	if !assert.NotEqual(t, -1, start, email.Text) {
+		return ""
+	}
+	line := strings.Fields(email.Text[start:])[0]
+	query, err := url.ParseQuery(strings.SplitN(line, "?", 2)[1])
+	assert.NoError(t, err)
+	return query.Get("token")
+}
+
+// storedEmailChanges backs the mock repository with a map.
+func storedEmailChanges(changes *mocks.EmailChangeRepository) map[string]domain.EmailChange {
+	stored := map[string]domain.EmailChange{}
+	changes.On("CreateEmailChange", mock.Anything).Return(func(change domain.EmailChange) error {
+		stored[change.ChangeId] = change
+		return nil
+	}).Maybe()
+	changes.On("TakeEmailChange", mock.Anything, mock.Anything).Return(func(changeId, kind string) (domain.EmailChange, error) {
+		change, ok := stored[changeId]
+		if !ok || change.Kind != kind {
+			return domain.EmailChange{}, assert.AnError
+		}
+		delete(stored, changeId)
+		return change, nil
+	}).Maybe()
+	changes.On("DeleteEmailChanges", mock.Anything, mock.Anything).Return(func(userId, kind string) error {
+		for id, change := range stored {
+			if change.UserId == userId && change.Kind == kind {
+				delete(stored, id)
+			}
+		}
+		return nil
+	}).Maybe()
+	return stored
+}
+
+func TestEmailChange(t *testing.T) {
+	mailer := infrastructure.NewMemoryMailer()
+	infrastructure.SetDefaultMailer(mailer)
+	defer infrastructure.SetDefaultMailer(nil)
+	changes := mocks.NewEmailChangeRepository(t)
+	storedEmailChanges(changes)
+	users := mocks.NewUserRepository(t)
+	revoker := mocks.NewTokenRevoker(t)
+	uc := NewAccountChangeUsecase(changes, users)
+	uc.SetTokenRevoker(revoker)
+
+	hash, _ := infrastructure.PasswordHasher("correct horse 42")
+	user := domain.User{ID: "u1", Username: "ada", Email: "ada@example.com", Password: hash, IsActive: true}
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{UserId: "u1"}}).Return(func(domain.UserFilterOption) ([]domain.User, error) {
+		return []domain.User{user}, nil
+	})
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Email: "grace@example.com"}}).Return([]domain.User{{ID: "u2"}}, nil)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Email: "ada@lovelace.org"}}).Return([]domain.User{{}}, assert.AnError)
+	users.On("SetEmail", "u1", mock.Anything).Return(func(_, email string) (domain.User, error) {
+		user.Email = email
+		return user, nil
+	})
+	revoker.On("RevokeUserTokens", "u1").Return(nil)
+
+	assert.Error(t, uc.RequestEmailChange("u1", "wrong horse", "ada@lovelace.org"))
+	assert.Error(t, uc.RequestEmailChange("u1", "correct horse 42", "not an email"))
+	assert.Error(t, uc.RequestEmailChange("u1", "correct horse 42", "grace@example.com"), "taken")
+	assert.Empty(t, mailer.Sent())
+
+	assert.NoError(t, uc.RequestEmailChange("u1", "correct horse 42", "ada@lovelace.org"))
+	confirmation, _ := mailer.Last()
+	assert.Equal(t, []string{"ada@lovelace.org"}, confirmation.To)
+	assert.Equal(t, "ada@example.com", user.Email, "nothing changes before the link is opened")
+
+	changed, err := uc.ConfirmEmailChange(linkToken(t, confirmation, "/users/email/confirm"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ada@lovelace.org", changed.Email)
+	_, err = uc.ConfirmEmailChange(linkToken(t, confirmation, "/users/email/confirm"))
+	assert.Error(t, err, "the link works once")
+
+	notice, _ := mailer.Last()
+	assert.Equal(t, []string{"ada@example.com"}, notice.To)
+	assert.Contains(t, notice.Text, "ada@lovelace.org")
+	_, err = uc.ConfirmEmailChange(linkToken(t, notice, "/users/email/revert"))
+	assert.Error(t, err, "a revert link does not confirm")
+	reverted, err := uc.RevertEmailChange(linkToken(t, notice, "/users/email/revert"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ada@example.com", reverted.Email)
+	revoker.AssertNumberOfCalls(t, "RevokeUserTokens", 2)
+}
+
+func TestChangeUsername(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	users := mocks.NewUserRepository(t)
+	uc := NewAccountChangeUsecase(mocks.NewEmailChangeRepository(t), users)
+	uc.now = func() time.Time { return now }
+
+	hash, _ := infrastructure.PasswordHasher("correct horse 42")
+	user := domain.User{ID: "u1", Username: "ada", Email: "ada@example.com", Password: hash}
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{UserId: "u1"}}).Return(func(domain.UserFilterOption) ([]domain.User, error) {
+		return []domain.User{user}, nil
+	})
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Username: "grace"}}).Return([]domain.User{{ID: "u2"}}, nil)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Username: "countess"}}).Return([]domain.User{{}}, assert.AnError)
+	users.On("SetUsername", "u1", "countess", now).Return(func(_, username string, at time.Time) (domain.User, error) {
+		user.Username, user.UsernameChangedAt = username, at
+		return user, nil
+	})
+
+	for username, reason := range map[string]string{
+		"ab":           "too short",
+		"ada lovelace": "spaces",
+		"Admin":        "reserved",
+		"BlogMate":     "reserved",
+		"grace":        "taken",
+		"ada":          "unchanged",
+	} {
+		_, err := uc.ChangeUsername("u1", "correct horse 42", username)
+		assert.Error(t, err, reason)
+	}
+	_, err := uc.ChangeUsername("u1", "wrong horse", "countess")
+	assert.Error(t, err)
+
+	changed, err := uc.ChangeUsername("u1", "correct horse 42", "countess")
+	assert.NoError(t, err)
+	assert.Equal(t, "countess", changed.Username)
+
+	now = now.Add(UsernameChangeInterval / 2)
+	_, err = uc.ChangeUsername("u1", "correct horse 42", "ada_l")
+	assert.ErrorContains(t, err, "again on 17 November 2026")
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+)
+
+const (
+	maxAPIKeysPerUser = 20
+	// apiKeyTouchInterval is how often the last use of a key is written, so
+	// busy keys do not write on every request.
+	apiKeyTouchInterval = time.Minute
+)
+
+var errInvalidAPIKey = errors.New("invalid API key")
+
+// APIKeyUsecase manages the personal API keys of users and authenticates the
+// requests made with them.
+type APIKeyUsecase struct {
+	apiKeyRepository domain.APIKeyRepository
+	userRepository   domain.UserRepository
+	now              func() time.Time
+}
+
+func NewAPIKeyUsecase(keys domain.APIKeyRepository, users domain.UserRepository) *APIKeyUsecase {
+	return &APIKeyUsecase{apiKeyRepository: keys, userRepository: users, now: time.Now}
+}
+
+// Create makes a key for the user with the scopes, expiring after expiresIn
+// or never when it is zero. The key is only returned this once.
+func (uc *APIKeyUsecase) Create(userId, name string, scopes []string, expiresIn time.Duration) (domain.NewAPIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return domain.NewAPIKey{}, errors.New("name the key, in at most 100 characters")
+	}
+	scopes, err := validScopes(scopes)
+	if err != nil {
+		return domain.NewAPIKey{}, err
+	}
+	if expiresIn < 0 {
+		return domain.NewAPIKey{}, errors.New("the expiry must be in the future")
+	}
+	existing, err := uc.apiKeyRepository.GetAPIKeys(userId)
+	if err != nil {
+		return domain.NewAPIKey{}, err
+	}
+	if len(existing) >= maxAPIKeysPerUser {
+		return domain.NewAPIKey{}, errors.New("too many API keys, delete unused ones first")
+	}
+
+	key, keyId, secretHash, err := infrastructure.NewAPIKey()
+	if err != nil {
+		return domain.NewAPIKey{}, err
+	}
+	apiKey := domain.APIKey{
+		KeyId:      keyId,
+		UserId:     userId,
+		Name:       name,
+		SecretHash: secretHash,
+		Scopes:     scopes,
+		CreatedAt:  uc.now(),
+	}
+	if expiresIn > 0 {
+		apiKey.ExpiresAt = apiKey.CreatedAt.Add(expiresIn)
+	}
+	if err := uc.apiKeyRepository.CreateAPIKey(apiKey); err != nil {
+		return domain.NewAPIKey{}, err
+	}
+	return domain.NewAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+// validScopes checks the scopes are known and removes duplicates.
+func validScopes(scopes []string) ([]string, error) {
+	valid := []string{}
+	for _, scope := range scopes {
+		known := false
+		for _, candidate := range domain.APIKeyScopes {
+			known = known || scope == candidate
+		}
+		if !known {
+			return nil, errors.New("unknown scope " + scope + ", use " + strings.Join(domain.APIKeyScopes, ", "))
+		}
+		duplicate := false
+		for _, seen := range valid {
+			duplicate = duplicate || scope == seen
+		}
+		if !duplicate {
+			valid = append(valid, scope)
+		}
+	}
+	if len(valid) == 0 {
+		return nil, errors.New("give the key at least one scope")
+	}
+	return valid, nil
+}
+
+func (uc *APIKeyUsecase) GetAPIKeys(userId string) ([]domain.APIKey, error) {
+	return uc.apiKeyRepository.GetAPIKeys(userId)
+}
+
+func (uc *APIKeyUsecase) Delete(userId, keyId string) error {
+	deleted, err := uc.apiKeyRepository.DeleteAPIKey(userId, keyId)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("API key not found")
+	}
+	return nil
+}
+
+// AuthenticateAPIKey returns the claims of the user of the key, limited to
+// its scopes. Keys never carry admin rights.
+func (uc *APIKeyUsecase) AuthenticateAPIKey(key string, ip string) (*domain.Claims, error) {
+	keyId, secretHash, ok := infrastructure.ParseAPIKey(key)
+	if !ok {
+		return nil, errInvalidAPIKey
+	}
+	apiKey, err := uc.apiKeyRepository.GetAPIKey(keyId)
+	if err != nil || subtle.ConstantTimeCompare([]byte(secretHash), []byte(apiKey.SecretHash)) != 1 {
+		return nil, errInvalidAPIKey
+	}
+	now := uc.now()
+	if !apiKey.ExpiresAt.IsZero() && !apiKey.ExpiresAt.After(now) {
+		return nil, errors.New("the API key has expired")
+	}
+	users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{UserId: apiKey.UserId}})
+	if err != nil || len(users) == 0 || users[0].ID == "" || !users[0].IsActive {
+		return nil, errInvalidAPIKey
+	}
+	if now.Sub(apiKey.LastUsedAt) >= apiKeyTouchInterval || apiKey.LastUsedIP != ip {
+		if err := uc.apiKeyRepository.TouchAPIKey(keyId, now, ip); err != nil {
+			log.Println("recording the use of API key", keyId, "failed:", err)
+		}
+	}
+	user := users[0]
+	roles := []string{}
+	for _, role := range user.EffectiveRoles() {
+		if role != domain.RoleAdmin {
+			roles = append(roles, role)
+		}
+	}
+	return &domain.Claims{
+		ID:       user.ID,
+		Email:    user.Email,
+		Username: user.Username,
+		IsActive: user.IsActive,
+		Scopes:   apiKey.Scopes,
+		Roles:    roles,
+	}, nil
+}
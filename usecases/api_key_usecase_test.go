@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+func TestAPIKeyCreate(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	keys := mocks.NewAPIKeyRepository(t)
+	uc := NewAPIKeyUsecase(keys, mocks.NewUserRepository(t))
+	uc.now = func() time.Time { return now }
+
+	_, err := uc.Create("u1", " ", []string{domain.ScopeRead}, 0)
+	assert.Error(t, err)
+	_, err = uc.Create("u1", "ci", nil, 0)
+	assert.Error(t, err)
+	_, err = uc.Create("u1", "ci", []string{"admin"}, 0)
+	assert.Error(t, err)
+
+	keys.On("GetAPIKeys", "u1").Return([]domain.APIKey{}, nil)
+	var stored domain.APIKey
+	keys.On("CreateAPIKey", mock.Anything).Return(func(key domain.APIKey) error {
+		stored = key
+		return nil
+	})
+	created, err := uc.Create("u1", "release notes", []string{domain.ScopeBlogsWrite, domain.ScopeBlogsWrite}, 90*24*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{domain.ScopeBlogsWrite}, stored.Scopes)
+	assert.Equal(t, now.Add(90*24*time.Hour), stored.ExpiresAt)
+	assert.Equal(t, stored, created.APIKey)
+	assert.NotContains(t, created.Key, stored.SecretHash, "only the hash is stored")
+}
+
+func TestAPIKeyAuthenticate(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	keys := mocks.NewAPIKeyRepository(t)
+	users := mocks.NewUserRepository(t)
+	uc := NewAPIKeyUsecase(keys, users)
+	uc.now = func() time.Time { return now }
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{UserId: "u1"}}).Return([]domain.User{{ID: "u1", Username: "ci", IsAdmin: true, IsActive: true}}, nil)
+
+	var stored domain.APIKey
+	keys.On("GetAPIKeys", "u1").Return([]domain.APIKey{}, nil)
+	keys.On("CreateAPIKey", mock.Anything).Return(func(key domain.APIKey) error {
+		stored = key
+		return nil
+	})
+	created, err := uc.Create("u1", "ci", []string{domain.ScopeRead}, time.Hour)
+	assert.NoError(t, err)
+	keys.On("GetAPIKey", stored.KeyId).Return(func(string) (domain.APIKey, error) { return stored, nil })
+
+	keys.On("TouchAPIKey", stored.KeyId, now, "10.0.0.1").Return(func(string, time.Time, string) error {
+		stored.LastUsedAt, stored.LastUsedIP = now, "10.0.0.1"
+		return nil
+	}).Once()
+	claims, err := uc.AuthenticateAPIKey(created.Key, "10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", claims.ID)
+	assert.Equal(t, []string{domain.ScopeRead}, claims.Scopes)
+	assert.False(t, claims.IsAdmin, "keys never carry admin rights")
+
+	// the last use is not written again within a minute
+	_, err = uc.AuthenticateAPIKey(created.Key, "10.0.0.1")
+	assert.NoError(t, err)
+
+	_, err = uc.AuthenticateAPIKey(created.Key[:len(created.Key)-1]+"x", "10.0.0.1")
+	assert.Error(t, err)
+	_, err = uc.AuthenticateAPIKey("not a key", "10.0.0.1")
+	assert.Error(t, err)
+
+	now = now.Add(time.Hour)
+	_, err = uc.AuthenticateAPIKey(created.Key, "10.0.0.1")
+	assert.Error(t, err, "the key expired")
+}
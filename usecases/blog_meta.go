@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yesetoda/BlogMate/domain"
+)
+
+const (
+	siteName = "BlogMate"
+	// excerptLength is the length of excerpts made from the content.
+	excerptLength           = 200
+	maxExcerptLength        = 300
+	maxSEOTitleLength       = 70
+	maxSEODescriptionLength = 160
+)
+
+var (
+	markdownImage = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	markdownLink  = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	htmlTag       = regexp.MustCompile(`<[^>]*>`)
+	markdownMarks = regexp.MustCompile("(?m)^\\s*(#{1,6}|>|[-*+]|\\d+\\.)\\s+|[*_`~]")
+)
+
+// SetSite gives the metadata of blogs the URL of the site, and the users to
+// name their authors.
+func (uc *BlogUsecase) SetSite(siteURL string, users domain.UserRepository) {
+	uc.siteURL = strings.TrimSuffix(siteURL, "/")
+	uc.users = users
+}
+
+// makeExcerpt turns the start of the content into plain text of at most
+// excerptLength characters, cut between words.
+func makeExcerpt(content string) string {
+	text := markdownImage.ReplaceAllString(content, "")
+	text = markdownLink.ReplaceAllString(text, "$1")
+	text = htmlTag.ReplaceAllString(text, " ")
+	text = markdownMarks.ReplaceAllString(text, "")
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= excerptLength {
+		return text
+	}
+	runes := []rune(text)[:excerptLength]
+	if cut := strings.LastIndex(string(runes), " "); cut > 0 {
+		return strings.TrimRight(string(runes)[:cut], ".,;:!?") + "…"
+	}
+	return string(runes) + "…"
+}
+
+// prepareMeta checks the cover, excerpt and SEO fields of a blog of the
+// author, and points the cover at the uploaded image it names.
+func (uc *BlogUsecase) prepareMeta(blog *domain.Blog, authorId string) error {
+	blog.Excerpt = strings.TrimSpace(blog.Excerpt)
+	blog.SEOTitle = strings.TrimSpace(blog.SEOTitle)
+	blog.SEODescription = strings.TrimSpace(blog.SEODescription)
+	if err := checkLength("excerpt", blog.Excerpt, maxExcerptLength); err != nil {
+		return err
+	}
+	if err := checkLength("SEO title", blog.SEOTitle, maxSEOTitleLength); err != nil {
+		return err
+	}
+	if err := checkLength("SEO description", blog.SEODescription, maxSEODescriptionLength); err != nil {
+		return err
+	}
+	if err := checkURL("cover image", blog.CoverImage); err != nil {
+		return err
+	}
+	if err := checkURL("canonical", blog.CanonicalURL); err != nil {
+		return err
+	}
+	if blog.CoverMediaId == "" {
+		return nil
+	}
+	if uc.media == nil {
+		return errors.New("uploads are not enabled")
+	}
+	cover, err := uc.media.GetMedia(blog.CoverMediaId)
+	if err != nil || cover.OwnerId != authorId || !strings.HasPrefix(cover.ContentType, "image/") {
+		return errors.New("the cover must be an image you uploaded")
+	}
+	blog.CoverImage = cover.URL
+	return nil
+}
+
+// GetBlogMeta describes the blog with Open Graph and Twitter Card tags and
+// JSON-LD Article data, for link previews and search engines.
+func (uc *BlogUsecase) GetBlogMeta(blogId string) (domain.BlogMeta, error) {
+	blog, err := uc.blogRepository.GetBlogById(blogId)
+	if err != nil {
+		return domain.BlogMeta{}, err
+	}
+	meta := domain.BlogMeta{
+		Title:        firstNonEmpty(blog.SEOTitle, blog.Title),
+		Description:  firstNonEmpty(blog.SEODescription, blog.Excerpt, makeExcerpt(blog.Content)),
+		CanonicalURL: firstNonEmpty(blog.CanonicalURL, uc.siteURL+"/blogs/"+blogId),
+		Image:        blog.CoverImage,
+	}
+	author := map[string]interface{}{"@type": "Person"}
+	if uc.users != nil {
+		users, err := uc.users.Get(domain.UserFilterOption{Filter: domain.UserFilter{UserId: blog.AuthorId}})
+		if err == nil && len(users) > 0 && users[0].ID != "" {
+			author["name"] = firstNonEmpty(strings.TrimSpace(users[0].FirstName+" "+users[0].LastName), users[0].Username)
+			author["url"] = uc.siteURL + "/users/" + users[0].Username + "/profile"
+		}
+	}
+
+	meta.Tags = []domain.MetaTag{
+		{Name: "description", Content: meta.Description},
+		{Property: "og:type", Content: "article"},
+		{Property: "og:site_name", Content: siteName},
+		{Property: "og:title", Content: meta.Title},
+		{Property: "og:description", Content: meta.Description},
+		{Property: "og:url", Content: meta.CanonicalURL},
+	}
+	if meta.Image != "" {
+		meta.Tags = append(meta.Tags, domain.MetaTag{Property: "og:image", Content: meta.Image})
+	}
+	if !blog.Date.IsZero() {
+		meta.Tags = append(meta.Tags, domain.MetaTag{Property: "article:published_time", Content: blog.Date.UTC().Format(time.RFC3339)})
+	}
+	if url, ok := author["url"].(string); ok {
+		meta.Tags = append(meta.Tags, domain.MetaTag{Property: "article:author", Content: url})
+	}
+	for _, tag := range blog.Tags {
+		meta.Tags = append(meta.Tags, domain.MetaTag{Property: "article:tag", Content: tag})
+	}
+	card := "summary"
+	if meta.Image != "" {
+		card = "summary_large_image"
+	}
+	meta.Tags = append(meta.Tags,
+		domain.MetaTag{Name: "twitter:card", Content: card},
+		domain.MetaTag{Name: "twitter:title", Content: meta.Title},
+		domain.MetaTag{Name: "twitter:description", Content: meta.Description},
+	)
+	if meta.Image != "" {
+		meta.Tags = append(meta.Tags, domain.MetaTag{Name: "twitter:image", Content: meta.Image})
+	}
+
+	meta.JSONLD = map[string]interface{}{
+		"@context":         "https://schema.org",
+		"@type":            "BlogPosting",
+		"headline":         meta.Title,
+		"description":      meta.Description,
+		"url":              meta.CanonicalURL,
+		"mainEntityOfPage": map[string]interface{}{"@type": "WebPage", "@id": meta.CanonicalURL},
+		"author":           author,
+		"publisher":        map[string]interface{}{"@type": "Organization", "name": siteName},
+	}
+	if meta.Image != "" {
+		meta.JSONLD["image"] = []string{meta.Image}
+	}
+	if !blog.Date.IsZero() {
+		meta.JSONLD["datePublished"] = blog.Date.UTC().Format(time.RFC3339)
+	}
+	if len(blog.Tags) > 0 {
+		meta.JSONLD["keywords"] = strings.Join(blog.Tags, ", ")
+	}
+	return meta, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+func TestMakeExcerpt(t *testing.T) {
+	assert.Equal(t, "Hello world see the docs and this list.",
+		makeExcerpt("# Hello *world*\n\n![logo](https://x/logo.png) see [the docs](https://x/docs) <b>and</b>\n- this `list`."))
+	long := makeExcerpt(strings.Repeat("word ", 100))
+	assert.Equal(t, strings.TrimSpace(strings.Repeat("word ", 40))+"…", long)
+	assert.LessOrEqual(t, len([]rune(long)), excerptLength+1)
+	assert.Equal(t, "", makeExcerpt(""))
+}
+
+func TestBlogMetaFields(t *testing.T) {
+	blogs := mocks.NewBlogRepository(t)
+	uc := NewBlogUsecase(blogs)
+
+	for _, invalid := range []domain.Blog{
+		{SEOTitle: strings.Repeat("a", 71)},
+		{SEODescription: strings.Repeat("a", 161)},
+		{Excerpt: strings.Repeat("a", 301)},
+		{CanonicalURL: "/blogs/b1"},
+		{CoverImage: "data:image/png;base64,AAAA"},
+		{CoverMediaId: "m1"},
+	} {
+		invalid.AuthorId = "u1"
+		_, err := uc.CreateBLog(invalid)
+		assert.Error(t, err, "%+v", invalid)
+	}
+
+	blogs.On("CreateBlogWithContext", mock.Anything, mock.Anything).Return(func(_ context.Context, blog domain.Blog) (domain.Blog, error) {
+		blog.BlogId = "b1"
+		return blog, nil
+	})
+	blog, err := uc.CreateBLog(domain.Blog{AuthorId: "u1", Title: "Go", Content: "**Generics** are here."})
+	require.NoError(t, err)
+	assert.Equal(t, "Generics are here.", blog.Excerpt)
+	blog, err = uc.CreateBLog(domain.Blog{AuthorId: "u1", Title: "Go", Content: "Generics are here.", Excerpt: " Written by hand "})
+	require.NoError(t, err)
+	assert.Equal(t, "Written by hand", blog.Excerpt)
+
+	blogs.On("GetBlogById", "generated").Return(domain.Blog{BlogId: "generated", AuthorId: "u1", Content: "Old.", Excerpt: "Old."}, nil)
+	blogs.On("GetBlogById", "written").Return(domain.Blog{BlogId: "written", AuthorId: "u1", Content: "Old.", Excerpt: "By hand."}, nil)
+	blogs.On("UpdateBlogWithContext", mock.Anything, "generated", domain.Blog{Content: "New.", Excerpt: "New."}).Return(domain.Blog{}, nil).Once()
+	_, err = uc.UpdateBLog("generated", domain.Blog{Content: "New."})
+	assert.NoError(t, err, "generated excerpts follow the content")
+	blogs.On("UpdateBlogWithContext", mock.Anything, "written", domain.Blog{Content: "New."}).Return(domain.Blog{}, nil).Once()
+	_, err = uc.UpdateBLog("written", domain.Blog{Content: "New."})
+	assert.NoError(t, err, "written excerpts are kept")
+
+	repo := mocks.NewMediaRepository(t)
+	stored := storedMedia(repo)
+	storage, err := infrastructure.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	media := NewMediaUsecase(repo, storage, MediaSettings{BaseURL: "https://blogmate.example"})
+	uc.SetMedia(media)
+	cover, err := media.Upload("u1", "cover.png", pngImage(t, 1200, 630))
+	require.NoError(t, err)
+	document, err := media.Upload("u1", "cv.pdf", []byte("%PDF-1.7\n..."))
+	require.NoError(t, err)
+	_, err = uc.CreateBLog(domain.Blog{AuthorId: "u1", Title: "Go", Content: "x", CoverMediaId: document.MediaId})
+	assert.Error(t, err, "covers are images")
+	_, err = uc.CreateBLog(domain.Blog{AuthorId: "u2", Title: "Go", Content: "x", CoverMediaId: cover.MediaId})
+	assert.Error(t, err, "covers are images of the author")
+
+	blog, err = uc.CreateBLog(domain.Blog{AuthorId: "u1", Title: "Go", Content: "x", CoverMediaId: cover.MediaId, CoverImage: "https://elsewhere.example/cover.png"})
+	require.NoError(t, err)
+	assert.Equal(t, cover.URL, blog.CoverImage)
+	assert.Equal(t, []string{"blog:b1"}, stored[cover.MediaId].References, "covers are kept")
+}
+
+func TestGetBlogMeta(t *testing.T) {
+	blogs := mocks.NewBlogRepository(t)
+	users := mocks.NewUserRepository(t)
+	uc := NewBlogUsecase(blogs)
+	uc.SetSite("https://blogmate.example/", users)
+	date := time.Date(2026, 10, 1, 8, 30, 0, 0, time.UTC)
+	blogs.On("GetBlogById", "b1").Return(domain.Blog{
+		BlogId: "b1", AuthorId: "u1", Title: "Generics in Go", Content: "Long content", Excerpt: "A tour of generics.",
+		Tags: []string{"go", "generics"}, Date: date, CoverImage: "https://blogmate.example/media/m1",
+		SEOTitle: "Go generics, a tour",
+	}, nil)
+	blogs.On("GetBlogById", "b2").Return(domain.Blog{BlogId: "b2", AuthorId: "u2", Title: "Plain", Content: "Just *text*.", CanonicalURL: "https://ada.dev/plain"}, nil)
+	blogs.On("GetBlogById", "missing").Return(domain.Blog{}, assert.AnError)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{UserId: "u1"}}).Return([]domain.User{{ID: "u1", Username: "ada", FirstName: "Ada", LastName: "Lovelace"}}, nil)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{UserId: "u2"}}).Return([]domain.User{{}}, assert.AnError)
+
+	meta, err := uc.GetBlogMeta("b1")
+	require.NoError(t, err)
+	assert.Equal(t, "Go generics, a tour", meta.Title)
+	assert.Equal(t, "A tour of generics.", meta.Description)
+	assert.Equal(t, "https://blogmate.example/blogs/b1", meta.CanonicalURL)
+	assert.Contains(t, meta.Tags, domain.MetaTag{Property: "og:image", Content: "https://blogmate.example/media/m1"})
+	assert.Contains(t, meta.Tags, domain.MetaTag{Property: "og:url", Content: "https://blogmate.example/blogs/b1"})
+	assert.Contains(t, meta.Tags, domain.MetaTag{Property: "article:published_time", Content: "2026-10-01T08:30:00Z"})
+	assert.Contains(t, meta.Tags, domain.MetaTag{Property: "article:author", Content: "https://blogmate.example/users/ada/profile"})
+	assert.Contains(t, meta.Tags, domain.MetaTag{Property: "article:tag", Content: "generics"})
+	assert.Contains(t, meta.Tags, domain.MetaTag{Name: "twitter:card", Content: "summary_large_image"})
+	assert.Equal(t, "BlogPosting", meta.JSONLD["@type"])
+	assert.Equal(t, map[string]interface{}{"@type": "Person", "name": "Ada Lovelace", "url": "https://blogmate.example/users/ada/profile"}, meta.JSONLD["author"])
+	assert.Equal(t, []string{"https://blogmate.example/media/m1"}, meta.JSONLD["image"])
+	assert.Equal(t, "go, generics", meta.JSONLD["keywords"])
+
+	meta, err = uc.GetBlogMeta("b2")
+	require.NoError(t, err)
+	assert.Equal(t, "Plain", meta.Title)
+	assert.Equal(t, "Just text.", meta.Description, "blogs saved without an excerpt get one")
+	assert.Equal(t, "https://ada.dev/plain", meta.CanonicalURL)
+	assert.Contains(t, meta.Tags, domain.MetaTag{Name: "twitter:card", Content: "summary"})
+	assert.NotContains(t, meta.JSONLD, "image")
+
+	_, err = uc.GetBlogMeta("missing")
+	assert.Error(t, err)
+}
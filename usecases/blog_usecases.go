@@ -1,22 +1,58 @@
 package usecase
 
 import (
+	"context"
+	"strings"
+
 	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type BlogUsecase struct {
 	blogRepository domain.BlogRepository
+	related        *relatedCache
+	embedder       infrastructure.Embedder
+	vectors        infrastructure.VectorStore
+	mentions       *MentionUsecase
+	notifications  *NotificationUsecase
+	broker         infrastructure.Broker
+	outbox         *OutboxUsecase
+	newsletter     *NewsletterUsecase
+	media          *MediaUsecase
+	siteURL        string
+	users          domain.UserRepository
 }
 
 func NewBlogUsecase(repo domain.BlogRepository) *BlogUsecase {
-	return &BlogUsecase{blogRepository: repo}
+	return &BlogUsecase{blogRepository: repo, related: newRelatedCache()}
 }
 
 func (uc *BlogUsecase) CreateBLog(blog domain.Blog) (domain.Blog, error) {
-	blog, err := uc.blogRepository.CreateBlog(blog)
+	if err := uc.prepareMeta(&blog, blog.AuthorId); err != nil {
+		return domain.Blog{}, err
+	}
+	if err := uc.checkMedia(blog.AuthorId, blog.Media); err != nil {
+		return domain.Blog{}, err
+	}
+	if blog.Excerpt == "" {
+		blog.Excerpt = makeExcerpt(blog.Content)
+	}
+	var created domain.Blog
+	err := uc.writeWithEvent(domain.EventBlogCreated, func(ctx context.Context) (string, interface{}, error) {
+		var err error
+		created, err = uc.blogRepository.CreateBlogWithContext(ctx, blog)
+		return created.BlogId, created, err
+	})
 	if err != nil {
 		return domain.Blog{}, err
 	}
+	blog = created
+	uc.related.invalidate()
+	uc.attachMedia(blog.AuthorId, blog.BlogId, blog.Media, blog.CoverMediaId)
+	uc.indexBlog(blog)
+	uc.recordMentions(blog.AuthorId, blog.BlogId, "", blog.Content)
+	uc.notifySubscribers(blog)
 	return blog, nil
 }
 
@@ -53,17 +89,62 @@ func (uc *BlogUsecase) FilterBlogs(filter domain.BlogFilterOption) ([]domain.Blo
 }
 
 func (uc *BlogUsecase) UpdateBLog(blogId string, updateBlog domain.Blog) (domain.Blog, error) {
-	blog, err := uc.blogRepository.UpdateBlog(blogId, updateBlog)
+	changesMedia := updateBlog.Media != nil || updateBlog.CoverMediaId != ""
+	changesExcerpt := updateBlog.Content != "" && updateBlog.Excerpt == ""
+	var existing domain.Blog
+	if changesMedia || changesExcerpt {
+		var err error
+		if existing, err = uc.blogRepository.GetBlogById(blogId); err != nil {
+			return domain.Blog{}, err
+		}
+	}
+	if err := uc.prepareMeta(&updateBlog, existing.AuthorId); err != nil {
+		return domain.Blog{}, err
+	}
+	if err := uc.checkMedia(existing.AuthorId, updateBlog.Media); err != nil {
+		return domain.Blog{}, err
+	}
+	// excerpts made from the content follow it, those written are kept
+	if changesExcerpt && (existing.Excerpt == "" || existing.Excerpt == makeExcerpt(existing.Content)) {
+		updateBlog.Excerpt = makeExcerpt(updateBlog.Content)
+	}
+	var blog domain.Blog
+	err := uc.writeWithEvent(domain.EventBlogUpdated, func(ctx context.Context) (string, interface{}, error) {
+		var err error
+		blog, err = uc.blogRepository.UpdateBlogWithContext(ctx, blogId, updateBlog)
+		// a blog is updated many times, each update is its own event
+		return blogId + ":" + primitive.NewObjectID().Hex(), blog, err
+	})
 	if err != nil {
 		return domain.Blog{}, err
 	}
+	uc.related.invalidate()
+	if changesMedia {
+		media, cover := updateBlog.Media, firstNonEmpty(updateBlog.CoverMediaId, existing.CoverMediaId)
+		if media == nil {
+			media = existing.Media
+		}
+		uc.attachMedia(existing.AuthorId, blogId, media, cover)
+	}
+	uc.indexBlog(blog)
+	if updateBlog.Content != "" {
+		uc.recordMentions(blog.AuthorId, blogId, "", blog.Content)
+	}
+	uc.publish("blog.updated", blog, infrastructure.BlogTopic(blogId))
 	return blog, nil
 }
 func (uc *BlogUsecase) DeleteBLog(blogId, authorId string) error {
-	err := uc.blogRepository.DeleteBlog(blogId, authorId)
+	err := uc.writeWithEvent(domain.EventBlogDeleted, func(ctx context.Context) (string, interface{}, error) {
+		return blogId, deletedRef{BlogId: blogId}, uc.blogRepository.DeleteBlogWithContext(ctx, blogId, authorId)
+	})
 	if err != nil {
 		return err
 	}
+	uc.related.invalidate()
+	uc.unindexBlog(blogId)
+	uc.forgetMentions(blogId, "")
+	uc.detachMedia(blogId)
+	uc.publish("blog.deleted", deletedRef{BlogId: blogId}, infrastructure.BlogTopic(blogId))
 	return nil
 }
 
@@ -72,6 +153,10 @@ func (uc *BlogUsecase) LikeBlog(blogId, userId string) (string, error) {
 	if err != nil {
 		return message, err
 	}
+	if strings.HasPrefix(message, "Added") {
+		uc.notifyBlogReaction(blogId, userId, "like")
+	}
+	uc.publishBlogReaction(blogId)
 	return message, nil
 }
 func (uc *BlogUsecase) DislikeBlog(blogId, userId string) (string, error) {
@@ -79,6 +164,10 @@ func (uc *BlogUsecase) DislikeBlog(blogId, userId string) (string, error) {
 	if err != nil {
 		return message, err
 	}
+	if strings.HasPrefix(message, "Added") {
+		uc.notifyBlogReaction(blogId, userId, "dislike")
+	}
+	uc.publishBlogReaction(blogId)
 	return message, err
 }
 func (uc *BlogUsecase) ViewBlogs(blogId, userId string) (string, error) {
@@ -89,12 +178,21 @@ func (uc *BlogUsecase) ViewBlogs(blogId, userId string) (string, error) {
 	return message, err
 }
 
-func (uc *BlogUsecase) AddComment(blogid string, comment domain.Comment) error {
-	err := uc.blogRepository.AddComment(blogid, comment)
+func (uc *BlogUsecase) AddComment(blogid string, comment domain.Comment) (domain.Comment, error) {
+	var added domain.Comment
+	err := uc.writeWithEvent(domain.EventCommentCreated, func(ctx context.Context) (string, interface{}, error) {
+		var err error
+		added, err = uc.blogRepository.AddCommentWithContext(ctx, blogid, comment)
+		return added.CommentId, added, err
+	})
 	if err != nil {
-		return err
+		return domain.Comment{}, err
 	}
-	return nil
+	comment = added
+	uc.recordMentions(comment.AuthorId, blogid, comment.CommentId, comment.Content)
+	uc.notifyComment(blogid, comment)
+	uc.publishComment("comment.created", blogid, comment)
+	return comment, nil
 }
 
 func (uc *BlogUsecase) GetAllComments(blogId string, opts domain.PaginationInfo) ([]domain.Comment, error) {
@@ -113,98 +211,69 @@ func (uc *BlogUsecase) GetCommentById(blogId, commentId string) (domain.Comment,
 	return comments, nil
 }
 func (uc *BlogUsecase) LikeComment(blogId, commentId, userId string) error {
-	err := uc.blogRepository.LikeOrDislikeComment(blogId, commentId, userId, 1)
+	added, err := uc.blogRepository.LikeOrDislikeComment(blogId, commentId, userId, 1)
 	if err != nil {
 		return err
 	}
+	if added {
+		uc.notifyCommentReaction(blogId, commentId, userId, "like")
+	}
+	uc.publishCommentReaction(blogId, commentId)
 	return nil
 }
 
 func (uc *BlogUsecase) DislikeComment(blogId, commentId, userId string) error {
-	err := uc.blogRepository.LikeOrDislikeComment(blogId, commentId, userId, -1)
+	added, err := uc.blogRepository.LikeOrDislikeComment(blogId, commentId, userId, -1)
 	if err != nil {
 		return err
 	}
+	if added {
+		uc.notifyCommentReaction(blogId, commentId, userId, "dislike")
+	}
+	uc.publishCommentReaction(blogId, commentId)
 	return nil
 }
 func (uc *BlogUsecase) ViewComment(blogId, commentId, userId string) error {
-	err := uc.blogRepository.LikeOrDislikeComment(blogId, commentId, userId, 0)
+	_, err := uc.blogRepository.LikeOrDislikeComment(blogId, commentId, userId, 0)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (uc *BlogUsecase) ReplyToComment(blogid, commentid string, reply domain.Reply) error {
-	err := uc.blogRepository.AddReply(blogid, commentid, reply)
-	if err != nil {
-		return err
-	}
-	return nil
+// ReplyToComment adds a child comment below commentid.
+func (uc *BlogUsecase) ReplyToComment(blogid, commentid string, reply domain.Comment) (domain.Comment, error) {
+	reply.ParentId = commentid
+	return uc.AddComment(blogid, reply)
 }
-func (uc *BlogUsecase) GetAllReplies(blogId, commentId string, opts domain.PaginationInfo) ([]domain.Reply, error) {
-	replies, err := uc.blogRepository.GetAllReplies(blogId, commentId, opts)
+func (uc *BlogUsecase) GetAllReplies(blogId, commentId string, opts domain.PaginationInfo) ([]domain.Comment, error) {
+	replies, err := uc.blogRepository.GetChildComments(blogId, commentId, opts)
 	if err != nil {
-		return []domain.Reply{}, err
+		return []domain.Comment{}, err
 	}
 	return replies, nil
 }
-func (uc *BlogUsecase) GetReplyById(blogId, commentId, replyId string) (domain.Reply, error) {
-	reply, err := uc.blogRepository.GetReplyById(blogId, commentId, replyId)
-	if err != nil {
-		return domain.Reply{}, err
-	}
-	return reply, nil
-}
-func (uc *BlogUsecase) LikeReply(blogId, commentId, replyId, userId string) error {
-	err := uc.blogRepository.LikeOrDislikeReply(blogId, commentId, replyId, userId, 1)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-func (uc *BlogUsecase) DislikeReply(blogId, commentId, replyId, userId string) error {
-	err := uc.blogRepository.LikeOrDislikeReply(blogId, commentId, replyId, userId, -1)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (uc *BlogUsecase) ViewReply(blogId, commentId, replyId, userId string) error {
-	err := uc.blogRepository.LikeOrDislikeReply(blogId, commentId, replyId, userId, 0)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (uc *BlogUsecase) UpdateReply(blogId, commentId, replyId, userId string, replyData domain.Reply) (domain.Reply, error) {
-	result, err := uc.blogRepository.UpdateReply(blogId, commentId, replyId, userId, replyData)
-	if err != nil {
-		return domain.Reply{}, err
-	}
-	return result, nil
-}
-func (uc *BlogUsecase) DeleteReply(blogId, commentId, replyId, userId string) error {
-	err := uc.blogRepository.DeleteReply(blogId, commentId, replyId, userId)
-	if err != nil {
-		return err
-	}
-	return nil
-}
 
-func (uc *BlogUsecase) UpdateComment(blogId, commentId, replyId, userId string, commentData domain.Comment) (domain.Comment, error) {
+func (uc *BlogUsecase) UpdateComment(blogId, commentId, userId string, commentData domain.Comment) (domain.Comment, error) {
 	result, err := uc.blogRepository.UpdateComment(blogId, commentId, userId, commentData)
 	if err != nil {
 		return domain.Comment{}, err
 	}
+	if commentData.Content != "" {
+		uc.recordMentions(userId, blogId, commentId, commentData.Content)
+	}
+	uc.publishComment("comment.updated", blogId, result)
 	return result, nil
 }
 func (uc *BlogUsecase) DeleteComment(blogId, commentId, userId string) error {
-	err := uc.blogRepository.DeleteComment(blogId, commentId, userId)
+	deleted, err := uc.blogRepository.DeleteComment(blogId, commentId, userId)
 	if err != nil {
 		return err
 	}
+	// the replies went with the comment, and their mentions with them
+	for _, id := range deleted {
+		uc.forgetMentions(blogId, id)
+	}
+	uc.publish("comment.deleted", deletedRef{BlogId: blogId, CommentId: commentId}, infrastructure.BlogTopic(blogId), infrastructure.ThreadTopic(commentId))
 	return nil
 }
@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"sort"
+
+	"github.com/yesetoda/BlogMate/domain"
+)
+
+const (
+	defaultThreadDepth = 3
+	maxThreadDepth     = 10
+)
+
+// GetCommentThread returns the comments of a blog as a tree. When rootId is
+// set only the subtree below that comment is returned. Comments deeper than
+// opts.MaxDepth levels below the root are left out; their parents still report
+// how many replies they have.
+func (uc *BlogUsecase) GetCommentThread(blogId, rootId string, opts domain.CommentThreadOption) ([]domain.CommentNode, error) {
+	depth := opts.MaxDepth
+	if depth <= 0 {
+		depth = defaultThreadDepth
+	}
+	if depth > maxThreadDepth {
+		depth = maxThreadDepth
+	}
+	comments, err := uc.blogRepository.GetCommentThread(blogId, rootId, depth)
+	if err != nil {
+		return []domain.CommentNode{}, err
+	}
+	return buildCommentTree(comments, rootId, opts.Sort), nil
+}
+
+func buildCommentTree(comments []domain.Comment, rootId, order string) []domain.CommentNode {
+	children := map[string][]domain.Comment{}
+	ids := map[string]bool{}
+	for _, c := range comments {
+		ids[c.CommentId] = true
+	}
+	var roots []domain.Comment
+	for _, c := range comments {
+		if c.CommentId == rootId || (rootId == "" && (c.ParentId == "" || !ids[c.ParentId])) {
+			roots = append(roots, c)
+			continue
+		}
+		children[c.ParentId] = append(children[c.ParentId], c)
+	}
+
+	var build func(level []domain.Comment) []domain.CommentNode
+	build = func(level []domain.Comment) []domain.CommentNode {
+		sortComments(level, order)
+		nodes := make([]domain.CommentNode, 0, len(level))
+		for _, c := range level {
+			nodes = append(nodes, domain.CommentNode{Comment: c, Children: build(children[c.CommentId])})
+		}
+		return nodes
+	}
+	return build(roots)
+}
+
+func sortComments(comments []domain.Comment, order string) {
+	sort.SliceStable(comments, func(i, j int) bool {
+		a, b := comments[i], comments[j]
+		switch order {
+		case domain.CommentSortNewest:
+			return a.Date.After(b.Date)
+		case domain.CommentSortOldest:
+			return a.Date.Before(b.Date)
+		default:
+			scoreA := len(a.Likes) - len(a.Dislikes)
+			scoreB := len(b.Likes) - len(b.Dislikes)
+			if scoreA == scoreB {
+				return a.Date.Before(b.Date)
+			}
+			return scoreA > scoreB
+		}
+	})
+}
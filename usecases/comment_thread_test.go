@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+func TestGetCommentThread(t *testing.T) {
+	now := time.Now()
+	comments := []domain.Comment{
+		{CommentId: "a", Path: "/a/", Depth: 0, Date: now, Likes: []string{"u1"}},
+		{CommentId: "b", Path: "/b/", Depth: 0, Date: now.Add(time.Minute), Likes: []string{"u1", "u2"}},
+		{CommentId: "c", ParentId: "a", Path: "/a/c/", Depth: 1, Date: now.Add(2 * time.Minute)},
+		{CommentId: "d", ParentId: "a", Path: "/a/d/", Depth: 1, Date: now.Add(3 * time.Minute)},
+		{CommentId: "e", ParentId: "c", Path: "/a/c/e/", Depth: 2, Date: now.Add(4 * time.Minute)},
+	}
+	repo := mocks.NewBlogRepository(t)
+	uc := NewBlogUsecase(repo)
+
+	t.Run("whole blog sorted by score", func(t *testing.T) {
+		repo.On("GetCommentThread", "blog", "", defaultThreadDepth).Return(comments, nil).Once()
+		thread, err := uc.GetCommentThread("blog", "", domain.CommentThreadOption{Sort: domain.CommentSortTop})
+		assert.NoError(t, err)
+		assert.Len(t, thread, 2)
+		assert.Equal(t, "b", thread[0].CommentId)
+		assert.Equal(t, "a", thread[1].CommentId)
+		assert.Len(t, thread[1].Children, 2)
+		assert.Equal(t, "e", thread[1].Children[0].Children[0].CommentId)
+	})
+
+	t.Run("subtree sorted by newest", func(t *testing.T) {
+		repo.On("GetCommentThread", "blog", "a", maxThreadDepth).Return([]domain.Comment{comments[0], comments[2], comments[3], comments[4]}, nil).Once()
+		thread, err := uc.GetCommentThread("blog", "a", domain.CommentThreadOption{MaxDepth: 50, Sort: domain.CommentSortNewest})
+		assert.NoError(t, err)
+		assert.Len(t, thread, 1)
+		assert.Equal(t, "d", thread[0].Children[0].CommentId)
+		assert.Equal(t, "c", thread[0].Children[1].CommentId)
+	})
+}
@@ -0,0 +1,267 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yesetoda/BlogMate/config"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+)
+
+const (
+	// digestSize is the number of posts in a digest.
+	digestSize = 5
+	// digestSummaryLength is the number of characters of a post shown in a digest.
+	digestSummaryLength = 160
+)
+
+// DigestUsecase sends the daily and weekly digests of the top posts from the
+// authors and tags a user follows.
+type DigestUsecase struct {
+	digestRepository domain.DigestRepository
+	blogRepository   domain.BlogRepository
+	userRepository   domain.UserRepository
+	mailer           infrastructure.Mailer
+	now              func() time.Time
+}
+
+func NewDigestUsecase(digests domain.DigestRepository, blogs domain.BlogRepository, users domain.UserRepository, mailer infrastructure.Mailer) *DigestUsecase {
+	return &DigestUsecase{
+		digestRepository: digests,
+		blogRepository:   blogs,
+		userRepository:   users,
+		mailer:           mailer,
+		now:              time.Now,
+	}
+}
+
+func (uc *DigestUsecase) GetSubscription(userId string) (domain.DigestSubscription, error) {
+	return uc.digestRepository.GetSubscription(userId)
+}
+
+// UpdateSubscription replaces the digest settings of the user. The
+// unsubscribe token is kept, or created on the first subscription.
+func (uc *DigestUsecase) UpdateSubscription(userId string, update domain.DigestSubscription) (domain.DigestSubscription, error) {
+	if update.Frequency == "" {
+		update.Frequency = domain.DigestWeekly
+	}
+	if update.Frequency != domain.DigestDaily && update.Frequency != domain.DigestWeekly {
+		return domain.DigestSubscription{}, fmt.Errorf("frequency must be %s or %s", domain.DigestDaily, domain.DigestWeekly)
+	}
+	current, err := uc.digestRepository.GetSubscription(userId)
+	if err != nil {
+		return domain.DigestSubscription{}, err
+	}
+	update.UserId = userId
+	update.UnsubscribeToken = current.UnsubscribeToken
+	if update.UnsubscribeToken == "" {
+		update.UnsubscribeToken, err = unsubscribeToken()
+		if err != nil {
+			return domain.DigestSubscription{}, err
+		}
+	}
+	if update.Authors == nil {
+		update.Authors = []string{}
+	}
+	if update.Tags == nil {
+		update.Tags = []string{}
+	}
+	return uc.digestRepository.SaveSubscription(update)
+}
+
+// Unsubscribe turns off the digests of the user the token was sent to.
+func (uc *DigestUsecase) Unsubscribe(token string) error {
+	subscription, err := uc.digestRepository.GetSubscriptionByToken(token)
+	if err != nil {
+		return err
+	}
+	subscription.Enabled = false
+	_, err = uc.digestRepository.SaveSubscription(subscription)
+	return err
+}
+
+func unsubscribeToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// digestPeriod names the day or the ISO week of t, digests of the same period
+// share the name and are only sent once.
+func digestPeriod(frequency string, t time.Time) string {
+	t = t.UTC()
+	if frequency == domain.DigestDaily {
+		return t.Format("2006-01-02")
+	}
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+func digestWindow(frequency string) time.Duration {
+	if frequency == domain.DigestDaily {
+		return 24 * time.Hour
+	}
+	return 7 * 24 * time.Hour
+}
+
+// pickDigestBlogs returns the most popular of blogs that were written by a
+// followed author or have a followed tag, or the most popular ones overall
+// when none match. blogs is sorted by popularity.
+func pickDigestBlogs(subscription domain.DigestSubscription, blogs []domain.Blog) []domain.Blog {
+	authors := map[string]bool{}
+	for _, author := range subscription.Authors {
+		authors[author] = true
+	}
+	tags := map[string]bool{}
+	for _, tag := range subscription.Tags {
+		tags[tag] = true
+	}
+	followed := []domain.Blog{}
+	for _, blog := range blogs {
+		match := authors[blog.AuthorId]
+		for _, tag := range blog.Tags {
+			match = match || tags[tag]
+		}
+		if match {
+			followed = append(followed, blog)
+		}
+	}
+	if len(followed) == 0 {
+		followed = blogs
+	}
+	if len(followed) > digestSize {
+		followed = followed[:digestSize]
+	}
+	return followed
+}
+
+// SendDue sends the digests of the current day and week that were not sent
+// yet and returns how many were sent.
+func (uc *DigestUsecase) SendDue() (int, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return 0, err
+	}
+	now := uc.now()
+	popular, err := uc.blogRepository.FindPopularBlog()
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	var errs []error
+	for _, frequency := range []string{domain.DigestDaily, domain.DigestWeekly} {
+		subscriptions, err := uc.digestRepository.GetSubscriptions(frequency)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		since := now.Add(-digestWindow(frequency))
+		recent := []domain.Blog{}
+		for _, blog := range popular {
+			if blog.Date.After(since) {
+				recent = append(recent, blog)
+			}
+		}
+		period := digestPeriod(frequency, now)
+		for _, subscription := range subscriptions {
+			ok, err := uc.send(cfg.Port, subscription, period, pickDigestBlogs(subscription, recent))
+			if err != nil {
+				log.Println("sending the digest of", subscription.UserId, "failed:", err)
+				errs = append(errs, err)
+			}
+			if ok {
+				sent++
+			}
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (uc *DigestUsecase) send(baseURL string, subscription domain.DigestSubscription, period string, blogs []domain.Blog) (bool, error) {
+	if len(blogs) == 0 {
+		return false, nil
+	}
+	users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{UserId: subscription.UserId}})
+	if err != nil {
+		return false, err
+	}
+	if len(users) == 0 || !users[0].IsActive {
+		return false, nil
+	}
+	user := users[0]
+
+	entry := domain.DigestLog{
+		LogId:     subscription.UserId + ":" + period,
+		UserId:    subscription.UserId,
+		Frequency: subscription.Frequency,
+		Period:    period,
+		SentAt:    uc.now(),
+	}
+	data := infrastructure.EmailData{
+		Name:            displayName(user),
+		Title:           "Top posts of the week",
+		UnsubscribeLink: baseURL + "/digest/unsubscribe?token=" + subscription.UnsubscribeToken,
+	}
+	if subscription.Frequency == domain.DigestDaily {
+		data.Title = "Top posts of the day"
+	}
+	for _, blog := range blogs {
+		entry.BlogIds = append(entry.BlogIds, blog.BlogId)
+		data.Items = append(data.Items, infrastructure.EmailItem{
+			Title:   blog.Title,
+			Summary: summarize(blog.Content, digestSummaryLength),
+			Link:    baseURL + "/blogs/" + blog.BlogId,
+		})
+	}
+	email, err := infrastructure.RenderEmail(infrastructure.DigestEmail, user.Locale, data)
+	if err != nil {
+		return false, err
+	}
+	email.To = []string{user.Email}
+
+	logged, err := uc.digestRepository.LogSend(entry)
+	if err != nil || !logged {
+		return false, err
+	}
+	if err := uc.mailer.Send(email); err != nil {
+		// forget the send so that the next run tries again
+		if rerr := uc.digestRepository.RemoveLog(entry.LogId); rerr != nil {
+			log.Println("removing the digest log", entry.LogId, "failed:", rerr)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func summarize(content string, length int) string {
+	runes := []rune(content)
+	if len(runes) <= length {
+		return content
+	}
+	return string(runes[:length]) + "…"
+}
+
+// Run sends the due digests every interval until stop is closed. Daily
+// digests go out on the first run of each day and weekly ones on the first
+// run of each ISO week, in UTC.
+func (uc *DigestUsecase) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := uc.SendDue(); err != nil {
+			log.Println("sending digests failed:", err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+type failingMailer struct{}
+
+func (failingMailer) Send(infrastructure.Email) error {
+	return errors.New("smtp is down")
+}
+
+func TestPickDigestBlogs(t *testing.T) {
+	blogs := []domain.Blog{
+		{BlogId: "b1", AuthorId: "a1", Tags: []string{"go"}},
+		{BlogId: "b2", AuthorId: "a2", Tags: []string{"rust"}},
+		{BlogId: "b3", AuthorId: "a3", Tags: []string{"go"}},
+	}
+
+	picked := pickDigestBlogs(domain.DigestSubscription{Authors: []string{"a2"}, Tags: []string{"go"}}, blogs)
+	assert.Len(t, picked, 3)
+
+	picked = pickDigestBlogs(domain.DigestSubscription{Tags: []string{"rust"}}, blogs)
+	assert.Equal(t, []domain.Blog{blogs[1]}, picked)
+
+	// nothing followed was published, trending posts are sent instead
+	picked = pickDigestBlogs(domain.DigestSubscription{Authors: []string{"a9"}}, blogs)
+	assert.Equal(t, blogs, picked)
+}
+
+func TestDigestPeriod(t *testing.T) {
+	at := time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2026-10-18", digestPeriod(domain.DigestDaily, at))
+	assert.Equal(t, "2026-W42", digestPeriod(domain.DigestWeekly, at))
+}
+
+func TestSendDueDigests(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	digests := mocks.NewDigestRepository(t)
+	blogs := mocks.NewBlogRepository(t)
+	users := mocks.NewUserRepository(t)
+	mailer := infrastructure.NewMemoryMailer()
+	uc := NewDigestUsecase(digests, blogs, users, mailer)
+	uc.now = func() time.Time { return now }
+
+	blogs.On("FindPopularBlog").Return([]domain.Blog{
+		{BlogId: "b1", Title: "Fresh", Content: "content", AuthorId: "a1", Date: now.Add(-2 * time.Hour)},
+		{BlogId: "b2", Title: "Old", AuthorId: "a1", Date: now.Add(-30 * 24 * time.Hour)},
+	}, nil)
+	subscription := domain.DigestSubscription{UserId: "u1", Enabled: true, Frequency: domain.DigestWeekly, UnsubscribeToken: "tok"}
+	digests.On("GetSubscriptions", domain.DigestDaily).Return([]domain.DigestSubscription{}, nil)
+	digests.On("GetSubscriptions", domain.DigestWeekly).Return([]domain.DigestSubscription{subscription}, nil)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{UserId: "u1"}}).Return([]domain.User{{ID: "u1", Email: "u1@example.com", Username: "u1", IsActive: true}}, nil)
+	digests.On("LogSend", mock.MatchedBy(func(entry domain.DigestLog) bool {
+		return entry.LogId == "u1:2026-W42" && len(entry.BlogIds) == 1 && entry.BlogIds[0] == "b1"
+	})).Return(true, nil).Once()
+	digests.On("LogSend", mock.Anything).Return(false, nil).Once()
+
+	sent, err := uc.SendDue()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	email, ok := mailer.Last()
+	assert.True(t, ok)
+	assert.Equal(t, []string{"u1@example.com"}, email.To)
+	assert.Contains(t, email.Text, "Fresh")
+	assert.NotContains(t, email.Text, "Old")
+	assert.Contains(t, email.HTML, "/digest/unsubscribe?token=tok")
+
+	// already sent this week
+	sent, err = uc.SendDue()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Len(t, mailer.Sent(), 1)
+}
+
+func TestFailedDigestIsRetried(t *testing.T) {
+	digests := mocks.NewDigestRepository(t)
+	users := mocks.NewUserRepository(t)
+	uc := NewDigestUsecase(digests, mocks.NewBlogRepository(t), users, failingMailer{})
+
+	users.On("Get", mock.Anything).Return([]domain.User{{ID: "u1", Email: "u1@example.com", IsActive: true}}, nil)
+	digests.On("LogSend", mock.Anything).Return(true, nil)
+	digests.On("RemoveLog", "u1:2026-10-18").Return(nil).Once()
+
+	subscription := domain.DigestSubscription{UserId: "u1", Enabled: true, Frequency: domain.DigestDaily}
+	ok, err := uc.send("", subscription, "2026-10-18", []domain.Blog{{BlogId: "b1", Title: "Fresh"}})
+	assert.Error(t, err)
+	assert.False(t, ok)
+}
+
+func TestUpdateDigestSubscription(t *testing.T) {
+	digests := mocks.NewDigestRepository(t)
+	uc := NewDigestUsecase(digests, mocks.NewBlogRepository(t), mocks.NewUserRepository(t), infrastructure.NewMemoryMailer())
+
+	_, err := uc.UpdateSubscription("u1", domain.DigestSubscription{Enabled: true, Frequency: "hourly"})
+	assert.Error(t, err)
+
+	digests.On("GetSubscription", "u1").Return(domain.DigestSubscription{UserId: "u1", UnsubscribeToken: "kept"}, nil)
+	digests.On("SaveSubscription", mock.Anything).Return(func(s domain.DigestSubscription) (domain.DigestSubscription, error) {
+		return s, nil
+	})
+	subscription, err := uc.UpdateSubscription("u1", domain.DigestSubscription{Enabled: true, Tags: []string{"go"}})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DigestWeekly, subscription.Frequency)
+	assert.Equal(t, "kept", subscription.UnsubscribeToken)
+	assert.Equal(t, []string{}, subscription.Authors)
+}
@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+)
+
+const (
+	// LoginLockout is how long an account is locked after too many failed
+	// logins.
+	LoginLockout = 15 * time.Minute
+	// loginFailureWindow is how long failures are remembered after the last
+	// one.
+	loginFailureWindow = time.Hour
+	loginBackoffBase   = time.Second
+	loginBackoffMax    = LoginLockout
+)
+
+// loginLimit is when failed logins start to be slowed down and locked.
+type loginLimit struct {
+	free      int
+	lockAfter int
+}
+
+var (
+	accountLoginLimit = loginLimit{free: 3, lockAfter: 10}
+	// an address is shared by many users behind a NAT, so it fails more
+	// before being slowed down
+	ipLoginLimit = loginLimit{free: 20, lockAfter: 100}
+)
+
+// LoginGuardUsecase slows down and locks out repeated failed logins, per
+// account and per IP address.
+type LoginGuardUsecase struct {
+	loginAttemptRepository domain.LoginAttemptRepository
+	userRepository         domain.UserRepository
+	outbox                 *OutboxUsecase
+	captchaAfter           int
+	now                    func() time.Time
+}
+
+func NewLoginGuardUsecase(attempts domain.LoginAttemptRepository, users domain.UserRepository) *LoginGuardUsecase {
+	return &LoginGuardUsecase{loginAttemptRepository: attempts, userRepository: users, now: time.Now}
+}
+
+// SetOutbox sends the lockout emails through the outbox instead of inline.
+func (uc *LoginGuardUsecase) SetOutbox(outbox *OutboxUsecase) {
+	uc.outbox = outbox
+}
+
+// SetCaptchaAfter tells clients to show a CAPTCHA once an account failed to
+// log in that many times. Zero never asks for one.
+func (uc *LoginGuardUsecase) SetCaptchaAfter(failures int) {
+	uc.captchaAfter = failures
+}
+
+// Check refuses a login, before its password is checked, while the account
+// or the address is locked or must wait after its last failure.
+func (uc *LoginGuardUsecase) Check(username, email, ip string) *domain.LoginBlocked {
+	key, _ := uc.accountKey(username, email)
+	return uc.check(key, ip)
+}
+
+// CheckUser refuses a later step of a login, e.g. its second factor, while
+// the user or the address is locked or must wait after its last failure.
+func (uc *LoginGuardUsecase) CheckUser(userId, ip string) *domain.LoginBlocked {
+	return uc.check(userAttemptsKey(userId), ip)
+}
+
+func (uc *LoginGuardUsecase) check(key, ip string) *domain.LoginBlocked {
+	for _, check := range []struct {
+		key   string
+		limit loginLimit
+	}{{key, accountLoginLimit}, {ipAttemptsKey(ip), ipLoginLimit}} {
+		attempts, err := uc.loginAttemptRepository.GetLoginAttempts(check.key)
+		if err != nil {
+			// logins keep working when the counters cannot be read
+			log.Println("reading the failed logins of", check.key, "failed:", err)
+			continue
+		}
+		if blocked := uc.blocked(attempts, check.limit); blocked != nil {
+			blocked.CaptchaRequired = uc.captchaRequired(attempts.Failures)
+			return blocked
+		}
+	}
+	return nil
+}
+
+// CheckIP refuses a request while the address is locked or must wait after
+// its last failure, e.g. to refresh tokens.
+func (uc *LoginGuardUsecase) CheckIP(ip string) *domain.LoginBlocked {
+	attempts, err := uc.loginAttemptRepository.GetLoginAttempts(ipAttemptsKey(ip))
+	if err != nil {
+		log.Println("reading the failed logins of", ip, "failed:", err)
+		return nil
+	}
+	return uc.blocked(attempts, ipLoginLimit)
+}
+
+func (uc *LoginGuardUsecase) blocked(attempts domain.LoginAttempts, limit loginLimit) *domain.LoginBlocked {
+	now := uc.now()
+	if now.Before(attempts.LockedUntil) {
+		return &domain.LoginBlocked{
+			Error:      "too many failed logins, try again later",
+			RetryAfter: retryAfter(attempts.LockedUntil.Sub(now)),
+			Locked:     true,
+		}
+	}
+	if attempts.Failures < limit.free {
+		return nil
+	}
+	if next := attempts.LastFailureAt.Add(loginBackoff(attempts.Failures - limit.free)); now.Before(next) {
+		return &domain.LoginBlocked{
+			Error:      "too many failed logins, wait before trying again",
+			RetryAfter: retryAfter(next.Sub(now)),
+		}
+	}
+	return nil
+}
+
+// loginBackoff doubles the wait after each failure beyond the free ones.
+func loginBackoff(extraFailures int) time.Duration {
+	if extraFailures > 20 {
+		return loginBackoffMax
+	}
+	wait := loginBackoffBase << extraFailures
+	if wait > loginBackoffMax {
+		return loginBackoffMax
+	}
+	return wait
+}
+
+// retryAfter rounds the wait up to whole seconds, for the Retry-After header.
+func retryAfter(wait time.Duration) int {
+	return int((wait + time.Second - 1) / time.Second)
+}
+
+// Failed counts a failed login of the account from the address, locks them
+// when they failed too often and tells whether the next login needs a
+// CAPTCHA.
+func (uc *LoginGuardUsecase) Failed(username, email, ip string) bool {
+	key, user := uc.accountKey(username, email)
+	failures := uc.fail(key, accountLoginLimit, user)
+	uc.fail(ipAttemptsKey(ip), ipLoginLimit, nil)
+	return uc.captchaRequired(failures)
+}
+
+// FailedUser counts a failed later step of a login of the user, e.g. a
+// wrong second factor, like Failed does for the password.
+func (uc *LoginGuardUsecase) FailedUser(userId, ip string) bool {
+	var user *domain.User
+	users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{UserId: userId}})
+	if err == nil && len(users) > 0 && users[0].ID != "" {
+		user = &users[0]
+	}
+	failures := uc.fail(userAttemptsKey(userId), accountLoginLimit, user)
+	uc.fail(ipAttemptsKey(ip), ipLoginLimit, nil)
+	return uc.captchaRequired(failures)
+}
+
+// FailedIP counts a failed request from the address, e.g. with an invalid
+// refresh token.
+func (uc *LoginGuardUsecase) FailedIP(ip string) {
+	uc.fail(ipAttemptsKey(ip), ipLoginLimit, nil)
+}
+
+func (uc *LoginGuardUsecase) fail(key string, limit loginLimit, user *domain.User) int {
+	now := uc.now()
+	attempts, err := uc.loginAttemptRepository.GetLoginAttempts(key)
+	if err == nil && attempts.Failures > 0 && now.Sub(attempts.LastFailureAt) > loginFailureWindow {
+		err = uc.loginAttemptRepository.DeleteLoginAttempts(key)
+	}
+	if err == nil {
+		attempts, err = uc.loginAttemptRepository.AddLoginFailure(key, now, now.Add(loginFailureWindow))
+	}
+	if err != nil {
+		log.Println("counting a failed login of", key, "failed:", err)
+		return 0
+	}
+	if attempts.Failures < limit.lockAfter {
+		return attempts.Failures
+	}
+	until := now.Add(LoginLockout)
+	if err := uc.loginAttemptRepository.LockLoginAttempts(key, until); err != nil {
+		log.Println("locking", key, "failed:", err)
+	}
+	// the owner hears about it once, not at every failure that follows
+	if user != nil && attempts.Failures == limit.lockAfter {
+		if err := uc.notifyLockout(*user, until); err != nil {
+			log.Println("sending the lockout email of", user.ID, "failed:", err)
+		}
+	}
+	return attempts.Failures
+}
+
+func (uc *LoginGuardUsecase) captchaRequired(failures int) bool {
+	return uc.captchaAfter > 0 && failures >= uc.captchaAfter
+}
+
+// Succeeded forgets the failed logins of the account. Those of the address
+// are kept, so one known password does not reset the counter of an attacker.
+func (uc *LoginGuardUsecase) Succeeded(username, email string) {
+	key, _ := uc.accountKey(username, email)
+	uc.forget(key)
+}
+
+// SucceededUser forgets the failed logins of the user once they completed
+// every step of a login.
+func (uc *LoginGuardUsecase) SucceededUser(userId string) {
+	uc.forget(userAttemptsKey(userId))
+}
+
+func (uc *LoginGuardUsecase) forget(key string) {
+	if err := uc.loginAttemptRepository.DeleteLoginAttempts(key); err != nil {
+		log.Println("forgetting the failed logins of", key, "failed:", err)
+	}
+}
+
+// LoginBlockedError refuses a step of a login while its user or address is
+// blocked.
+type LoginBlockedError struct {
+	Blocked *domain.LoginBlocked
+}
+
+func (e *LoginBlockedError) Error() string {
+	return e.Blocked.Error
+}
+
+// Unlock lifts the lockout of the user and forgets their failed logins.
+func (uc *LoginGuardUsecase) Unlock(userId string) error {
+	users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{UserId: userId}})
+	if err != nil || len(users) == 0 || users[0].ID == "" {
+		return fmt.Errorf("user not found")
+	}
+	return uc.loginAttemptRepository.DeleteLoginAttempts(userAttemptsKey(userId))
+}
+
+// accountKey counts the failures of existing users by id, whether they log
+// in with their username or email, and those of unknown logins by name.
+func (uc *LoginGuardUsecase) accountKey(username, email string) (string, *domain.User) {
+	filter := domain.UserFilter{Username: username}
+	if username == "" {
+		filter = domain.UserFilter{Email: email}
+	}
+	users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: filter})
+	if err == nil && len(users) > 0 && users[0].ID != "" {
+		return userAttemptsKey(users[0].ID), &users[0]
+	}
+	return "login:" + strings.ToLower(filter.Username+filter.Email), nil
+}
+
+func userAttemptsKey(userId string) string {
+	return "user:" + userId
+}
+
+func ipAttemptsKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (uc *LoginGuardUsecase) notifyLockout(user domain.User, until time.Time) error {
+	payload := domain.OutboxEmailPayload{
+		To:       user.Email,
+		Template: infrastructure.AccountLockedEmail,
+		Locale:   user.Locale,
+		Name:     displayName(user),
+		Body:     fmt.Sprintf("Logging in is blocked until %s.", until.UTC().Format("15:04 MST on 2 January 2006")),
+	}
+	if uc.outbox != nil {
+		return uc.outbox.Enqueue(context.Background(), domain.OutboxEmail, "account-locked:"+user.ID+":"+until.Format(time.RFC3339), payload)
+	}
+	data := infrastructure.EmailData{Name: payload.Name, Body: payload.Body}
+	return infrastructure.SendTemplatedEmail(payload.To, payload.Template, payload.Locale, data)
+}
@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+// storedLoginAttempts backs the mock repository with a map.
+func storedLoginAttempts(attempts *mocks.LoginAttemptRepository) map[string]domain.LoginAttempts {
+	stored := map[string]domain.LoginAttempts{}
+	attempts.On("GetLoginAttempts", mock.Anything).Return(func(key string) (domain.LoginAttempts, error) {
+		found, ok := stored[key]
+		if !ok {
+			found.Key = key
+		}
+		return found, nil
+	}).Maybe()
+	attempts.On("AddLoginFailure", mock.Anything, mock.Anything, mock.Anything).Return(func(key string, at, expiresAt time.Time) (domain.LoginAttempts, error) {
+		found := stored[key]
+		found.Key, found.Failures, found.LastFailureAt, found.ExpiresAt = key, found.Failures+1, at, expiresAt
+		stored[key] = found
+		return found, nil
+	}).Maybe()
+	attempts.On("LockLoginAttempts", mock.Anything, mock.Anything).Return(func(key string, until time.Time) error {
+		found := stored[key]
+		found.LockedUntil = until
+		stored[key] = found
+		return nil
+	}).Maybe()
+	attempts.On("DeleteLoginAttempts", mock.Anything).Return(func(key string) error {
+		delete(stored, key)
+		return nil
+	}).Maybe()
+	return stored
+}
+
+func TestLoginGuardBackoffAndLockout(t *testing.T) {
+	mailer := infrastructure.NewMemoryMailer()
+	infrastructure.SetDefaultMailer(mailer)
+	defer infrastructure.SetDefaultMailer(nil)
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	attempts := mocks.NewLoginAttemptRepository(t)
+	stored := storedLoginAttempts(attempts)
+	users := mocks.NewUserRepository(t)
+	user := domain.User{ID: "u1", Username: "ada", Email: "ada@example.com", IsActive: true}
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Username: "ada"}}).Return([]domain.User{user}, nil)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Email: "ada@example.com"}}).Return([]domain.User{user}, nil)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{UserId: "u1"}}).Return([]domain.User{user}, nil)
+	uc := NewLoginGuardUsecase(attempts, users)
+	uc.now = func() time.Time { return now }
+	uc.SetCaptchaAfter(5)
+
+	for i := 0; i < accountLoginLimit.free; i++ {
+		assert.Nil(t, uc.Check("ada", "", "10.0.0.1"))
+		assert.False(t, uc.Failed("ada", "", "10.0.0.1"))
+	}
+	blocked := uc.Check("", "ada@example.com", "10.0.0.2")
+	if assert.NotNil(t, blocked, "the username and the email share the counter") {
+		assert.Equal(t, 1, blocked.RetryAfter)
+		assert.False(t, blocked.Locked)
+	}
+	now = now.Add(time.Second)
+	assert.Nil(t, uc.Check("ada", "", "10.0.0.1"))
+	uc.Failed("ada", "", "10.0.0.1")
+	assert.Equal(t, 2, uc.Check("ada", "", "10.0.0.1").RetryAfter, "the wait doubles")
+
+	captcha := false
+	for failures := 5; failures <= accountLoginLimit.lockAfter; failures++ {
+		now = now.Add(loginBackoffMax)
+		captcha = uc.Failed("ada", "", "10.0.0.1")
+	}
+	assert.True(t, captcha)
+	blocked = uc.Check("ada", "", "10.0.0.1")
+	if assert.NotNil(t, blocked) {
+		assert.True(t, blocked.Locked)
+		assert.True(t, blocked.CaptchaRequired)
+		assert.Equal(t, int(LoginLockout/time.Second), blocked.RetryAfter)
+	}
+	assert.Len(t, mailer.Sent(), 1)
+	email, _ := mailer.Last()
+	assert.Equal(t, []string{"ada@example.com"}, email.To)
+
+	assert.NoError(t, uc.Unlock("u1"))
+	assert.Nil(t, uc.Check("ada", "", "10.0.0.1"))
+	assert.Equal(t, accountLoginLimit.lockAfter, stored["ip:10.0.0.1"].Failures, "unlocking the user keeps the counter of the address")
+}
+
+func TestLoginGuardForgetsOldFailures(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	attempts := mocks.NewLoginAttemptRepository(t)
+	stored := storedLoginAttempts(attempts)
+	users := mocks.NewUserRepository(t)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Username: "Nobody"}}).Return([]domain.User{{}}, assert.AnError)
+	uc := NewLoginGuardUsecase(attempts, users)
+	uc.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		uc.Failed("Nobody", "", "10.0.0.1")
+	}
+	assert.Equal(t, 3, stored["login:nobody"].Failures, "unknown logins are counted too")
+	now = now.Add(loginFailureWindow + time.Minute)
+	uc.Failed("Nobody", "", "10.0.0.1")
+	assert.Equal(t, 1, stored["login:nobody"].Failures)
+
+	uc.Succeeded("Nobody", "")
+	assert.NotContains(t, stored, "login:nobody")
+	assert.Contains(t, stored, "ip:10.0.0.1")
+}
+
+func TestLoginGuardIP(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	attempts := mocks.NewLoginAttemptRepository(t)
+	storedLoginAttempts(attempts)
+	uc := NewLoginGuardUsecase(attempts, mocks.NewUserRepository(t))
+	uc.now = func() time.Time { return now }
+
+	for i := 0; i < ipLoginLimit.free; i++ {
+		assert.Nil(t, uc.CheckIP("10.0.0.1"))
+		uc.FailedIP("10.0.0.1")
+	}
+	assert.NotNil(t, uc.CheckIP("10.0.0.1"))
+	assert.Nil(t, uc.CheckIP("10.0.0.2"))
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yesetoda/BlogMate/config"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+)
+
+// MagicLinkTTL is how long an emailed login link works.
+const MagicLinkTTL = 15 * time.Minute
+
+const magicLinkSent = "If an account exists for this email, a login link was sent to it"
+
+var errInvalidMagicLink = errors.New("the login link is invalid or expired, please ask for a new one")
+
+// MagicLinkUsecase logs users in with single-use links sent to their email
+// instead of a password.
+type MagicLinkUsecase struct {
+	magicLinkRepository domain.MagicLinkRepository
+	userRepository      domain.UserRepository
+	outbox              *OutboxUsecase
+	now                 func() time.Time
+}
+
+func NewMagicLinkUsecase(links domain.MagicLinkRepository, users domain.UserRepository) *MagicLinkUsecase {
+	return &MagicLinkUsecase{magicLinkRepository: links, userRepository: users, now: time.Now}
+}
+
+// SetOutbox sends the login emails through the outbox instead of inline.
+func (uc *MagicLinkUsecase) SetOutbox(outbox *OutboxUsecase) {
+	uc.outbox = outbox
+}
+
+// Request emails a login link to the user with the email. The answer is the
+// same whether they exist or not, and its device token is needed with the
+// link to log in, so a link opened elsewhere does not log anyone in.
+func (uc *MagicLinkUsecase) Request(email, device, ip string) (domain.MagicLinkRequest, error) {
+	deviceToken, deviceHash, err := infrastructure.NewRefreshToken()
+	if err != nil {
+		return domain.MagicLinkRequest{}, err
+	}
+	response := domain.MagicLinkRequest{
+		Message:     magicLinkSent,
+		DeviceToken: deviceToken,
+		ExpiresIn:   int(MagicLinkTTL / time.Second),
+	}
+	users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{Email: email}})
+	if err != nil || len(users) == 0 || users[0].ID == "" || !users[0].IsActive {
+		return response, nil
+	}
+	user := users[0]
+
+	token, hash, err := infrastructure.NewRefreshToken()
+	if err != nil {
+		return domain.MagicLinkRequest{}, err
+	}
+	err = uc.magicLinkRepository.CreateMagicLink(domain.MagicLink{
+		LinkId:     hash,
+		UserId:     user.ID,
+		DeviceHash: deviceHash,
+		Device:     device,
+		IP:         ip,
+		CreatedAt:  uc.now(),
+		ExpiresAt:  uc.now().Add(MagicLinkTTL),
+	})
+	if err != nil {
+		return domain.MagicLinkRequest{}, err
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return domain.MagicLinkRequest{}, err
+	}
+	payload := domain.OutboxEmailPayload{
+		To:       user.Email,
+		Template: infrastructure.MagicLinkEmail,
+		Locale:   user.Locale,
+		Name:     displayName(user),
+		Body:     fmt.Sprintf("It was asked from %s (%s).", device, ip),
+		Link:     magicLinkPage(cfg) + "token=" + url.QueryEscape(token),
+	}
+	if uc.outbox != nil {
+		err = uc.outbox.Enqueue(context.Background(), domain.OutboxEmail, "magic-link:"+hash, payload)
+	} else {
+		data := infrastructure.EmailData{Name: payload.Name, Body: payload.Body, Link: payload.Link}
+		err = infrastructure.SendTemplatedEmail(payload.To, payload.Template, payload.Locale, data)
+	}
+	if err != nil {
+		return domain.MagicLinkRequest{}, err
+	}
+	return response, nil
+}
+
+// magicLinkPage is the client page the emailed link opens, ready for its
+// query. Only the client that asked for the link holds the device token, so
+// the link cannot be redeemed by the API directly.
+func magicLinkPage(cfg *config.Config) string {
+	page := cfg.MagicLink.URL
+	if page == "" {
+		page = cfg.Port + "/magic-link"
+	}
+	if strings.Contains(page, "?") {
+		return page + "&"
+	}
+	return page + "?"
+}
+
+// Redeem uses up the link of the token and returns the user to log in. The
+// device token must be the one given to the device that asked for the link.
+func (uc *MagicLinkUsecase) Redeem(token, deviceToken string) (domain.User, error) {
+	if token == "" || deviceToken == "" {
+		return domain.User{}, errInvalidMagicLink
+	}
+	link, err := uc.magicLinkRepository.TakeMagicLink(infrastructure.HashRefreshToken(token))
+	if err != nil || !link.ExpiresAt.After(uc.now()) {
+		return domain.User{}, errInvalidMagicLink
+	}
+	if subtle.ConstantTimeCompare([]byte(infrastructure.HashRefreshToken(deviceToken)), []byte(link.DeviceHash)) != 1 {
+		return domain.User{}, errors.New("open the login link on the device you asked for it on")
+	}
+	users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{UserId: link.UserId}})
+	if err != nil || len(users) == 0 || !users[0].IsActive {
+		return domain.User{}, errInvalidMagicLink
+	}
+	return users[0], nil
+}
@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+// emailedToken reads the token of the login link from the last email.
+func emailedToken(t *testing.T, mailer *infrastructure.MemoryMailer) string {
+	return emailedLink(t, mailer).Query().Get("token")
+}
+
+// emailedLink reads the login link from the last email.
+func emailedLink(t *testing.T, mailer *infrastructure.MemoryMailer) *url.URL {
+	email, ok := mailer.Last()
+	assert.True(t, ok)
+	for _, field := range strings.Fields(email.Text) {
+		if strings.Contains(field, "token=") {
+			link, err := url.Parse(field)
+			assert.NoError(t, err)
+			return link
+		}
+	}
+	t.Fatal("no login link in", email.Text)
+	return nil
+}
+
+func TestMagicLinkLogin(t *testing.T) {
+	mailer := infrastructure.NewMemoryMailer()
+	infrastructure.SetDefaultMailer(mailer)
+	defer infrastructure.SetDefaultMailer(nil)
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	links := mocks.NewMagicLinkRepository(t)
+	users := mocks.NewUserRepository(t)
+	uc := NewMagicLinkUsecase(links, users)
+	uc.now = func() time.Time { return now }
+	user := domain.User{ID: "u1", Email: "ada@example.com", FirstName: "Ada", IsActive: true}
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Email: "ada@example.com"}}).Return([]domain.User{user}, nil)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{UserId: "u1"}}).Return([]domain.User{user}, nil)
+
+	stored := map[string]domain.MagicLink{}
+	links.On("CreateMagicLink", mock.Anything).Return(func(link domain.MagicLink) error {
+		stored[link.LinkId] = link
+		return nil
+	})
+	links.On("TakeMagicLink", mock.Anything).Return(func(linkId string) (domain.MagicLink, error) {
+		link, ok := stored[linkId]
+		delete(stored, linkId)
+		if !ok {
+			return domain.MagicLink{}, assert.AnError
+		}
+		return link, nil
+	})
+
+	response, err := uc.Request("ada@example.com", "Firefox", "10.0.0.1")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.DeviceToken)
+	assert.Equal(t, int(MagicLinkTTL/time.Second), response.ExpiresIn)
+	email, _ := mailer.Last()
+	assert.Contains(t, email.Text, "Firefox (10.0.0.1)")
+	token := emailedToken(t, mailer)
+	for id, link := range stored {
+		assert.Equal(t, infrastructure.HashRefreshToken(token), id, "only the hash is stored")
+		assert.NotContains(t, link.DeviceHash, response.DeviceToken)
+	}
+
+	loggedIn, err := uc.Redeem(token, response.DeviceToken)
+	assert.NoError(t, err)
+	assert.Equal(t, user, loggedIn)
+	_, err = uc.Redeem(token, response.DeviceToken)
+	assert.Error(t, err, "a link works once")
+
+	// a link opened on another device is used up without logging in
+	response, _ = uc.Request("ada@example.com", "Firefox", "10.0.0.1")
+	token = emailedToken(t, mailer)
+	_, err = uc.Redeem(token, "another device")
+	assert.Error(t, err)
+	_, err = uc.Redeem(token, response.DeviceToken)
+	assert.Error(t, err)
+
+	// the link opens the page of the client, which holds the device token
+	t.Setenv("MAGIC_LINK_URL", "https://app.example.com/login/link?lang=fr")
+	uc.Request("ada@example.com", "Firefox", "10.0.0.1")
+	link := emailedLink(t, mailer)
+	assert.Equal(t, "app.example.com", link.Host)
+	assert.Equal(t, "/login/link", link.Path)
+	assert.Equal(t, "fr", link.Query().Get("lang"))
+	assert.NotEmpty(t, link.Query().Get("token"))
+
+	// expired links are refused
+	response, _ = uc.Request("ada@example.com", "Firefox", "10.0.0.1")
+	token = emailedToken(t, mailer)
+	now = now.Add(MagicLinkTTL)
+	_, err = uc.Redeem(token, response.DeviceToken)
+	assert.Error(t, err)
+}
+
+func TestMagicLinkUnknownEmail(t *testing.T) {
+	mailer := infrastructure.NewMemoryMailer()
+	infrastructure.SetDefaultMailer(mailer)
+	defer infrastructure.SetDefaultMailer(nil)
+	users := mocks.NewUserRepository(t)
+	uc := NewMagicLinkUsecase(mocks.NewMagicLinkRepository(t), users)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Email: "nobody@example.com"}}).Return([]domain.User{{}}, assert.AnError)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Email: "new@example.com"}}).Return([]domain.User{{ID: "u2", IsActive: false}}, nil)
+
+	for _, email := range []string{"nobody@example.com", "new@example.com"} {
+		response, err := uc.Request(email, "curl", "10.0.0.2")
+		assert.NoError(t, err)
+		assert.Equal(t, magicLinkSent, response.Message, "the answer does not tell whether the account exists")
+		assert.NotEmpty(t, response.DeviceToken)
+	}
+	assert.Empty(t, mailer.Sent())
+}
@@ -0,0 +1,356 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// DefaultMaxUploadSize is the largest file users can upload by default.
+	DefaultMaxUploadSize = 10 << 20
+	// DefaultMediaQuota is how much storage each user has by default.
+	DefaultMediaQuota = 100 << 20
+	// ThumbnailSide is the largest side of image thumbnails, in pixels.
+	ThumbnailSide = 320
+	// OrphanMediaGrace is how long uploads can stay unused, while the blog
+	// or profile using them is written, before they are deleted.
+	OrphanMediaGrace = 24 * time.Hour
+)
+
+// mediaTypes are the types of files users can upload. They are told from
+// the content of the file, never from what the client claims.
+var mediaTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp", "application/pdf"}
+
+var (
+	ErrMediaTooLarge      = errors.New("the file is too large")
+	ErrMediaQuotaExceeded = errors.New("the storage quota is exceeded")
+	ErrMediaNotFound      = errors.New("media not found")
+)
+
+// MediaSettings limit uploads. Zero values keep the defaults.
+type MediaSettings struct {
+	MaxUploadSize int64
+	// Quota is how many bytes of files and thumbnails each user can store.
+	Quota int64
+	// BaseURL prefixes the URLs media are served at.
+	BaseURL string
+}
+
+// MediaUsecase stores the files users upload, with thumbnails of images,
+// within their quota, and deletes those nothing uses.
+type MediaUsecase struct {
+	mediaRepository domain.MediaRepository
+	storage         infrastructure.Storage
+	settings        MediaSettings
+	now             func() time.Time
+}
+
+func NewMediaUsecase(media domain.MediaRepository, storage infrastructure.Storage, settings MediaSettings) *MediaUsecase {
+	if settings.MaxUploadSize <= 0 {
+		settings.MaxUploadSize = DefaultMaxUploadSize
+	}
+	if settings.Quota <= 0 {
+		settings.Quota = DefaultMediaQuota
+	}
+	settings.BaseURL = strings.TrimSuffix(settings.BaseURL, "/")
+	return &MediaUsecase{mediaRepository: media, storage: storage, settings: settings, now: time.Now}
+}
+
+// MaxUploadSize is the largest file users can upload.
+func (uc *MediaUsecase) MaxUploadSize() int64 {
+	return uc.settings.MaxUploadSize
+}
+
+// Upload stores the file of the user, and a thumbnail when it is an image.
+func (uc *MediaUsecase) Upload(ownerId, filename string, data []byte) (domain.Media, error) {
+	if len(data) == 0 {
+		return domain.Media{}, errors.New("the file is empty")
+	}
+	if int64(len(data)) > uc.settings.MaxUploadSize {
+		return domain.Media{}, fmt.Errorf("%w, the limit is %s", ErrMediaTooLarge, formatBytes(uc.settings.MaxUploadSize))
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if !contains(mediaTypes, contentType) {
+		return domain.Media{}, errors.New("unsupported file type, upload JPEG, PNG, GIF or WebP images or PDF documents")
+	}
+	media := domain.Media{
+		MediaId:     primitive.NewObjectID().Hex(),
+		OwnerId:     ownerId,
+		Filename:    path.Base(strings.ReplaceAll(filename, "\\", "/")),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		References:  []string{},
+		CreatedAt:   uc.now(),
+	}
+	media.Key = ownerId + "/" + media.MediaId
+	var thumbnail infrastructure.Thumbnail
+	if contentType != "image/webp" && strings.HasPrefix(contentType, "image/") {
+		var err error
+		if thumbnail, err = infrastructure.MakeThumbnail(data, ThumbnailSide); err != nil {
+			return domain.Media{}, errors.New("the image could not be read")
+		}
+		media.Width, media.Height = thumbnail.Width, thumbnail.Height
+		media.ThumbnailKey = media.Key + "_thumbnail"
+		media.ThumbnailSize = int64(len(thumbnail.Data))
+	}
+	used, err := uc.mediaRepository.GetUsage(ownerId)
+	if err != nil {
+		return domain.Media{}, err
+	}
+	if used+media.Size+media.ThumbnailSize > uc.settings.Quota {
+		return domain.Media{}, fmt.Errorf("%w, %s of %s are used", ErrMediaQuotaExceeded, formatBytes(used), formatBytes(uc.settings.Quota))
+	}
+	ctx := context.Background()
+	if err := uc.storage.Put(ctx, media.Key, bytes.NewReader(data), media.Size, contentType); err != nil {
+		return domain.Media{}, err
+	}
+	if media.ThumbnailKey != "" {
+		err = uc.storage.Put(ctx, media.ThumbnailKey, bytes.NewReader(thumbnail.Data), media.ThumbnailSize, thumbnail.ContentType)
+	}
+	if err == nil {
+		err = uc.mediaRepository.CreateMedia(media)
+	}
+	if err != nil {
+		uc.deleteFiles(media)
+		return domain.Media{}, err
+	}
+	return uc.withURLs(media), nil
+}
+
+// GetMedia returns the media with its URLs.
+func (uc *MediaUsecase) GetMedia(mediaId string) (domain.Media, error) {
+	media, err := uc.mediaRepository.GetMedia(mediaId)
+	if err != nil {
+		return domain.Media{}, ErrMediaNotFound
+	}
+	return uc.withURLs(media), nil
+}
+
+// MediaFile is the content of media, or of its thumbnail, to serve.
+type MediaFile struct {
+	Media       domain.Media
+	Body        io.ReadCloser
+	ContentType string
+	Size        int64
+}
+
+// Open returns the file of the media, or its thumbnail when asked and it has
+// one. The caller closes its body.
+func (uc *MediaUsecase) Open(mediaId string, thumbnail bool) (MediaFile, error) {
+	media, err := uc.GetMedia(mediaId)
+	if err != nil {
+		return MediaFile{}, err
+	}
+	file := MediaFile{Media: media, ContentType: media.ContentType, Size: media.Size}
+	key := media.Key
+	if thumbnail && media.ThumbnailKey != "" {
+		key, file.Size = media.ThumbnailKey, media.ThumbnailSize
+		// thumbnails of JPEG photos are JPEG, the others PNG
+		if media.ContentType != "image/jpeg" {
+			file.ContentType = "image/png"
+		}
+	}
+	file.Body, err = uc.storage.Get(context.Background(), key)
+	if errors.Is(err, infrastructure.ErrObjectNotFound) {
+		return MediaFile{}, ErrMediaNotFound
+	}
+	if err != nil {
+		return MediaFile{}, err
+	}
+	return file, nil
+}
+
+// GetMyMedia lists the media of the user, newest first, and their usage.
+func (uc *MediaUsecase) GetMyMedia(ownerId string) ([]domain.Media, domain.MediaUsage, error) {
+	media, err := uc.mediaRepository.GetUserMedia(ownerId)
+	if err != nil {
+		return nil, domain.MediaUsage{}, err
+	}
+	usage := domain.MediaUsage{Quota: uc.settings.Quota}
+	for i := range media {
+		media[i] = uc.withURLs(media[i])
+		usage.Used += media[i].Size + media[i].ThumbnailSize
+	}
+	return media, usage, nil
+}
+
+// DeleteMedia deletes media of the user that nothing uses anymore.
+func (uc *MediaUsecase) DeleteMedia(ownerId, mediaId string) error {
+	media, err := uc.mediaRepository.GetMedia(mediaId)
+	if err != nil || media.OwnerId != ownerId {
+		return ErrMediaNotFound
+	}
+	if len(media.References) > 0 {
+		return fmt.Errorf("the media is still used by %s", strings.Join(media.References, ", "))
+	}
+	return uc.delete(media)
+}
+
+// CheckMedia tells whether all the media exist and belong to the user.
+func (uc *MediaUsecase) CheckMedia(ownerId string, mediaIds []string) error {
+	for _, mediaId := range mediaIds {
+		media, err := uc.mediaRepository.GetMedia(mediaId)
+		if err != nil || media.OwnerId != ownerId {
+			return fmt.Errorf("you can only use your own media, %s is not one", mediaId)
+		}
+	}
+	return nil
+}
+
+// Attach records that the reference, such as "blog:<id>", uses exactly the
+// given media of the user, so they are kept.
+func (uc *MediaUsecase) Attach(ownerId, reference string, mediaIds []string) error {
+	if err := uc.CheckMedia(ownerId, mediaIds); err != nil {
+		return err
+	}
+	return uc.mediaRepository.SetReferences(reference, mediaIds)
+}
+
+// Detach records that the reference uses no media anymore.
+func (uc *MediaUsecase) Detach(reference string) error {
+	return uc.mediaRepository.SetReferences(reference, nil)
+}
+
+// CleanupOrphans deletes media nothing used within OrphanMediaGrace of their
+// upload, or stopped using since.
+func (uc *MediaUsecase) CleanupOrphans() (int, error) {
+	orphans, err := uc.mediaRepository.GetOrphans(uc.now().Add(-OrphanMediaGrace))
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, media := range orphans {
+		if err := uc.delete(media); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// Run cleans up orphaned media every interval until stop is closed.
+func (uc *MediaUsecase) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if deleted, err := uc.CleanupOrphans(); err != nil {
+			log.Println("cleaning up orphaned media failed:", err)
+		} else if deleted > 0 {
+			log.Println("deleted", deleted, "orphaned media")
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (uc *MediaUsecase) delete(media domain.Media) error {
+	if err := uc.deleteFiles(media); err != nil {
+		return err
+	}
+	return uc.mediaRepository.DeleteMedia(media.MediaId)
+}
+
+func (uc *MediaUsecase) deleteFiles(media domain.Media) error {
+	ctx := context.Background()
+	if media.ThumbnailKey != "" {
+		if err := uc.storage.Delete(ctx, media.ThumbnailKey); err != nil {
+			return err
+		}
+	}
+	return uc.storage.Delete(ctx, media.Key)
+}
+
+func (uc *MediaUsecase) withURLs(media domain.Media) domain.Media {
+	media.URL = uc.settings.BaseURL + "/media/" + media.MediaId
+	if media.ThumbnailKey != "" {
+		media.ThumbnailURL = media.URL + "?size=thumbnail"
+	}
+	return media
+}
+
+func formatBytes(size int64) string {
+	if size >= 1<<20 {
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	}
+	return fmt.Sprintf("%d KB", (size+1023)/1024)
+}
+
+// SetMedia lets blogs use uploaded media, which are kept while they do.
+func (uc *BlogUsecase) SetMedia(media *MediaUsecase) {
+	uc.media = media
+}
+
+func (uc *BlogUsecase) checkMedia(authorId string, mediaIds []string) error {
+	if uc.media == nil || len(mediaIds) == 0 {
+		return nil
+	}
+	return uc.media.CheckMedia(authorId, mediaIds)
+}
+
+// attachMedia records the media a blog uses, its cover included. The blog is
+// saved by then, so failures are logged rather than returned.
+func (uc *BlogUsecase) attachMedia(authorId, blogId string, mediaIds []string, coverMediaId string) {
+	if uc.media == nil {
+		return
+	}
+	if coverMediaId != "" && !contains(mediaIds, coverMediaId) {
+		mediaIds = append(append([]string{}, mediaIds...), coverMediaId)
+	}
+	if err := uc.media.Attach(authorId, "blog:"+blogId, mediaIds); err != nil {
+		log.Println("recording the media of blog", blogId, "failed:", err)
+	}
+}
+
+func (uc *BlogUsecase) detachMedia(blogId string) {
+	if uc.media == nil {
+		return
+	}
+	if err := uc.media.Detach("blog:" + blogId); err != nil {
+		log.Println("releasing the media of blog", blogId, "failed:", err)
+	}
+}
+
+// SetMedia lets users pick an uploaded image as avatar.
+func (uc *ProfileUsecase) SetMedia(media *MediaUsecase) {
+	uc.media = media
+}
+
+func (uc *ProfileUsecase) avatar(userId, mediaId string) (domain.Media, error) {
+	if uc.media == nil {
+		return domain.Media{}, errors.New("uploads are not enabled")
+	}
+	media, err := uc.media.GetMedia(mediaId)
+	if err != nil || media.OwnerId != userId || media.ThumbnailURL == "" {
+		return domain.Media{}, errors.New("the avatar must be an image you uploaded")
+	}
+	return media, nil
+}
+
+func (uc *ProfileUsecase) attachAvatar(userId, mediaId string) {
+	if uc.media == nil {
+		return
+	}
+	var mediaIds []string
+	if mediaId != "" {
+		mediaIds = []string{mediaId}
+	}
+	if err := uc.media.Attach(userId, "profile:"+userId, mediaIds); err != nil {
+		log.Println("recording the avatar of user", userId, "failed:", err)
+	}
+}
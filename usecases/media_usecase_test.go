@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+// storedMedia backs the mock repository with a map.
+func storedMedia(media *mocks.MediaRepository) map[string]domain.Media {
+	stored := map[string]domain.Media{}
+	media.On("CreateMedia", mock.Anything).Return(func(m domain.Media) error {
+		stored[m.MediaId] = m
+		return nil
+	}).Maybe()
+	media.On("GetMedia", mock.Anything).Return(func(mediaId string) (domain.Media, error) {
+		m, ok := stored[mediaId]
+		if !ok {
+			return domain.Media{}, assert.AnError
+		}
+		return m, nil
+	}).Maybe()
+	media.On("GetUsage", mock.Anything).Return(func(ownerId string) (int64, error) {
+		var used int64
+		for _, m := range stored {
+			if m.OwnerId == ownerId {
+				used += m.Size + m.ThumbnailSize
+			}
+		}
+		return used, nil
+	}).Maybe()
+	media.On("SetReferences", mock.Anything, mock.Anything).Return(func(reference string, mediaIds []string) error {
+		for id, m := range stored {
+			var references []string
+			for _, other := range m.References {
+				if other != reference {
+					references = append(references, other)
+				}
+			}
+			if contains(mediaIds, id) {
+				references = append(references, reference)
+			}
+			m.References = references
+			stored[id] = m
+		}
+		return nil
+	}).Maybe()
+	media.On("GetOrphans", mock.Anything).Return(func(before time.Time) ([]domain.Media, error) {
+		orphans := []domain.Media{}
+		for _, m := range stored {
+			if len(m.References) == 0 && m.CreatedAt.Before(before) {
+				orphans = append(orphans, m)
+			}
+		}
+		return orphans, nil
+	}).Maybe()
+	media.On("DeleteMedia", mock.Anything).Return(func(mediaId string) error {
+		delete(stored, mediaId)
+		return nil
+	}).Maybe()
+	return stored
+}
+
+func pngImage(t *testing.T, width, height int) []byte {
+	var data bytes.Buffer
+	require.NoError(t, png.Encode(&data, image.NewNRGBA(image.Rect(0, 0, width, height))))
+	return data.Bytes()
+}
+
+func TestUploadMedia(t *testing.T) {
+	repo := mocks.NewMediaRepository(t)
+	stored := storedMedia(repo)
+	storage, err := infrastructure.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	photo := pngImage(t, 1000, 500)
+	uc := NewMediaUsecase(repo, storage, MediaSettings{MaxUploadSize: 64 << 10, Quota: int64(len(photo)) * 3, BaseURL: "https://blogmate.example/"})
+
+	media, err := uc.Upload("u1", `C:\Users\ada\holiday.png`, photo)
+	require.NoError(t, err)
+	assert.Equal(t, "holiday.png", media.Filename)
+	assert.Equal(t, "image/png", media.ContentType)
+	assert.Equal(t, 1000, media.Width)
+	assert.Equal(t, 500, media.Height)
+	assert.Equal(t, "https://blogmate.example/media/"+media.MediaId, media.URL)
+	assert.Equal(t, media.URL+"?size=thumbnail", media.ThumbnailURL)
+	assert.Contains(t, stored, media.MediaId)
+
+	file, err := uc.Open(media.MediaId, true)
+	require.NoError(t, err)
+	thumbnail, err := png.Decode(file.Body)
+	file.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, ThumbnailSide, ThumbnailSide/2), thumbnail.Bounds())
+	assert.Equal(t, "image/png", file.ContentType)
+
+	file, err = uc.Open(media.MediaId, false)
+	require.NoError(t, err)
+	original, _ := io.ReadAll(file.Body)
+	file.Body.Close()
+	assert.Equal(t, photo, original)
+
+	document, err := uc.Upload("u1", "cv.pdf", []byte("%PDF-1.7\n..."))
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", document.ContentType)
+	assert.Empty(t, document.ThumbnailURL)
+
+	_, err = uc.Upload("u1", "page.html", []byte("<html><script>alert(1)</script></html>"))
+	assert.Error(t, err, "types are told from the content")
+	_, err = uc.Upload("u1", "fake.png", []byte("\x89PNG\r\n\x1a\nnot really"))
+	assert.Error(t, err)
+	_, err = uc.Upload("u1", "empty.png", nil)
+	assert.Error(t, err)
+	_, err = uc.Upload("u1", "huge.pdf", append([]byte("%PDF-1.7\n"), make([]byte, 64<<10)...))
+	assert.ErrorIs(t, err, ErrMediaTooLarge)
+
+	_, err = uc.Upload("u1", "again.png", photo)
+	require.NoError(t, err)
+	_, err = uc.Upload("u1", "once-more.png", photo)
+	assert.ErrorIs(t, err, ErrMediaQuotaExceeded)
+	_, err = uc.Upload("u2", "mine.png", photo)
+	assert.NoError(t, err, "quotas are per user")
+
+	repo.On("GetUserMedia", "u1").Return([]domain.Media{stored[media.MediaId]}, nil)
+	list, usage, err := uc.GetMyMedia("u1")
+	require.NoError(t, err)
+	assert.Equal(t, media.URL, list[0].URL)
+	assert.Equal(t, media.Size+stored[media.MediaId].ThumbnailSize, usage.Used)
+	assert.Equal(t, int64(len(photo))*3, usage.Quota)
+}
+
+func TestMediaReferences(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	repo := mocks.NewMediaRepository(t)
+	stored := storedMedia(repo)
+	storage, err := infrastructure.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	uc := NewMediaUsecase(repo, storage, MediaSettings{})
+	uc.now = func() time.Time { return now }
+	blogs := mocks.NewBlogRepository(t)
+	blogUsecase := NewBlogUsecase(blogs)
+	blogUsecase.SetMedia(uc)
+
+	image1, err := uc.Upload("u1", "a.png", pngImage(t, 10, 10))
+	require.NoError(t, err)
+	image2, err := uc.Upload("u1", "b.png", pngImage(t, 10, 10))
+	require.NoError(t, err)
+	theirs, err := uc.Upload("u2", "c.png", pngImage(t, 10, 10))
+	require.NoError(t, err)
+
+	_, err = blogUsecase.CreateBLog(domain.Blog{AuthorId: "u1", Title: "Stolen", Media: []string{theirs.MediaId}})
+	assert.Error(t, err, "blogs only use media of their author")
+
+	blogs.On("CreateBlogWithContext", mock.Anything, mock.Anything).Return(domain.Blog{BlogId: "b1", AuthorId: "u1", Media: []string{image1.MediaId, image2.MediaId}}, nil)
+	_, err = blogUsecase.CreateBLog(domain.Blog{AuthorId: "u1", Title: "Holiday", Media: []string{image1.MediaId, image2.MediaId}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"blog:b1"}, stored[image1.MediaId].References)
+	assert.Error(t, uc.DeleteMedia("u1", image1.MediaId), "media in use are kept")
+	assert.ErrorIs(t, uc.DeleteMedia("u2", image2.MediaId), ErrMediaNotFound)
+
+	blogs.On("GetBlogById", "b1").Return(domain.Blog{BlogId: "b1", AuthorId: "u1"}, nil)
+	blogs.On("UpdateBlogWithContext", mock.Anything, "b1", mock.Anything).Return(domain.Blog{}, nil)
+	_, err = blogUsecase.UpdateBLog("b1", domain.Blog{Media: []string{image1.MediaId}})
+	require.NoError(t, err)
+	assert.Empty(t, stored[image2.MediaId].References)
+
+	// nothing is deleted before the grace period
+	deleted, err := uc.CleanupOrphans()
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+	now = now.Add(OrphanMediaGrace + time.Minute)
+	deleted, err = uc.CleanupOrphans()
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.Contains(t, stored, image1.MediaId)
+	assert.NotContains(t, stored, image2.MediaId)
+	_, err = storage.Get(context.Background(), image2.Key)
+	assert.ErrorIs(t, err, infrastructure.ErrObjectNotFound)
+
+	blogs.On("DeleteBlogWithContext", mock.Anything, "b1", "u1").Return(nil)
+	require.NoError(t, blogUsecase.DeleteBLog("b1", "u1"))
+	assert.Empty(t, stored[image1.MediaId].References)
+	assert.NoError(t, uc.DeleteMedia("u1", image1.MediaId))
+	assert.Empty(t, stored)
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"log"
+
+	"github.com/yesetoda/BlogMate/config"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+)
+
+type MentionUsecase struct {
+	userRepository    domain.UserRepository
+	mentionRepository domain.MentionRepository
+	// notify tells a user about a new mention, it sends an email by default
+	notify func(user domain.User, mention domain.Mention) error
+}
+
+func NewMentionUsecase(users domain.UserRepository, mentions domain.MentionRepository) *MentionUsecase {
+	return &MentionUsecase{userRepository: users, mentionRepository: mentions, notify: emailMention}
+}
+
+func emailMention(user domain.User, mention domain.Mention) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	link := cfg.Port + "/blogs/" + mention.BlogId
+	where := "a blog"
+	if mention.CommentId != "" {
+		where = "a comment"
+	}
+	data := infrastructure.EmailData{Name: displayName(user), Title: "You were mentioned", Body: "You were mentioned in " + where + ".", Link: link}
+	return infrastructure.SendTemplatedEmail(user.Email, infrastructure.NotificationEmail, user.Locale, data)
+}
+
+// ProcessMentions stores the users mentioned in content as mentions of the
+// blog, or of one of its comments when commentId is set, and notifies the
+// users that were not mentioned there before. Unknown usernames and authors
+// mentioning themselves are ignored.
+func (uc *MentionUsecase) ProcessMentions(authorId, blogId, commentId, content string) error {
+	mentions := []domain.Mention{}
+	users := map[string]domain.User{}
+	for _, username := range infrastructure.ParseMentions(content) {
+		found, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{Username: username}})
+		if err != nil || len(found) == 0 || found[0].ID == "" || found[0].ID == authorId {
+			continue
+		}
+		users[found[0].ID] = found[0]
+		mentions = append(mentions, domain.Mention{
+			MentionedUserId: found[0].ID,
+			Username:        found[0].Username,
+			AuthorId:        authorId,
+		})
+	}
+	added, err := uc.mentionRepository.ReplaceMentions(blogId, commentId, mentions)
+	if err != nil {
+		return err
+	}
+	for _, mention := range added {
+		if err := uc.notify(users[mention.MentionedUserId], mention); err != nil {
+			log.Println("notifying", mention.Username, "of a mention failed:", err)
+		}
+	}
+	return nil
+}
+
+func (uc *MentionUsecase) GetMentions(userId string, opts domain.PaginationInfo) ([]domain.Mention, error) {
+	mentions, err := uc.mentionRepository.GetMentionsForUser(userId, opts)
+	if err != nil {
+		return []domain.Mention{}, err
+	}
+	return mentions, nil
+}
+
+func (uc *MentionUsecase) DeleteMentions(blogId, commentId string) error {
+	return uc.mentionRepository.DeleteMentions(blogId, commentId)
+}
+
+// SetMentions makes the usecase record @mentions in blogs and comments.
+func (uc *BlogUsecase) SetMentions(mentions *MentionUsecase) {
+	uc.mentions = mentions
+}
+
+// recordMentions processes the mentions in the background so that slow
+// notifications do not hold up the request.
+func (uc *BlogUsecase) recordMentions(authorId, blogId, commentId, content string) {
+	if uc.mentions == nil {
+		return
+	}
+	go func() {
+		if err := uc.mentions.ProcessMentions(authorId, blogId, commentId, content); err != nil {
+			log.Println("recording mentions for blog", blogId, "failed:", err)
+		}
+	}()
+}
+
+func (uc *BlogUsecase) forgetMentions(blogId, commentId string) {
+	if uc.mentions == nil {
+		return
+	}
+	if err := uc.mentions.DeleteMentions(blogId, commentId); err != nil {
+		log.Println("deleting mentions for blog", blogId, "failed:", err)
+	}
+}
@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+func TestProcessMentions(t *testing.T) {
+	users := mocks.NewUserRepository(t)
+	mentions := mocks.NewMentionRepository(t)
+	byName := func(name string) domain.UserFilterOption {
+		return domain.UserFilterOption{Filter: domain.UserFilter{Username: name}}
+	}
+	users.On("Get", byName("jane")).Return([]domain.User{{ID: "u2", Username: "jane", Email: "jane@example.com"}}, nil)
+	users.On("Get", byName("author")).Return([]domain.User{{ID: "u1", Username: "author"}}, nil)
+	users.On("Get", byName("ghost")).Return([]domain.User{{}}, errors.New("there is no user with the given username"))
+	mentions.On("ReplaceMentions", "b1", "c1", mock.MatchedBy(func(m []domain.Mention) bool {
+		return len(m) == 1 && m[0].MentionedUserId == "u2" && m[0].AuthorId == "u1"
+	})).Return([]domain.Mention{{MentionedUserId: "u2", Username: "jane", AuthorId: "u1", BlogId: "b1", CommentId: "c1"}}, nil).Once()
+
+	uc := NewMentionUsecase(users, mentions)
+	notified := []string{}
+	uc.notify = func(user domain.User, mention domain.Mention) error {
+		notified = append(notified, user.Email)
+		return nil
+	}
+
+	err := uc.ProcessMentions("u1", "b1", "c1", "hey @jane, @ghost and @author")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"jane@example.com"}, notified)
+
+	// an edit that keeps the mention does not notify again
+	mentions.On("ReplaceMentions", "b1", "c1", mock.Anything).Return([]domain.Mention{}, nil).Once()
+	err = uc.ProcessMentions("u1", "b1", "c1", "hey @jane")
+	assert.NoError(t, err)
+	assert.Len(t, notified, 1)
+}
+
+func TestDeleteCommentForgetsMentionsOfReplies(t *testing.T) {
+	blogs := mocks.NewBlogRepository(t)
+	mentions := mocks.NewMentionRepository(t)
+	uc := NewBlogUsecase(blogs)
+	uc.SetMentions(NewMentionUsecase(mocks.NewUserRepository(t), mentions))
+
+	blogs.On("DeleteComment", "b1", "c1", "u1").Return([]string{"c1", "c2", "c3"}, nil).Once()
+	for _, id := range []string{"c1", "c2", "c3"} {
+		mentions.On("DeleteMentions", "b1", id).Return(nil).Once()
+	}
+	assert.NoError(t, uc.DeleteComment("b1", "c1", "u1"))
+}
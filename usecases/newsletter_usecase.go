@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/yesetoda/BlogMate/config"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+)
+
+// NewsletterUsecase lets readers without an account subscribe by email to an
+// author or a tag, and emails them when a matching post is published.
+type NewsletterUsecase struct {
+	newsletterRepository domain.NewsletterRepository
+	userRepository       domain.UserRepository
+	outbox               *OutboxUsecase
+	now                  func() time.Time
+}
+
+func NewNewsletterUsecase(newsletters domain.NewsletterRepository, users domain.UserRepository) *NewsletterUsecase {
+	return &NewsletterUsecase{newsletterRepository: newsletters, userRepository: users, now: time.Now}
+}
+
+// SetOutbox sends newsletter emails through the outbox instead of inline.
+func (uc *NewsletterUsecase) SetOutbox(outbox *OutboxUsecase) {
+	uc.outbox = outbox
+}
+
+// Subscribe stores a pending subscription of email to the author or tag and
+// sends the confirmation link. Subscribing again sends a new link, confirmed
+// subscriptions are left as they are.
+func (uc *NewsletterUsecase) Subscribe(email, kind, target string) error {
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return errors.New("invalid email address")
+	}
+	email = strings.ToLower(address.Address)
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return errors.New("an author or a tag is required")
+	}
+	var what string
+	switch kind {
+	case domain.NewsletterAuthor:
+		authors, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{UserId: target}})
+		if err != nil || len(authors) == 0 || authors[0].ID == "" {
+			return errors.New("author not found")
+		}
+		what = displayName(authors[0]) + " publishes a post"
+	case domain.NewsletterTag:
+		what = "a post tagged " + target + " is published"
+	default:
+		return errors.New("kind must be author or tag")
+	}
+
+	existing, err := uc.newsletterRepository.GetSubscribers(domain.NewsletterFilter{Email: email, Kind: kind, Target: target})
+	if err != nil {
+		return err
+	}
+	subscriber := domain.NewsletterSubscriber{Email: email, Kind: kind, Target: target}
+	if len(existing) > 0 {
+		subscriber = existing[0]
+		if subscriber.Status == domain.SubscriberConfirmed {
+			return nil
+		}
+	}
+	subscriber.Status = domain.SubscriberPending
+	subscriber.ConfirmToken = string(newConfirmationToken())
+	subscriber, err = uc.newsletterRepository.SaveSubscriber(subscriber)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	payload := domain.OutboxEmailPayload{
+		To:       subscriber.Email,
+		Template: infrastructure.NewsletterConfirmationEmail,
+		Title:    what,
+		Link:     cfg.Port + "/newsletter/confirm?token=" + subscriber.ConfirmToken,
+	}
+	return uc.sendEmail("newsletter-confirm:"+subscriber.SubscriberId+":"+subscriber.ConfirmToken, payload)
+}
+
+// Confirm activates the subscription the confirmation token was sent for.
+func (uc *NewsletterUsecase) Confirm(token string) (domain.NewsletterSubscriber, error) {
+	subscriber, err := uc.findByToken(domain.NewsletterFilter{ConfirmToken: token, Status: domain.SubscriberPending})
+	if err != nil {
+		return domain.NewsletterSubscriber{}, err
+	}
+	subscriber.Status = domain.SubscriberConfirmed
+	subscriber.ConfirmToken = ""
+	subscriber.ConfirmedAt = uc.now()
+	if subscriber.UnsubscribeToken == "" {
+		subscriber.UnsubscribeToken = string(newConfirmationToken())
+	}
+	return uc.newsletterRepository.SaveSubscriber(subscriber)
+}
+
+// Unsubscribe stops the subscription the email with the token was sent for.
+func (uc *NewsletterUsecase) Unsubscribe(token string) error {
+	subscriber, err := uc.findByToken(domain.NewsletterFilter{UnsubscribeToken: token})
+	if err != nil {
+		return err
+	}
+	subscriber.Status = domain.SubscriberUnsubscribed
+	_, err = uc.newsletterRepository.SaveSubscriber(subscriber)
+	return err
+}
+
+func (uc *NewsletterUsecase) findByToken(filter domain.NewsletterFilter) (domain.NewsletterSubscriber, error) {
+	if filter.ConfirmToken == "" && filter.UnsubscribeToken == "" {
+		return domain.NewsletterSubscriber{}, errors.New("token is required")
+	}
+	subscribers, err := uc.newsletterRepository.GetSubscribers(filter)
+	if err != nil {
+		return domain.NewsletterSubscriber{}, err
+	}
+	if len(subscribers) == 0 {
+		return domain.NewsletterSubscriber{}, errors.New("invalid or expired token")
+	}
+	return subscribers[0], nil
+}
+
+// GetSubscribers lists the subscribers of an author, optionally only those
+// with the given status.
+func (uc *NewsletterUsecase) GetSubscribers(authorId, status string, opts domain.PaginationInfo) ([]domain.NewsletterSubscriber, error) {
+	return uc.newsletterRepository.GetSubscribers(domain.NewsletterFilter{
+		Kind:       domain.NewsletterAuthor,
+		Target:     authorId,
+		Status:     status,
+		Pagination: opts,
+	})
+}
+
+// RemoveSubscriber deletes one of the subscribers of an author.
+func (uc *NewsletterUsecase) RemoveSubscriber(authorId, subscriberId string) error {
+	subscribers, err := uc.newsletterRepository.GetSubscribers(domain.NewsletterFilter{Kind: domain.NewsletterAuthor, Target: authorId})
+	if err != nil {
+		return err
+	}
+	for _, subscriber := range subscribers {
+		if subscriber.SubscriberId == subscriberId {
+			return uc.newsletterRepository.DeleteSubscriber(subscriberId)
+		}
+	}
+	return errors.New("subscriber not found")
+}
+
+// ExportSubscribers writes the subscribers of an author to w as CSV.
+func (uc *NewsletterUsecase) ExportSubscribers(authorId string, w io.Writer) error {
+	subscribers, err := uc.GetSubscribers(authorId, "", domain.PaginationInfo{})
+	if err != nil {
+		return err
+	}
+	out := csv.NewWriter(w)
+	out.Write([]string{"email", "status", "subscribed_at", "confirmed_at"})
+	for _, subscriber := range subscribers {
+		confirmedAt := ""
+		if !subscriber.ConfirmedAt.IsZero() {
+			confirmedAt = subscriber.ConfirmedAt.UTC().Format(time.RFC3339)
+		}
+		out.Write([]string{subscriber.Email, subscriber.Status, subscriber.CreatedAt.UTC().Format(time.RFC3339), confirmedAt})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// NotifyPublished emails the confirmed subscribers of the author and tags of
+// a new blog. Readers subscribed several times get a single email.
+func (uc *NewsletterUsecase) NotifyPublished(blog domain.Blog) error {
+	subscribers, err := uc.newsletterRepository.GetSubscribersForBlog(blog.AuthorId, blog.Tags)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	sent := map[string]bool{}
+	var errs []error
+	for _, subscriber := range subscribers {
+		if sent[subscriber.Email] {
+			continue
+		}
+		sent[subscriber.Email] = true
+		payload := domain.OutboxEmailPayload{
+			To:          subscriber.Email,
+			Template:    infrastructure.NewsletterPostEmail,
+			Title:       blog.Title,
+			Body:        summarize(blog.Content, digestSummaryLength),
+			Link:        cfg.Port + "/blogs/" + blog.BlogId,
+			Unsubscribe: cfg.Port + "/newsletter/unsubscribe?token=" + subscriber.UnsubscribeToken,
+		}
+		if err := uc.sendEmail("newsletter:"+blog.BlogId+":"+subscriber.Email, payload); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (uc *NewsletterUsecase) sendEmail(key string, payload domain.OutboxEmailPayload) error {
+	if uc.outbox != nil {
+		return uc.outbox.Enqueue(context.Background(), domain.OutboxEmail, key, payload)
+	}
+	data := infrastructure.EmailData{Title: payload.Title, Body: payload.Body, Link: payload.Link, UnsubscribeLink: payload.Unsubscribe}
+	return infrastructure.SendTemplatedEmail(payload.To, payload.Template, payload.Locale, data)
+}
+
+// SetNewsletter emails newsletter subscribers about new blogs.
+func (uc *BlogUsecase) SetNewsletter(newsletter *NewsletterUsecase) {
+	uc.newsletter = newsletter
+}
+
+func (uc *BlogUsecase) notifySubscribers(blog domain.Blog) {
+	if uc.newsletter == nil {
+		return
+	}
+	go func() {
+		if err := uc.newsletter.NotifyPublished(blog); err != nil {
+			log.Println("emailing the subscribers of blog", blog.BlogId, "failed:", err)
+		}
+	}()
+}
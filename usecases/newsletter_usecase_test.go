@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+func saveSubscriber(s domain.NewsletterSubscriber) (domain.NewsletterSubscriber, error) {
+	if s.SubscriberId == "" {
+		s.SubscriberId = "s1"
+	}
+	return s, nil
+}
+
+func TestNewsletterSubscribe(t *testing.T) {
+	mailer := infrastructure.NewMemoryMailer()
+	infrastructure.SetDefaultMailer(mailer)
+	defer infrastructure.SetDefaultMailer(nil)
+	repo := mocks.NewNewsletterRepository(t)
+	users := mocks.NewUserRepository(t)
+	uc := NewNewsletterUsecase(repo, users)
+
+	assert.Error(t, uc.Subscribe("not an email", domain.NewsletterTag, "go"))
+	assert.Error(t, uc.Subscribe("reader@example.com", "planet", "go"))
+
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{UserId: "a1"}}).Return([]domain.User{{ID: "a1", Username: "abel"}}, nil)
+	repo.On("GetSubscribers", domain.NewsletterFilter{Email: "reader@example.com", Kind: domain.NewsletterAuthor, Target: "a1"}).Return([]domain.NewsletterSubscriber{}, nil).Once()
+	repo.On("SaveSubscriber", mock.MatchedBy(func(s domain.NewsletterSubscriber) bool {
+		return s.Status == domain.SubscriberPending && len(s.ConfirmToken) == 64
+	})).Return(saveSubscriber).Once()
+
+	assert.NoError(t, uc.Subscribe("Reader <READER@example.com>", domain.NewsletterAuthor, "a1"))
+	email, ok := mailer.Last()
+	assert.True(t, ok)
+	assert.Equal(t, []string{"reader@example.com"}, email.To)
+	assert.Contains(t, email.Text, "abel publishes a post")
+	assert.Contains(t, email.Text, "/newsletter/confirm?token=")
+
+	// already confirmed, nothing is sent again
+	repo.On("GetSubscribers", domain.NewsletterFilter{Email: "reader@example.com", Kind: domain.NewsletterAuthor, Target: "a1"}).Return([]domain.NewsletterSubscriber{{SubscriberId: "s1", Status: domain.SubscriberConfirmed}}, nil).Once()
+	assert.NoError(t, uc.Subscribe("reader@example.com", domain.NewsletterAuthor, "a1"))
+	assert.Len(t, mailer.Sent(), 1)
+}
+
+func TestNewsletterConfirm(t *testing.T) {
+	repo := mocks.NewNewsletterRepository(t)
+	uc := NewNewsletterUsecase(repo, mocks.NewUserRepository(t))
+
+	_, err := uc.Confirm("")
+	assert.Error(t, err)
+
+	repo.On("GetSubscribers", domain.NewsletterFilter{ConfirmToken: "tok", Status: domain.SubscriberPending}).Return([]domain.NewsletterSubscriber{{SubscriberId: "s1", Status: domain.SubscriberPending, ConfirmToken: "tok"}}, nil)
+	repo.On("SaveSubscriber", mock.Anything).Return(saveSubscriber)
+	subscriber, err := uc.Confirm("tok")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.SubscriberConfirmed, subscriber.Status)
+	assert.Empty(t, subscriber.ConfirmToken)
+	assert.NotEmpty(t, subscriber.UnsubscribeToken)
+}
+
+func TestNewsletterNotifyPublished(t *testing.T) {
+	mailer := infrastructure.NewMemoryMailer()
+	infrastructure.SetDefaultMailer(mailer)
+	defer infrastructure.SetDefaultMailer(nil)
+	repo := mocks.NewNewsletterRepository(t)
+	uc := NewNewsletterUsecase(repo, mocks.NewUserRepository(t))
+
+	blog := domain.Blog{BlogId: "b1", AuthorId: "a1", Title: "Channels", Tags: []string{"go"}}
+	repo.On("GetSubscribersForBlog", "a1", []string{"go"}).Return([]domain.NewsletterSubscriber{
+		{Email: "one@example.com", Kind: domain.NewsletterAuthor, UnsubscribeToken: "u1"},
+		{Email: "one@example.com", Kind: domain.NewsletterTag, UnsubscribeToken: "u2"},
+		{Email: "two@example.com", Kind: domain.NewsletterTag, UnsubscribeToken: "u3"},
+	}, nil)
+
+	assert.NoError(t, uc.NotifyPublished(blog))
+	sent := mailer.Sent()
+	assert.Len(t, sent, 2)
+	assert.Equal(t, []string{"one@example.com"}, sent[0].To)
+	assert.Contains(t, sent[0].Text, "Channels")
+	assert.Contains(t, sent[0].HTML, "/newsletter/unsubscribe?token=u1")
+}
+
+func TestExportSubscribers(t *testing.T) {
+	repo := mocks.NewNewsletterRepository(t)
+	uc := NewNewsletterUsecase(repo, mocks.NewUserRepository(t))
+	at := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	repo.On("GetSubscribers", domain.NewsletterFilter{Kind: domain.NewsletterAuthor, Target: "a1"}).Return([]domain.NewsletterSubscriber{
+		{Email: "one@example.com", Status: domain.SubscriberConfirmed, CreatedAt: at, ConfirmedAt: at},
+		{Email: "two@example.com", Status: domain.SubscriberPending, CreatedAt: at},
+	}, nil)
+
+	var out bytes.Buffer
+	assert.NoError(t, uc.ExportSubscribers("a1", &out))
+	assert.Equal(t, "email,status,subscribed_at,confirmed_at\n"+
+		"one@example.com,confirmed,2026-10-18T09:00:00Z,2026-10-18T09:00:00Z\n"+
+		"two@example.com,pending,2026-10-18T09:00:00Z,\n", out.String())
+
+	assert.Error(t, uc.RemoveSubscriber("a1", "someone-else"))
+}
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type NotificationUsecase struct {
+	notificationRepository domain.NotificationRepository
+	broker                 infrastructure.Broker
+	outbox                 *OutboxUsecase
+}
+
+func NewNotificationUsecase(repo domain.NotificationRepository) *NotificationUsecase {
+	return &NotificationUsecase{notificationRepository: repo}
+}
+
+// Notify stores a notification unless it is about the user's own action or the
+// user turned its type off. It reports whether the notification was stored, or
+// queued when the usecase has an outbox.
+func (uc *NotificationUsecase) Notify(n domain.Notification) (bool, error) {
+	if n.UserId == "" || n.UserId == n.ActorId {
+		return false, nil
+	}
+	if uc.outbox != nil {
+		if n.NotificationId == "" {
+			n.NotificationId = primitive.NewObjectID().Hex()
+		}
+		if err := uc.outbox.Enqueue(context.Background(), domain.OutboxNotification, "notification:"+n.NotificationId, n); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return uc.deliver(n)
+}
+
+// SetOutbox makes Notify queue notifications in the outbox, they are stored
+// when the dispatcher hands them to HandleOutboxMessage.
+func (uc *NotificationUsecase) SetOutbox(outbox *OutboxUsecase) {
+	uc.outbox = outbox
+}
+
+// HandleOutboxMessage is the outbox handler of notification messages.
+func (uc *NotificationUsecase) HandleOutboxMessage(message domain.OutboxMessage) error {
+	var n domain.Notification
+	if err := json.Unmarshal([]byte(message.Payload), &n); err != nil {
+		return err
+	}
+	_, err := uc.deliver(n)
+	return err
+}
+
+func (uc *NotificationUsecase) deliver(n domain.Notification) (bool, error) {
+	prefs, err := uc.notificationRepository.GetPreferences(n.UserId)
+	if err != nil {
+		return false, err
+	}
+	if !prefs.Enabled(n.Type) {
+		return false, nil
+	}
+	stored, err := uc.notificationRepository.CreateNotification(n)
+	if err != nil {
+		return false, err
+	}
+	if uc.broker != nil {
+		uc.broker.Publish(infrastructure.Event{Topic: infrastructure.UserTopic(n.UserId), Type: "notification.created", Data: stored})
+	}
+	return true, nil
+}
+
+func (uc *NotificationUsecase) GetNotifications(userId string, opts domain.NotificationFilterOption) ([]domain.Notification, error) {
+	notifications, err := uc.notificationRepository.GetNotifications(userId, opts)
+	if err != nil {
+		return []domain.Notification{}, err
+	}
+	return notifications, nil
+}
+
+func (uc *NotificationUsecase) CountUnread(userId string) (int64, error) {
+	return uc.notificationRepository.CountUnread(userId)
+}
+
+func (uc *NotificationUsecase) MarkRead(userId string, notificationIds []string) (int64, error) {
+	return uc.notificationRepository.MarkRead(userId, notificationIds)
+}
+
+func (uc *NotificationUsecase) GetPreferences(userId string) (domain.NotificationPreferences, error) {
+	return uc.notificationRepository.GetPreferences(userId)
+}
+
+func (uc *NotificationUsecase) UpdatePreferences(userId string, events map[string]bool) (domain.NotificationPreferences, error) {
+	for eventType := range events {
+		if !isNotificationType(eventType) {
+			return domain.NotificationPreferences{}, fmt.Errorf("unknown notification type %q", eventType)
+		}
+	}
+	return uc.notificationRepository.UpdatePreferences(domain.NotificationPreferences{UserId: userId, Events: events})
+}
+
+func isNotificationType(eventType string) bool {
+	for _, t := range domain.NotificationTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// SetNotifications makes the usecase notify authors about comments, replies
+// and reactions on their content.
+func (uc *BlogUsecase) SetNotifications(notifications *NotificationUsecase) {
+	uc.notifications = notifications
+}
+
+// SetNotifications delivers mention notifications in-app instead of by email.
+func (uc *MentionUsecase) SetNotifications(notifications *NotificationUsecase) {
+	uc.notify = func(user domain.User, mention domain.Mention) error {
+		message := "You were mentioned in a blog"
+		if mention.CommentId != "" {
+			message = "You were mentioned in a comment"
+		}
+		_, err := notifications.Notify(domain.Notification{
+			UserId:    user.ID,
+			ActorId:   mention.AuthorId,
+			Type:      domain.NotificationMention,
+			BlogId:    mention.BlogId,
+			CommentId: mention.CommentId,
+			Message:   message,
+		})
+		return err
+	}
+}
+
+// notifyAsync builds and stores a notification in the background. build
+// returns false when there is nobody to notify.
+func (uc *BlogUsecase) notifyAsync(build func() (domain.Notification, bool)) {
+	if uc.notifications == nil {
+		return
+	}
+	go func() {
+		n, ok := build()
+		if !ok {
+			return
+		}
+		if _, err := uc.notifications.Notify(n); err != nil {
+			log.Println("storing", n.Type, "notification failed:", err)
+		}
+	}()
+}
+
+func (uc *BlogUsecase) notifyComment(blogId string, comment domain.Comment) {
+	uc.notifyAsync(func() (domain.Notification, bool) {
+		n := domain.Notification{ActorId: comment.AuthorId, BlogId: blogId, CommentId: comment.CommentId}
+		if comment.ParentId != "" {
+			parent, err := uc.blogRepository.GetCommentById(blogId, comment.ParentId)
+			if err != nil {
+				return n, false
+			}
+			n.UserId = parent.AuthorId
+			n.Type = domain.NotificationReply
+			n.Message = "Someone replied to your comment"
+			return n, true
+		}
+		blog, err := uc.blogRepository.GetBlogById(blogId)
+		if err != nil {
+			return n, false
+		}
+		n.UserId = blog.AuthorId
+		n.Type = domain.NotificationComment
+		n.Message = fmt.Sprintf("New comment on your blog %q", blog.Title)
+		return n, true
+	})
+}
+
+func (uc *BlogUsecase) notifyBlogReaction(blogId, userId, reaction string) {
+	uc.notifyAsync(func() (domain.Notification, bool) {
+		blog, err := uc.blogRepository.GetBlogById(blogId)
+		if err != nil {
+			return domain.Notification{}, false
+		}
+		return domain.Notification{
+			UserId:  blog.AuthorId,
+			ActorId: userId,
+			Type:    domain.NotificationReaction,
+			BlogId:  blogId,
+			Message: fmt.Sprintf("Someone %sd your blog %q", reaction, blog.Title),
+			Key:     "reaction:" + blogId + ":" + userId,
+		}, true
+	})
+}
+
+func (uc *BlogUsecase) notifyCommentReaction(blogId, commentId, userId, reaction string) {
+	uc.notifyAsync(func() (domain.Notification, bool) {
+		comment, err := uc.blogRepository.GetCommentById(blogId, commentId)
+		if err != nil {
+			return domain.Notification{}, false
+		}
+		return domain.Notification{
+			UserId:    comment.AuthorId,
+			ActorId:   userId,
+			Type:      domain.NotificationReaction,
+			BlogId:    blogId,
+			CommentId: commentId,
+			Message:   "Someone " + reaction + "d your comment",
+			Key:       "reaction:" + blogId + ":" + commentId + ":" + userId,
+		}, true
+	})
+}
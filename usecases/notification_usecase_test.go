@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+func TestNotify(t *testing.T) {
+	repo := mocks.NewNotificationRepository(t)
+	repo.On("GetPreferences", "u1").Return(domain.NotificationPreferences{UserId: "u1", Events: map[string]bool{domain.NotificationReaction: false}}, nil)
+	repo.On("CreateNotification", mock.MatchedBy(func(n domain.Notification) bool {
+		return n.UserId == "u1" && n.Type == domain.NotificationComment
+	})).Return(domain.Notification{}, nil).Once()
+	uc := NewNotificationUsecase(repo)
+
+	stored, err := uc.Notify(domain.Notification{UserId: "u1", ActorId: "u2", Type: domain.NotificationComment})
+	assert.NoError(t, err)
+	assert.True(t, stored)
+
+	// turned off by the user
+	stored, err = uc.Notify(domain.Notification{UserId: "u1", ActorId: "u2", Type: domain.NotificationReaction})
+	assert.NoError(t, err)
+	assert.False(t, stored)
+
+	// users are not notified about their own actions
+	stored, err = uc.Notify(domain.Notification{UserId: "u1", ActorId: "u1", Type: domain.NotificationComment})
+	assert.NoError(t, err)
+	assert.False(t, stored)
+}
+
+func TestUpdateNotificationPreferences(t *testing.T) {
+	repo := mocks.NewNotificationRepository(t)
+	uc := NewNotificationUsecase(repo)
+
+	_, err := uc.UpdatePreferences("u1", map[string]bool{"birthday": true})
+	assert.Error(t, err)
+
+	events := map[string]bool{domain.NotificationMention: false}
+	repo.On("UpdatePreferences", domain.NotificationPreferences{UserId: "u1", Events: events}).Return(domain.NotificationPreferences{UserId: "u1", Events: events}, nil).Once()
+	prefs, err := uc.UpdatePreferences("u1", events)
+	assert.NoError(t, err)
+	assert.False(t, prefs.Enabled(domain.NotificationMention))
+	assert.True(t, prefs.Enabled(domain.NotificationReply))
+}
+
+func TestCommentReactionsNotifyOnlyWhenAdded(t *testing.T) {
+	blogs := mocks.NewBlogRepository(t)
+	repo := mocks.NewNotificationRepository(t)
+	uc := NewBlogUsecase(blogs)
+	uc.SetNotifications(NewNotificationUsecase(repo))
+	created := make(chan domain.Notification, 3)
+	blogs.On("GetCommentById", "b1", "c1").Return(domain.Comment{CommentId: "c1", AuthorId: "u1"}, nil).Maybe()
+	repo.On("GetPreferences", "u1").Return(domain.NotificationPreferences{UserId: "u1"}, nil).Maybe()
+	repo.On("CreateNotification", mock.Anything).Return(func(n domain.Notification) (domain.Notification, error) {
+		created <- n
+		return n, nil
+	}).Maybe()
+
+	blogs.On("LikeOrDislikeComment", "b1", "c1", "u2", 1).Return(true, nil).Once()
+	assert.NoError(t, uc.LikeComment("b1", "c1", "u2"))
+	select {
+	case n := <-created:
+		assert.Equal(t, "Someone liked your comment", n.Message)
+	case <-time.After(time.Second):
+		t.Fatal("a new like was not notified")
+	}
+
+	// liking again, or a dislike the user had already left, adds nothing
+	blogs.On("LikeOrDislikeComment", "b1", "c1", "u2", 1).Return(false, nil).Once()
+	assert.NoError(t, uc.LikeComment("b1", "c1", "u2"))
+	blogs.On("LikeOrDislikeComment", "b1", "c1", "u2", -1).Return(false, nil).Once()
+	assert.NoError(t, uc.DislikeComment("b1", "c1", "u2"))
+	select {
+	case n := <-created:
+		t.Errorf("notified %q without a new reaction", n.Message)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
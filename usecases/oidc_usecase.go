@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+)
+
+// oidcStateTTL is how long a user has to log in at the provider.
+const oidcStateTTL = 10 * time.Minute
+
+var usernameUnsafe = regexp.MustCompile(`[^a-z0-9_.]+`)
+
+var errOIDCAccountNotActivated = errors.New("an account with this email address is not activated yet, activate it before logging in with a provider")
+
+// OIDCResult is how a provider callback ended: a login of User, or a
+// provider account linked to them.
+type OIDCResult struct {
+	User   domain.User
+	Linked bool
+}
+
+// OIDCUsecase logs users in with OpenID Connect providers, creating their
+// account on the first login or linking it to an existing, activated one by
+// verified email.
+type OIDCUsecase struct {
+	providers          map[string]domain.OIDCProvider
+	identityRepository domain.IdentityRepository
+	userRepository     domain.UserRepository
+	now                func() time.Time
+}
+
+func NewOIDCUsecase(identities domain.IdentityRepository, users domain.UserRepository, providers ...domain.OIDCProvider) *OIDCUsecase {
+	uc := &OIDCUsecase{
+		providers:          map[string]domain.OIDCProvider{},
+		identityRepository: identities,
+		userRepository:     users,
+		now:                time.Now,
+	}
+	for _, provider := range providers {
+		uc.providers[provider.Name()] = provider
+	}
+	return uc
+}
+
+// Providers lists the names of the configured providers.
+func (uc *OIDCUsecase) Providers() []string {
+	names := []string{}
+	for name := range uc.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Begin returns the URL of the provider to send the browser to. linkUserId
+// is the logged in user who links the provider account, empty for a login.
+func (uc *OIDCUsecase) Begin(providerName, linkUserId string) (string, error) {
+	provider, ok := uc.providers[providerName]
+	if !ok {
+		return "", fmt.Errorf("unknown provider %q", providerName)
+	}
+	state, stateHash, err := infrastructure.NewRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, _, err := infrastructure.NewRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, challenge := infrastructure.NewPKCE()
+	err = uc.identityRepository.SaveState(domain.OIDCState{
+		StateId:   stateHash,
+		Provider:  providerName,
+		Nonce:     nonce,
+		Verifier:  verifier,
+		UserId:    linkUserId,
+		ExpiresAt: uc.now().Add(oidcStateTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	return provider.AuthCodeURL(state, nonce, challenge)
+}
+
+// Callback finishes the flow the provider redirected back from.
+func (uc *OIDCUsecase) Callback(providerName, state, code string) (OIDCResult, error) {
+	provider, ok := uc.providers[providerName]
+	if !ok {
+		return OIDCResult{}, fmt.Errorf("unknown provider %q", providerName)
+	}
+	if state == "" || code == "" {
+		return OIDCResult{}, errors.New("state and code are required")
+	}
+	pending, err := uc.identityRepository.TakeState(infrastructure.HashRefreshToken(state))
+	if err != nil || pending.Provider != providerName || !pending.ExpiresAt.After(uc.now()) {
+		return OIDCResult{}, errors.New("login expired or already used, please try again")
+	}
+	claims, err := provider.Exchange(code, pending.Verifier)
+	if err != nil {
+		return OIDCResult{}, err
+	}
+	if claims.Nonce != pending.Nonce {
+		return OIDCResult{}, errors.New("id_token was not issued for this login")
+	}
+
+	identity, err := uc.identityRepository.GetIdentity(providerName, claims.Subject)
+	known := err == nil && identity.UserId != ""
+	if pending.UserId != "" {
+		if known && identity.UserId != pending.UserId {
+			return OIDCResult{}, errors.New("this account is linked to another user")
+		}
+		user, err := uc.user(pending.UserId)
+		if err != nil {
+			return OIDCResult{}, err
+		}
+		return OIDCResult{User: user, Linked: true}, uc.link(providerName, claims, user.ID)
+	}
+	if known {
+		user, err := uc.user(identity.UserId)
+		if err != nil {
+			return OIDCResult{}, err
+		}
+		if !user.IsActive {
+			return OIDCResult{}, ErrAccountNotActivated
+		}
+		return OIDCResult{User: user}, nil
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return OIDCResult{}, errors.New("the provider did not share a verified email address")
+	}
+	email := strings.ToLower(claims.Email)
+	user, err := uc.findByEmail(email)
+	if err != nil {
+		user, err = uc.createUser(email, claims)
+		if err != nil {
+			return OIDCResult{}, err
+		}
+	}
+	if !user.IsActive {
+		// anyone may have registered the address with a password of their
+		// own, so the account is not taken over before its owner activates it
+		return OIDCResult{}, errOIDCAccountNotActivated
+	}
+	return OIDCResult{User: user}, uc.link(providerName, claims, user.ID)
+}
+
+func (uc *OIDCUsecase) user(userId string) (domain.User, error) {
+	users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{UserId: userId}})
+	if err != nil || len(users) == 0 {
+		return domain.User{}, errors.New("user not found")
+	}
+	return users[0], nil
+}
+
+func (uc *OIDCUsecase) findByEmail(email string) (domain.User, error) {
+	users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{Email: email}})
+	if err != nil || len(users) == 0 || users[0].ID == "" {
+		return domain.User{}, errors.New("user not found")
+	}
+	return users[0], nil
+}
+
+// createUser registers the user of a first login. They have no password and
+// log in with the provider until they set one.
+func (uc *OIDCUsecase) createUser(email string, claims domain.OIDCClaims) (domain.User, error) {
+	username, err := uc.freeUsername(email)
+	if err != nil {
+		return domain.User{}, err
+	}
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" && lastName == "" {
+		firstName = claims.Name
+	}
+	return uc.userRepository.Create(&domain.User{
+		Username:  username,
+		Email:     email,
+		FirstName: firstName,
+		LastName:  lastName,
+		IsActive:  true,
+	})
+}
+
+// freeUsername derives a username from the email address, adding a random
+// suffix when it is taken.
+func (uc *OIDCUsecase) freeUsername(email string) (string, error) {
+	base := usernameUnsafe.ReplaceAllString(strings.ToLower(strings.SplitN(email, "@", 2)[0]), "_")
+	if base == "" {
+		base = "user"
+	}
+	candidate := base
+	for i := 0; i < 5; i++ {
+		users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{Username: candidate}})
+		if err != nil || len(users) == 0 || users[0].ID == "" {
+			return candidate, nil
+		}
+		candidate = base + "_" + strings.ToLower(string(newConfirmationToken()[:4]))
+	}
+	return "", errors.New("could not find a free username")
+}
+
+func (uc *OIDCUsecase) link(providerName string, claims domain.OIDCClaims, userId string) error {
+	return uc.identityRepository.SaveIdentity(domain.Identity{
+		Provider: providerName,
+		Subject:  claims.Subject,
+		UserId:   userId,
+		Email:    claims.Email,
+		LinkedAt: uc.now(),
+	})
+}
+
+func (uc *OIDCUsecase) GetIdentities(userId string) ([]domain.Identity, error) {
+	return uc.identityRepository.GetIdentities(userId)
+}
+
+// Unlink removes a provider account from the user. The last one of a user
+// without a password stays, they could not log in anymore.
+func (uc *OIDCUsecase) Unlink(userId, providerName string) error {
+	identities, err := uc.identityRepository.GetIdentities(userId)
+	if err != nil {
+		return err
+	}
+	user, err := uc.user(userId)
+	if err != nil {
+		return err
+	}
+	if user.Password == "" && len(identities) <= 1 {
+		return errors.New("set a password before unlinking your last login provider")
+	}
+	unlinked, err := uc.identityRepository.DeleteIdentity(userId, providerName)
+	if err != nil {
+		return err
+	}
+	if !unlinked {
+		return errors.New("provider is not linked")
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+// fakeOIDCServer is a local stand-in for an OpenID Connect provider. The
+// user "logs in" with authorize, which issues a code for the given claims.
+type fakeOIDCServer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]fakeGrant
+}
+
+type fakeGrant struct {
+	challenge string
+	nonce     string
+	claims    domain.OIDCClaims
+}
+
+func newFakeOIDCServer(t *testing.T) *fakeOIDCServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	server := &fakeOIDCServer{key: key, codes: map[string]fakeGrant{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 server.URL,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+			"jwks_uri":               server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test-key",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", server.token)
+	server.Server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// authorize plays the user logging in at the provider for the auth URL and
+// returns the state and code of the redirect back.
+func (s *fakeOIDCServer) authorize(t *testing.T, authURL string, claims domain.OIDCClaims) (string, string) {
+	parsed, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	code := randomCode()
+	s.mu.Lock()
+	s.codes[code] = fakeGrant{challenge: query.Get("code_challenge"), nonce: query.Get("nonce"), claims: claims}
+	s.mu.Unlock()
+	return query.Get("state"), code
+}
+
+func (s *fakeOIDCServer) token(w http.ResponseWriter, r *http.Request) {
+	clientId, clientSecret, _ := r.BasicAuth()
+	if clientId != "blogmate" || clientSecret != "secret" {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+	s.mu.Lock()
+	grant, ok := s.codes[r.FormValue("code")]
+	delete(s.codes, r.FormValue("code"))
+	s.mu.Unlock()
+	sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            s.URL,
+		"aud":            "blogmate",
+		"sub":            grant.claims.Subject,
+		"email":          grant.claims.Email,
+		"email_verified": grant.claims.EmailVerified,
+		"name":           grant.claims.Name,
+		"nonce":          grant.nonce,
+		"exp":            time.Now().Add(time.Minute).Unix(),
+	})
+	token.Header["kid"] = "test-key"
+	signed, _ := token.SignedString(s.key)
+	json.NewEncoder(w).Encode(map[string]string{"access_token": "unused", "id_token": signed})
+}
+
+func randomCode() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// newOIDCTest wires the usecase to the stand-in provider, with the pending
+// logins kept in memory.
+func newOIDCTest(t *testing.T) (*OIDCUsecase, *fakeOIDCServer, *mocks.IdentityRepository, *mocks.UserRepository) {
+	server := newFakeOIDCServer(t)
+	identities := mocks.NewIdentityRepository(t)
+	users := mocks.NewUserRepository(t)
+	provider := infrastructure.NewOIDCProvider(infrastructure.OIDCConfig{
+		Name:         "test",
+		Issuer:       server.URL,
+		ClientId:     "blogmate",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/auth/oidc/test/callback",
+	}, server.Client())
+
+	states := map[string]domain.OIDCState{}
+	identities.On("SaveState", mock.Anything).Return(func(state domain.OIDCState) error {
+		states[state.StateId] = state
+		return nil
+	}).Maybe()
+	identities.On("TakeState", mock.Anything).Return(func(stateId string) (domain.OIDCState, error) {
+		state, ok := states[stateId]
+		delete(states, stateId)
+		if !ok {
+			return domain.OIDCState{}, assert.AnError
+		}
+		return state, nil
+	}).Maybe()
+	return NewOIDCUsecase(identities, users, provider), server, identities, users
+}
+
+func TestOIDCFirstLoginCreatesTheUser(t *testing.T) {
+	uc, server, identities, users := newOIDCTest(t)
+	claims := domain.OIDCClaims{Subject: "sub-1", Email: "Ada@Example.com", EmailVerified: true, Name: "Ada"}
+
+	authURL, err := uc.Begin("test", "")
+	assert.NoError(t, err)
+	assert.Contains(t, authURL, server.URL+"/authorize?")
+	state, code := server.authorize(t, authURL, claims)
+
+	identities.On("GetIdentity", "test", "sub-1").Return(domain.Identity{}, assert.AnError).Once()
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Email: "ada@example.com"}}).Return([]domain.User{{}}, assert.AnError)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Username: "ada"}}).Return([]domain.User{{}}, assert.AnError)
+	created := domain.User{ID: "u1", Username: "ada", Email: "ada@example.com", FirstName: "Ada", IsActive: true}
+	users.On("Create", &domain.User{Username: "ada", Email: "ada@example.com", FirstName: "Ada", IsActive: true}).Return(created, nil).Once()
+	identities.On("SaveIdentity", mock.MatchedBy(func(identity domain.Identity) bool {
+		return identity.Provider == "test" && identity.Subject == "sub-1" && identity.UserId == "u1"
+	})).Return(nil).Once()
+
+	result, err := uc.Callback("test", state, code)
+	assert.NoError(t, err)
+	assert.Equal(t, OIDCResult{User: created}, result)
+
+	_, err = uc.Callback("test", state, code)
+	assert.Error(t, err, "a state is used once")
+
+	// the next login finds the linked identity
+	authURL, _ = uc.Begin("test", "")
+	state, code = server.authorize(t, authURL, claims)
+	identities.On("GetIdentity", "test", "sub-1").Return(domain.Identity{Provider: "test", Subject: "sub-1", UserId: "u1"}, nil).Once()
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{UserId: "u1"}}).Return([]domain.User{created}, nil).Once()
+	result, err = uc.Callback("test", state, code)
+	assert.NoError(t, err)
+	assert.Equal(t, created, result.User)
+}
+
+func TestOIDCLinksExistingUserByVerifiedEmail(t *testing.T) {
+	uc, server, identities, users := newOIDCTest(t)
+	existing := domain.User{ID: "u2", Email: "grace@example.com", Password: "hash", IsActive: true}
+	identities.On("GetIdentity", "test", mock.Anything).Return(domain.Identity{}, assert.AnError)
+
+	authURL, _ := uc.Begin("test", "")
+	state, code := server.authorize(t, authURL, domain.OIDCClaims{Subject: "sub-2", Email: "grace@example.com"})
+	_, err := uc.Callback("test", state, code)
+	assert.Error(t, err, "unverified emails are not trusted")
+
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Email: "grace@example.com"}}).Return([]domain.User{existing}, nil).Once()
+	identities.On("SaveIdentity", mock.MatchedBy(func(identity domain.Identity) bool {
+		return identity.Subject == "sub-2" && identity.UserId == "u2"
+	})).Return(nil).Once()
+	authURL, _ = uc.Begin("test", "")
+	state, code = server.authorize(t, authURL, domain.OIDCClaims{Subject: "sub-2", Email: "grace@example.com", EmailVerified: true})
+	result, err := uc.Callback("test", state, code)
+	assert.NoError(t, err)
+	assert.Equal(t, existing, result.User)
+}
+
+func TestOIDCRefusesInactiveAccounts(t *testing.T) {
+	uc, server, identities, users := newOIDCTest(t)
+	// registered by someone else with a password of their own, never activated
+	pending := domain.User{ID: "u5", Email: "hedy@example.com", Password: "hash"}
+	claims := domain.OIDCClaims{Subject: "sub-5", Email: "hedy@example.com", EmailVerified: true}
+
+	identities.On("GetIdentity", "test", "sub-5").Return(domain.Identity{}, assert.AnError).Once()
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Email: "hedy@example.com"}}).Return([]domain.User{pending}, nil).Once()
+	authURL, _ := uc.Begin("test", "")
+	state, code := server.authorize(t, authURL, claims)
+	_, err := uc.Callback("test", state, code)
+	assert.Equal(t, errOIDCAccountNotActivated, err)
+
+	identities.On("GetIdentity", "test", "sub-5").Return(domain.Identity{Provider: "test", Subject: "sub-5", UserId: "u5"}, nil).Once()
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{UserId: "u5"}}).Return([]domain.User{pending}, nil).Once()
+	authURL, _ = uc.Begin("test", "")
+	state, code = server.authorize(t, authURL, claims)
+	_, err = uc.Callback("test", state, code)
+	assert.Equal(t, ErrAccountNotActivated, err)
+}
+
+func TestOIDCRejectsTamperedLogins(t *testing.T) {
+	uc, server, _, _ := newOIDCTest(t)
+	claims := domain.OIDCClaims{Subject: "sub-3", Email: "eve@example.com", EmailVerified: true}
+
+	_, err := uc.Begin("unknown", "")
+	assert.Error(t, err)
+
+	// a code issued for another login's PKCE challenge is refused
+	first, _ := uc.Begin("test", "")
+	second, _ := uc.Begin("test", "")
+	_, code := server.authorize(t, first, claims)
+	state, _ := server.authorize(t, second, claims)
+	_, err = uc.Callback("test", state, code)
+	assert.Error(t, err)
+
+	_, err = uc.Callback("test", "made-up", "code")
+	assert.Error(t, err)
+}
+
+func TestOIDCLinkAndUnlink(t *testing.T) {
+	uc, server, identities, users := newOIDCTest(t)
+	user := domain.User{ID: "u4", Email: "linus@example.com", IsActive: true}
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{UserId: "u4"}}).Return([]domain.User{user}, nil)
+
+	// linking an account linked to someone else fails
+	identities.On("GetIdentity", "test", "taken").Return(domain.Identity{UserId: "other"}, nil).Once()
+	authURL, _ := uc.Begin("test", "u4")
+	state, code := server.authorize(t, authURL, domain.OIDCClaims{Subject: "taken"})
+	_, err := uc.Callback("test", state, code)
+	assert.Error(t, err)
+
+	// the email of a linked account does not need to match or be verified
+	identities.On("GetIdentity", "test", "sub-4").Return(domain.Identity{}, assert.AnError).Once()
+	identities.On("SaveIdentity", mock.MatchedBy(func(identity domain.Identity) bool {
+		return identity.Subject == "sub-4" && identity.UserId == "u4"
+	})).Return(nil).Once()
+	authURL, _ = uc.Begin("test", "u4")
+	state, code = server.authorize(t, authURL, domain.OIDCClaims{Subject: "sub-4", Email: "work@example.org"})
+	result, err := uc.Callback("test", state, code)
+	assert.NoError(t, err)
+	assert.Equal(t, OIDCResult{User: user, Linked: true}, result)
+
+	// the only way to log in of a user without a password stays
+	identities.On("GetIdentities", "u4").Return([]domain.Identity{{Provider: "test", UserId: "u4"}}, nil)
+	assert.Error(t, uc.Unlink("u4", "test"))
+}
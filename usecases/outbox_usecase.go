@@ -0,0 +1,273 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yesetoda/BlogMate/config"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+)
+
+const (
+	outboxMaxAttempts = 10
+	outboxBaseBackoff = 10 * time.Second
+	outboxMaxBackoff  = time.Hour
+	outboxBatchSize   = 50
+	// outboxMessageTimeout is the longest a handler takes with one message,
+	// emails are the slowest and their SMTP connection times out sooner.
+	outboxMessageTimeout = 30 * time.Second
+	// outboxLock outlasts a batch, whose messages are handled one after the
+	// other, so no other dispatcher claims them again while they are sent.
+	outboxLock = (outboxBatchSize + 1) * outboxMessageTimeout
+)
+
+// OutboxHandler delivers one message. It may be called more than once for the
+// same message, handlers use the idempotency key to drop repeats where the
+// receiver allows it.
+type OutboxHandler func(message domain.OutboxMessage) error
+
+type OutboxUsecase struct {
+	outboxRepository domain.OutboxRepository
+	transactor       domain.Transactor
+	handlers         map[string]OutboxHandler
+	now              func() time.Time
+	wake             chan struct{}
+}
+
+func NewOutboxUsecase(repo domain.OutboxRepository, transactor domain.Transactor) *OutboxUsecase {
+	return &OutboxUsecase{
+		outboxRepository: repo,
+		transactor:       transactor,
+		handlers:         map[string]OutboxHandler{},
+		now:              time.Now,
+		wake:             make(chan struct{}, 1),
+	}
+}
+
+// Handle sets the handler of a message kind. Handlers are registered before
+// Run is started.
+func (uc *OutboxUsecase) Handle(kind string, handler OutboxHandler) {
+	uc.handlers[kind] = handler
+}
+
+// WithTransaction runs fn in a transaction, domain writes and Enqueue calls
+// made with its ctx are stored together or not at all.
+func (uc *OutboxUsecase) WithTransaction(fn func(ctx context.Context) error) error {
+	if uc.transactor == nil {
+		return fn(context.Background())
+	}
+	return uc.transactor.WithTransaction(fn)
+}
+
+// Enqueue stores a message for the dispatcher. Use the ctx of WithTransaction
+// to store it together with a domain change.
+func (uc *OutboxUsecase) Enqueue(ctx context.Context, kind, idempotencyKey string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	err = uc.outboxRepository.Enqueue(ctx, []domain.OutboxMessage{{
+		Kind:           kind,
+		IdempotencyKey: idempotencyKey,
+		Payload:        string(data),
+		CreatedAt:      uc.now(),
+	}})
+	if err != nil {
+		return err
+	}
+	uc.signal()
+	return nil
+}
+
+func (uc *OutboxUsecase) signal() {
+	select {
+	case uc.wake <- struct{}{}:
+	default:
+	}
+}
+
+// outboxEvent is the payload of a webhook message.
+type outboxEvent struct {
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// EnqueueEvent stores a webhook event, the idempotency key becomes the event
+// id seen by receivers.
+func (uc *OutboxUsecase) EnqueueEvent(ctx context.Context, event, idempotencyKey string, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return uc.Enqueue(ctx, domain.OutboxWebhook, idempotencyKey, outboxEvent{Event: event, CreatedAt: uc.now(), Data: raw})
+}
+
+func outboxBackoff(failed int) time.Duration {
+	wait := outboxBaseBackoff
+	for i := 1; i < failed; i++ {
+		wait *= 2
+		if wait >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return wait
+}
+
+// DispatchDue hands the due messages to their handlers and returns how many
+// were handled. Messages that keep failing end up in the dead letters.
+func (uc *OutboxUsecase) DispatchDue() (int, error) {
+	messages, err := uc.outboxRepository.Claim(uc.now(), outboxLock, outboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, m := range messages {
+		handler, ok := uc.handlers[m.Kind]
+		if !ok {
+			err = fmt.Errorf("no handler for %q messages", m.Kind)
+		} else {
+			err = handler(m)
+		}
+		if err == nil {
+			err = uc.outboxRepository.MarkDelivered(m.MessageId, uc.now())
+		} else {
+			failed := m.Attempts + 1
+			dead := !ok || failed >= outboxMaxAttempts
+			if dead {
+				log.Println("outbox message", m.MessageId, "moved to dead letters:", err)
+			}
+			err = uc.outboxRepository.MarkFailed(m.MessageId, err.Error(), uc.now().Add(outboxBackoff(failed)), dead)
+		}
+		if err != nil {
+			// the message is handed out again once its lock expires, the
+			// rest of the batch is still handled
+			log.Println("recording the outcome of outbox message", m.MessageId, "failed:", err)
+		}
+	}
+	return len(messages), nil
+}
+
+// Run dispatches messages until stop is closed. It wakes up every interval
+// and whenever a message is enqueued by this process.
+func (uc *OutboxUsecase) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			handled, err := uc.DispatchDue()
+			if err != nil {
+				log.Println("dispatching outbox messages failed:", err)
+			}
+			if err != nil || handled < outboxBatchSize {
+				break
+			}
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-uc.wake:
+		}
+	}
+}
+
+func (uc *OutboxUsecase) GetMessages(status string, opts domain.PaginationInfo) ([]domain.OutboxMessage, error) {
+	messages, err := uc.outboxRepository.GetMessages(status, opts)
+	if err != nil {
+		return []domain.OutboxMessage{}, err
+	}
+	return messages, nil
+}
+
+// Retry moves a dead message back to the queue.
+func (uc *OutboxUsecase) Retry(messageId string) error {
+	if err := uc.outboxRepository.Requeue(messageId, uc.now()); err != nil {
+		return err
+	}
+	uc.signal()
+	return nil
+}
+
+// EmailOutboxHandler returns the handler of email messages, which renders
+// their template and sends them with mailer.
+func EmailOutboxHandler(mailer infrastructure.Mailer) OutboxHandler {
+	return func(message domain.OutboxMessage) error {
+		var payload domain.OutboxEmailPayload
+		if err := json.Unmarshal([]byte(message.Payload), &payload); err != nil {
+			return err
+		}
+		template := payload.Template
+		if template == "" {
+			template = infrastructure.NotificationEmail
+		}
+		data := infrastructure.EmailData{Name: payload.Name, Title: payload.Title, Body: payload.Body, Link: payload.Link, UnsubscribeLink: payload.Unsubscribe}
+		email, err := infrastructure.RenderEmail(template, payload.Locale, data)
+		if err != nil {
+			return err
+		}
+		if payload.Template == "" && payload.Title != "" {
+			email.Subject = payload.Title
+		}
+		email.To = []string{payload.To}
+		return mailer.Send(email)
+	}
+}
+
+// createWithOutbox stores a new user together with its verification email and
+// user.registered event, so neither is lost when sending fails or the process
+// stops, and a failed send no longer fails the registration.
+func (useCase *userUsecase) createWithOutbox(u *domain.User) (domain.User, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return domain.User{}, err
+	}
+	var nUser domain.User
+	err = useCase.outbox.WithTransaction(func(ctx context.Context) error {
+		var err error
+		nUser, err = useCase.userRepository.CreateWithContext(ctx, u)
+		if err != nil {
+			return err
+		}
+		if !nUser.IsAdmin {
+			link := cfg.Port + "/users/accountVerification/?email=" + nUser.Email + "&token=" + nUser.VerifyToken
+			email := domain.OutboxEmailPayload{To: nUser.Email, Template: infrastructure.VerificationEmail, Locale: nUser.Locale, Name: displayName(nUser), Link: link}
+			if err := useCase.outbox.Enqueue(ctx, domain.OutboxEmail, "verify:"+nUser.ID, email); err != nil {
+				return err
+			}
+		}
+		data := registeredUser{ID: nUser.ID, Username: nUser.Username, Email: nUser.Email, FirstName: nUser.FirstName, LastName: nUser.LastName}
+		return useCase.outbox.EnqueueEvent(ctx, domain.EventUserRegistered, domain.EventUserRegistered+":"+nUser.ID, data)
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+	return nUser, nil
+}
+
+// SetOutbox stores the blog and comment events, e.g. for webhooks, in the
+// outbox together with the change they announce.
+func (uc *BlogUsecase) SetOutbox(outbox *OutboxUsecase) {
+	uc.outbox = outbox
+}
+
+// writeWithEvent makes a blog change and stores its event in one transaction,
+// so the event is queued exactly when the change is. write returns the id
+// that makes the event unique and its data, and may run again when the
+// transaction is retried.
+func (uc *BlogUsecase) writeWithEvent(event string, write func(ctx context.Context) (string, interface{}, error)) error {
+	if uc.outbox == nil {
+		_, _, err := write(context.Background())
+		return err
+	}
+	return uc.outbox.WithTransaction(func(ctx context.Context) error {
+		id, data, err := write(ctx)
+		if err != nil {
+			return err
+		}
+		return uc.outbox.EnqueueEvent(ctx, event, event+":"+id, data)
+	})
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+func TestDispatchDue(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := mocks.NewOutboxRepository(t)
+	uc := NewOutboxUsecase(repo, nil)
+	uc.now = func() time.Time { return now }
+
+	handled := []string{}
+	uc.Handle(domain.OutboxEmail, func(m domain.OutboxMessage) error {
+		handled = append(handled, m.MessageId)
+		if m.MessageId == "broken" {
+			return errors.New("smtp is down")
+		}
+		return nil
+	})
+
+	repo.On("Claim", now, outboxLock, outboxBatchSize).Return([]domain.OutboxMessage{
+		{MessageId: "ok", Kind: domain.OutboxEmail},
+		{MessageId: "broken", Kind: domain.OutboxEmail, Attempts: 2},
+		{MessageId: "exhausted", Kind: domain.OutboxWebhook},
+	}, nil).Once()
+	repo.On("MarkDelivered", "ok", now).Return(errors.New("connection reset")).Once()
+	repo.On("MarkFailed", "broken", "smtp is down", now.Add(40*time.Second), false).Return(nil).Once()
+	// nothing handles webhooks here, the message goes straight to the dead letters
+	repo.On("MarkFailed", "exhausted", mock.Anything, mock.Anything, true).Return(nil).Once()
+
+	count, err := uc.DispatchDue()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count, "a message that could not be marked does not stop the batch")
+	assert.Equal(t, []string{"ok", "broken"}, handled)
+
+	repo.On("Claim", now, outboxLock, outboxBatchSize).Return([]domain.OutboxMessage{
+		{MessageId: "last", Kind: domain.OutboxEmail, Attempts: outboxMaxAttempts - 1},
+	}, nil).Once()
+	uc.Handle(domain.OutboxEmail, func(m domain.OutboxMessage) error { return errors.New("still down") })
+	repo.On("MarkFailed", "last", "still down", mock.Anything, true).Return(nil).Once()
+	_, err = uc.DispatchDue()
+	assert.NoError(t, err)
+}
+
+func TestCreateUserWithOutbox(t *testing.T) {
+	users := mocks.NewUserRepository(t)
+	outboxRepo := mocks.NewOutboxRepository(t)
+	transactor := mocks.NewTransactor(t)
+	txCtx := context.WithValue(context.Background(), struct{}{}, "tx")
+	transactor.On("WithTransaction", mock.Anything).Return(func(fn func(ctx context.Context) error) error {
+		return fn(txCtx)
+	}).Once()
+	outbox := NewOutboxUsecase(outboxRepo, transactor)
+	uc, _ := NewUserUsecase(users, WithUserOutbox(outbox))
+
+	users.On("CreateWithContext", txCtx, mock.Anything).Return(func(ctx context.Context, u *domain.User) (domain.User, error) {
+		u.ID = "u1"
+		return *u, nil
+	}).Once()
+	outboxRepo.On("Enqueue", txCtx, mock.MatchedBy(func(m []domain.OutboxMessage) bool {
+		return len(m) == 1 && m[0].Kind == domain.OutboxEmail && m[0].IdempotencyKey == "verify:u1"
+	})).Return(nil).Once()
+	outboxRepo.On("Enqueue", txCtx, mock.MatchedBy(func(m []domain.OutboxMessage) bool {
+		return len(m) == 1 && m[0].Kind == domain.OutboxWebhook && m[0].IdempotencyKey == "user.registered:u1"
+	})).Return(nil).Once()
+
+	created, err := uc.Create(&domain.User{Username: "john", Email: "john@example.com", Password: "correct horse 42"})
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", created.ID)
+	assert.False(t, created.IsActive)
+}
+
+func TestBlogEventsAreWrittenWithTheChange(t *testing.T) {
+	blogs := mocks.NewBlogRepository(t)
+	outboxRepo := mocks.NewOutboxRepository(t)
+	transactor := mocks.NewTransactor(t)
+	txCtx := context.WithValue(context.Background(), struct{}{}, "tx")
+	transactor.On("WithTransaction", mock.Anything).Return(func(fn func(ctx context.Context) error) error {
+		return fn(txCtx)
+	})
+	uc := NewBlogUsecase(blogs)
+	uc.SetOutbox(NewOutboxUsecase(outboxRepo, transactor))
+
+	blogs.On("CreateBlogWithContext", txCtx, mock.Anything).Return(func(ctx context.Context, b domain.Blog) (domain.Blog, error) {
+		b.BlogId = "b1"
+		return b, nil
+	}).Once()
+	outboxRepo.On("Enqueue", txCtx, mock.MatchedBy(func(m []domain.OutboxMessage) bool {
+		return len(m) == 1 && m[0].Kind == domain.OutboxWebhook && m[0].IdempotencyKey == "blog.created:b1"
+	})).Return(nil).Once()
+	_, err := uc.CreateBLog(domain.Blog{AuthorId: "u1", Title: "Go", Content: "Generics are here."})
+	assert.NoError(t, err)
+
+	blogs.On("AddCommentWithContext", txCtx, "b1", mock.Anything).Return(domain.Comment{CommentId: "c1", BlogId: "b1"}, nil).Once()
+	outboxRepo.On("Enqueue", txCtx, mock.MatchedBy(func(m []domain.OutboxMessage) bool {
+		return len(m) == 1 && m[0].IdempotencyKey == "comment.created:c1"
+	})).Return(assert.AnError).Once()
+	_, err = uc.AddComment("b1", domain.Comment{Content: "Nice."})
+	assert.Equal(t, assert.AnError, err, "the comment is rolled back when its event cannot be stored")
+
+	blogs.On("DeleteBlogWithContext", txCtx, "b2", "u1").Return(assert.AnError).Once()
+	assert.Error(t, uc.DeleteBLog("b2", "u1"))
+	outboxRepo.AssertNumberOfCalls(t, "Enqueue", 2)
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+)
+
+// maxPasswordBytes is the most bcrypt reads of a password.
+const maxPasswordBytes = 72
+
+// DefaultPasswordPolicy applies unless the usecase is given another one.
+var DefaultPasswordPolicy = domain.PasswordPolicy{MinLength: 8, MinCharacterClasses: 2, History: 5}
+
+// WithPasswordPolicy replaces the default password policy.
+func WithPasswordPolicy(policy domain.PasswordPolicy) UserUsecaseOption {
+	return func(uc *userUsecase) {
+		uc.passwordPolicy = policy
+	}
+}
+
+// WithBreachedPasswords refuses the passwords known from data breaches.
+func WithBreachedPasswords(breached domain.BreachedPasswordChecker) UserUsecaseOption {
+	return func(uc *userUsecase) {
+		uc.breached = breached
+	}
+}
+
+// validatePassword checks the new password of the user against the policy,
+// the breached passwords and their previous passwords.
+func (useCase *userUsecase) validatePassword(user domain.User, password string) error {
+	policy := useCase.passwordPolicy
+	if len([]rune(password)) < policy.MinLength {
+		return fmt.Errorf("the password must be at least %d characters long", policy.MinLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("the password must be at most %d bytes long", maxPasswordBytes)
+	}
+	if characterClasses(password) < policy.MinCharacterClasses {
+		return fmt.Errorf("the password must mix at least %d of lowercase letters, uppercase letters, digits and symbols", policy.MinCharacterClasses)
+	}
+	localPart, _, _ := strings.Cut(user.Email, "@")
+	for _, personal := range []string{user.Username, user.Email, localPart} {
+		if personal != "" && strings.EqualFold(password, personal) {
+			return errors.New("the password must not be your username or email")
+		}
+	}
+	if useCase.breached != nil {
+		breached, err := useCase.breached.IsBreached(password)
+		if err != nil {
+			// the other rules still apply when the list cannot be read
+			log.Println("checking the breached passwords failed:", err)
+		}
+		if breached {
+			return errors.New("this password appeared in a data breach, choose another one")
+		}
+	}
+	previous := append([]string{user.Password}, user.PasswordHistory...)
+	for i, hash := range previous {
+		if i >= policy.History {
+			break
+		}
+		if hash == "" {
+			continue
+		}
+		if ok, _ := infrastructure.VerifyPassword(hash, password); ok {
+			return errors.New("choose a password you have not used recently")
+		}
+	}
+	return nil
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			classes++
+		}
+	}
+	return classes
+}
+
+// newPassword validates and hashes the new password of the user, and returns
+// their password history with the current password added. The history keeps
+// the passwords before the new one that the policy still refuses.
+func (useCase *userUsecase) newPassword(user domain.User, password string) (string, []string, error) {
+	if err := useCase.validatePassword(user, password); err != nil {
+		return "", nil, err
+	}
+	hash, err := infrastructure.PasswordHasher(password)
+	if err != nil {
+		return "", nil, err
+	}
+	history := []string{}
+	if user.Password != "" {
+		history = append(history, user.Password)
+	}
+	history = append(history, user.PasswordHistory...)
+	if keep := max(useCase.passwordPolicy.History-1, 0); len(history) > keep {
+		history = history[:keep]
+	}
+	return hash, history, nil
+}
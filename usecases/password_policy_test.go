@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	breached, err := infrastructure.NewBreachedPasswords("")
+	assert.NoError(t, err)
+	uc := &userUsecase{passwordPolicy: domain.PasswordPolicy{MinLength: 10, MinCharacterClasses: 3, History: 2}, breached: breached}
+	old, _ := infrastructure.PasswordHasher("Old password 1")
+	older, _ := infrastructure.PasswordHasher("Older password 2")
+	user := domain.User{Username: "Ada_Lovelace_1", Email: "Ada.Lovelace1@example.com", Password: old, PasswordHistory: []string{older}}
+
+	for password, reason := range map[string]string{
+		"Short1!":                   "too short",
+		"lowercase and spaces":      "two classes",
+		"ada_lovelace_1":            "the username",
+		"ada.lovelace1@example.com": "the email",
+		"Password123":               "breached",
+		"Old password 1":            "the current password",
+		"Older password 2":          "a previous password",
+		"Ünïcødé pässwörd but 73 bytes long, which bcrypt would silently cut!!!": "too long",
+	} {
+		assert.Error(t, uc.validatePassword(user, password), reason)
+	}
+	assert.NoError(t, uc.validatePassword(user, "Brand new password 3"))
+
+	hash, history, err := uc.newPassword(user, "Brand new password 3")
+	assert.NoError(t, err)
+	ok, _ := infrastructure.VerifyPassword(hash, "Brand new password 3")
+	assert.True(t, ok)
+	assert.Equal(t, []string{old}, history, "the new password and the current one make the history of 2")
+
+	uc.passwordPolicy.History = 1
+	assert.Error(t, uc.validatePassword(user, "Old password 1"), "the current password")
+	assert.NoError(t, uc.validatePassword(user, "Older password 2"), "older than the history")
+}
+
+func TestChangePasswordHashesAndChecksTheOldOne(t *testing.T) {
+	users := mocks.NewUserRepository(t)
+	uc, _ := NewUserUsecase(users)
+	current, _ := infrastructure.PasswordHasher("current password 1")
+	user := domain.User{ID: "u1", Email: "ada@example.com", Password: current, IsActive: true}
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Email: "ada@example.com"}}).Return([]domain.User{user}, nil)
+
+	_, err := uc.ChangePassword("ada@example.com", "wrong password 1", "new password 2")
+	assert.Error(t, err)
+	_, err = uc.ChangePassword("ada@example.com", "current password 1", "current password 1")
+	assert.Error(t, err, "the same password is refused")
+
+	var stored domain.User
+	users.On("Update", "u1", mock.Anything).Return(func(_ string, update domain.User) (domain.User, error) {
+		stored = update
+		return update, nil
+	})
+	_, err = uc.ChangePassword("ada@example.com", "current password 1", "new password 2")
+	assert.NoError(t, err)
+	assert.NotEqual(t, "new password 2", stored.Password, "only the hash is stored")
+	ok, _ := infrastructure.VerifyPassword(stored.Password, "new password 2")
+	assert.True(t, ok)
+	assert.Equal(t, []string{current}, stored.PasswordHistory)
+}
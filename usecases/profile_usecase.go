@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yesetoda/BlogMate/domain"
+)
+
+// Profile limits, in characters.
+const (
+	maxDisplayNameLength = 50
+	maxBioLength         = 500
+	maxLocationLength    = 100
+	maxURLLength         = 200
+	// MaxPinnedPosts is how many of their blogs users can pin.
+	MaxPinnedPosts = 3
+)
+
+var errProfileNotFound = errors.New("profile not found")
+
+// ProfileUsecase edits the profiles of users and shows them to anyone.
+type ProfileUsecase struct {
+	profileRepository domain.ProfileRepository
+	userRepository    domain.UserRepository
+	blogRepository    domain.BlogRepository
+	media             *MediaUsecase
+	now               func() time.Time
+}
+
+func NewProfileUsecase(profiles domain.ProfileRepository, users domain.UserRepository, blogs domain.BlogRepository) *ProfileUsecase {
+	return &ProfileUsecase{profileRepository: profiles, userRepository: users, blogRepository: blogs, now: time.Now}
+}
+
+// GetMyProfile returns the profile of the user as they edit it.
+func (uc *ProfileUsecase) GetMyProfile(userId string) (domain.Profile, error) {
+	return uc.profileRepository.GetProfile(userId)
+}
+
+// UpdateProfile checks and replaces the profile of the user. Pinned posts must
+// be blogs of the user.
+func (uc *ProfileUsecase) UpdateProfile(userId string, profile domain.Profile) (domain.Profile, error) {
+	profile.UserId = userId
+	profile.DisplayName = strings.TrimSpace(profile.DisplayName)
+	profile.Bio = strings.TrimSpace(profile.Bio)
+	profile.Location = strings.TrimSpace(profile.Location)
+	if err := checkLength("display name", profile.DisplayName, maxDisplayNameLength); err != nil {
+		return domain.Profile{}, err
+	}
+	if err := checkLength("bio", profile.Bio, maxBioLength); err != nil {
+		return domain.Profile{}, err
+	}
+	if err := checkLength("location", profile.Location, maxLocationLength); err != nil {
+		return domain.Profile{}, err
+	}
+	if err := checkURL("avatar", profile.AvatarURL); err != nil {
+		return domain.Profile{}, err
+	}
+	if err := checkURL("website", profile.Website); err != nil {
+		return domain.Profile{}, err
+	}
+	for network, link := range profile.SocialLinks {
+		if !knownSocialNetwork(network) {
+			return domain.Profile{}, fmt.Errorf("unknown social network %s, use one of %s", network, strings.Join(domain.SocialNetworks, ", "))
+		}
+		if link == "" {
+			delete(profile.SocialLinks, network)
+			continue
+		}
+		if err := checkURL(network, link); err != nil {
+			return domain.Profile{}, err
+		}
+	}
+	if len(profile.PinnedPosts) > MaxPinnedPosts {
+		return domain.Profile{}, fmt.Errorf("you can pin at most %d posts", MaxPinnedPosts)
+	}
+	for i, blogId := range profile.PinnedPosts {
+		for _, other := range profile.PinnedPosts[:i] {
+			if other == blogId {
+				return domain.Profile{}, errors.New("a post is pinned twice")
+			}
+		}
+		blog, err := uc.blogRepository.GetBlogById(blogId)
+		if err != nil || blog.AuthorId != userId {
+			return domain.Profile{}, fmt.Errorf("you can only pin your own posts, %s is not one", blogId)
+		}
+	}
+	if profile.AvatarMediaId != "" {
+		avatar, err := uc.avatar(userId, profile.AvatarMediaId)
+		if err != nil {
+			return domain.Profile{}, err
+		}
+		profile.AvatarURL = avatar.ThumbnailURL
+	}
+	profile.UpdatedAt = uc.now()
+	if err := uc.profileRepository.SaveProfile(profile); err != nil {
+		return domain.Profile{}, err
+	}
+	uc.attachAvatar(userId, profile.AvatarMediaId)
+	return profile, nil
+}
+
+// GetPublicProfile returns the profile of the user with the username, as
+// anyone sees it. Users who did not activate their account have none.
+func (uc *ProfileUsecase) GetPublicProfile(username string) (domain.PublicProfile, error) {
+	users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{Username: username}})
+	if err != nil || len(users) == 0 || users[0].ID == "" || !users[0].IsActive {
+		return domain.PublicProfile{}, errProfileNotFound
+	}
+	user := users[0]
+	profile, err := uc.profileRepository.GetProfile(user.ID)
+	if err != nil {
+		return domain.PublicProfile{}, err
+	}
+	public := domain.PublicProfile{
+		Username:    user.Username,
+		DisplayName: profile.DisplayName,
+		Bio:         profile.Bio,
+		AvatarURL:   profile.AvatarURL,
+		Location:    profile.Location,
+		Website:     profile.Website,
+		SocialLinks: profile.SocialLinks,
+		Roles:       user.Roles,
+	}
+	if public.DisplayName == "" {
+		public.DisplayName = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	}
+	for _, blogId := range profile.PinnedPosts {
+		// pinned posts deleted since are left out
+		blog, err := uc.blogRepository.GetBlogById(blogId)
+		if err != nil || blog.AuthorId != user.ID {
+			continue
+		}
+		public.PinnedPosts = append(public.PinnedPosts, domain.PinnedPost{BlogId: blog.BlogId, Title: blog.Title, Date: blog.Date, Tags: blog.Tags})
+	}
+	return public, nil
+}
+
+func checkLength(field, value string, max int) error {
+	if utf8.RuneCountInString(value) > max {
+		return fmt.Errorf("the %s is longer than %d characters", field, max)
+	}
+	return nil
+}
+
+// checkURL accepts empty values and absolute http or https URLs.
+func checkURL(field, value string) error {
+	if value == "" {
+		return nil
+	}
+	if len(value) > maxURLLength {
+		return fmt.Errorf("the %s link is longer than %d characters", field, maxURLLength)
+	}
+	link, err := url.Parse(value)
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		return fmt.Errorf("the %s link must be an http or https URL", field)
+	}
+	return nil
+}
+
+func knownSocialNetwork(network string) bool {
+	for _, known := range domain.SocialNetworks {
+		if network == known {
+			return true
+		}
+	}
+	return false
+}
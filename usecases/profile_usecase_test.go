@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+func TestUpdateProfile(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	profiles := mocks.NewProfileRepository(t)
+	blogs := mocks.NewBlogRepository(t)
+	uc := NewProfileUsecase(profiles, mocks.NewUserRepository(t), blogs)
+	uc.now = func() time.Time { return now }
+	blogs.On("GetBlogById", "b1").Return(domain.Blog{BlogId: "b1", AuthorId: "u1"}, nil).Maybe()
+	blogs.On("GetBlogById", "b2").Return(domain.Blog{BlogId: "b2", AuthorId: "u2"}, nil).Maybe()
+	blogs.On("GetBlogById", "gone").Return(domain.Blog{}, errors.New("no documents")).Maybe()
+
+	for _, invalid := range []domain.Profile{
+		{DisplayName: strings.Repeat("a", 51)},
+		{Bio: strings.Repeat("é", 501)},
+		{Location: strings.Repeat("a", 101)},
+		{AvatarURL: "javascript:alert(1)"},
+		{Website: "example.com"},
+		{Website: "https://example.com/" + strings.Repeat("a", 200)},
+		{SocialLinks: map[string]string{"myspace": "https://myspace.com/u1"}},
+		{SocialLinks: map[string]string{"github": "ftp://github.com/u1"}},
+		{PinnedPosts: []string{"b1", "b1"}},
+		{PinnedPosts: []string{"b2"}},
+		{PinnedPosts: []string{"gone"}},
+		{PinnedPosts: []string{"b1", "b1", "b1", "b1"}},
+	} {
+		_, err := uc.UpdateProfile("u1", invalid)
+		assert.Error(t, err, "%+v", invalid)
+	}
+
+	profiles.On("SaveProfile", mock.Anything).Return(nil).Once()
+	profile, err := uc.UpdateProfile("u1", domain.Profile{
+		UserId:      "someone-else",
+		DisplayName: "  Ada ",
+		Bio:         strings.Repeat("é", 500),
+		Website:     "https://ada.dev",
+		SocialLinks: map[string]string{"github": "https://github.com/ada", "x": ""},
+		PinnedPosts: []string{"b1"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", profile.UserId)
+	assert.Equal(t, "Ada", profile.DisplayName)
+	assert.Equal(t, map[string]string{"github": "https://github.com/ada"}, profile.SocialLinks)
+	assert.Equal(t, now, profile.UpdatedAt)
+	profiles.AssertCalled(t, "SaveProfile", profile)
+}
+
+func TestGetPublicProfile(t *testing.T) {
+	profiles := mocks.NewProfileRepository(t)
+	users := mocks.NewUserRepository(t)
+	blogs := mocks.NewBlogRepository(t)
+	uc := NewProfileUsecase(profiles, users, blogs)
+	date := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Username: "ada"}}).Return([]domain.User{{
+		ID: "u1", Username: "ada", FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com",
+		Password: "hash", VerifyToken: "verify", RefreshToken: "refresh", IsActive: true,
+	}}, nil)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Username: "pending"}}).Return([]domain.User{{ID: "u2", Username: "pending"}}, nil)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Username: "nobody"}}).Return([]domain.User{{}}, errors.New("there is no user with the given username"))
+	profiles.On("GetProfile", "u1").Return(domain.Profile{UserId: "u1", Bio: "Poet of science", PinnedPosts: []string{"b1", "deleted"}}, nil)
+	blogs.On("GetBlogById", "b1").Return(domain.Blog{BlogId: "b1", AuthorId: "u1", Title: "Notes", Date: date, Content: "..."}, nil)
+	blogs.On("GetBlogById", "deleted").Return(domain.Blog{}, errors.New("no documents"))
+
+	profile, err := uc.GetPublicProfile("ada")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.PublicProfile{
+		Username:    "ada",
+		DisplayName: "Ada Lovelace",
+		Bio:         "Poet of science",
+		PinnedPosts: []domain.PinnedPost{{BlogId: "b1", Title: "Notes", Date: date}},
+	}, profile)
+	body, _ := json.Marshal(profile)
+	for _, secret := range []string{"hash", "verify", "refresh", "ada@example.com"} {
+		assert.NotContains(t, string(body), secret)
+	}
+
+	_, err = uc.GetPublicProfile("pending")
+	assert.Error(t, err, "accounts not activated have no profile")
+	_, err = uc.GetPublicProfile("nobody")
+	assert.Error(t, err)
+}
+
+func TestProfileAvatar(t *testing.T) {
+	profiles := mocks.NewProfileRepository(t)
+	uc := NewProfileUsecase(profiles, mocks.NewUserRepository(t), mocks.NewBlogRepository(t))
+	_, err := uc.UpdateProfile("u1", domain.Profile{AvatarMediaId: "m1"})
+	assert.Error(t, err, "uploads are not enabled")
+
+	repo := mocks.NewMediaRepository(t)
+	stored := storedMedia(repo)
+	storage, err := infrastructure.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	media := NewMediaUsecase(repo, storage, MediaSettings{BaseURL: "https://blogmate.example"})
+	uc.SetMedia(media)
+	avatar, err := media.Upload("u1", "me.png", pngImage(t, 400, 400))
+	require.NoError(t, err)
+	document, err := media.Upload("u1", "cv.pdf", []byte("%PDF-1.7\n..."))
+	require.NoError(t, err)
+	theirs, err := media.Upload("u2", "them.png", pngImage(t, 400, 400))
+	require.NoError(t, err)
+
+	for _, invalid := range []string{document.MediaId, theirs.MediaId, "missing"} {
+		_, err = uc.UpdateProfile("u1", domain.Profile{AvatarMediaId: invalid})
+		assert.Error(t, err, invalid)
+	}
+	profiles.On("SaveProfile", mock.Anything).Return(nil)
+	profile, err := uc.UpdateProfile("u1", domain.Profile{AvatarMediaId: avatar.MediaId, AvatarURL: "https://elsewhere.example/me.png"})
+	require.NoError(t, err)
+	assert.Equal(t, avatar.ThumbnailURL, profile.AvatarURL)
+	assert.Equal(t, []string{"profile:u1"}, stored[avatar.MediaId].References)
+
+	_, err = uc.UpdateProfile("u1", domain.Profile{})
+	require.NoError(t, err)
+	assert.Empty(t, stored[avatar.MediaId].References, "replaced avatars can be cleaned up")
+}
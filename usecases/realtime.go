@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"strings"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+)
+
+// SetBroker makes the usecase publish blog and comment changes so that
+// connected clients see them without re-fetching.
+func (uc *BlogUsecase) SetBroker(broker infrastructure.Broker) {
+	uc.broker = broker
+}
+
+// SetBroker pushes new notifications to the user's channel.
+func (uc *NotificationUsecase) SetBroker(broker infrastructure.Broker) {
+	uc.broker = broker
+}
+
+func (uc *BlogUsecase) publish(eventType string, data interface{}, topics ...string) {
+	if uc.broker == nil {
+		return
+	}
+	for _, topic := range topics {
+		uc.broker.Publish(infrastructure.Event{Topic: topic, Type: eventType, Data: data})
+	}
+}
+
+// commentTopics returns the blog topic and the thread topics of every
+// ancestor of the comment with the given path.
+func commentTopics(blogId, path string) []string {
+	topics := []string{infrastructure.BlogTopic(blogId)}
+	ids := strings.Split(strings.Trim(path, "/"), "/")
+	for _, id := range ids[:len(ids)-1] {
+		if id != "" {
+			topics = append(topics, infrastructure.ThreadTopic(id))
+		}
+	}
+	return topics
+}
+
+type deletedRef struct {
+	BlogId    string `json:"blog_id"`
+	CommentId string `json:"comment_id,omitempty"`
+}
+
+type reactionCounts struct {
+	BlogId    string `json:"blog_id"`
+	CommentId string `json:"comment_id,omitempty"`
+	Likes     int    `json:"likes"`
+	Dislikes  int    `json:"dislikes"`
+	Views     int    `json:"views"`
+}
+
+func (uc *BlogUsecase) publishBlogReaction(blogId string) {
+	if uc.broker == nil {
+		return
+	}
+	go func() {
+		blog, err := uc.blogRepository.GetBlogById(blogId)
+		if err != nil {
+			return
+		}
+		uc.publish("blog.reaction", reactionCounts{BlogId: blogId, Likes: len(blog.Likes), Dislikes: len(blog.Dislikes), Views: blog.Views}, infrastructure.BlogTopic(blogId))
+	}()
+}
+
+func (uc *BlogUsecase) publishCommentReaction(blogId, commentId string) {
+	if uc.broker == nil {
+		return
+	}
+	go func() {
+		comment, err := uc.blogRepository.GetCommentById(blogId, commentId)
+		if err != nil {
+			return
+		}
+		counts := reactionCounts{BlogId: blogId, CommentId: commentId, Likes: len(comment.Likes), Dislikes: len(comment.Dislikes), Views: comment.Views}
+		uc.publish("comment.reaction", counts, commentTopics(blogId, comment.Path)...)
+	}()
+}
+
+func (uc *BlogUsecase) publishComment(eventType, blogId string, comment domain.Comment) {
+	path := comment.Path
+	if path == "" {
+		path = "/" + comment.CommentId + "/"
+	}
+	uc.publish(eventType, comment, commentTopics(blogId, path)...)
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+)
+
+const (
+	relatedCacheTTL = 10 * time.Minute
+	tagWeight       = 0.4
+	textWeight      = 0.6
+)
+
+// relatedCache keeps the TF-IDF index of every blog and the rankings computed
+// from it. It is rebuilt lazily after the TTL expires or a blog changes.
+type relatedCache struct {
+	mu      sync.Mutex
+	builtAt time.Time
+	blogs   map[string]domain.Blog
+	index   *infrastructure.TFIDFIndex
+	results map[string][]domain.RelatedBlog
+}
+
+func newRelatedCache() *relatedCache {
+	return &relatedCache{}
+}
+
+func (c *relatedCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.builtAt = time.Time{}
+}
+
+func (c *relatedCache) rebuild(blogs []domain.Blog) {
+	c.blogs = make(map[string]domain.Blog, len(blogs))
+	docs := make(map[string][]string, len(blogs))
+	for _, b := range blogs {
+		c.blogs[b.BlogId] = b
+		// the title is repeated so that it weighs more than the body
+		text := strings.Join([]string{b.Title, b.Title, b.Content}, " ")
+		docs[b.BlogId] = infrastructure.Tokenize(text)
+	}
+	c.index = infrastructure.NewTFIDFIndex(docs)
+	c.results = map[string][]domain.RelatedBlog{}
+	c.builtAt = time.Now()
+}
+
+func (c *relatedCache) rank(blogId string) ([]domain.RelatedBlog, error) {
+	if cached, ok := c.results[blogId]; ok {
+		return cached, nil
+	}
+	target, ok := c.blogs[blogId]
+	if !ok {
+		return nil, errors.New("blog not found")
+	}
+	related := []domain.RelatedBlog{}
+	for id, b := range c.blogs {
+		if id == blogId {
+			continue
+		}
+		score := tagWeight*infrastructure.TagOverlap(target.Tags, b.Tags) + textWeight*c.index.Similarity(blogId, id)
+		if score <= 0 {
+			continue
+		}
+		related = append(related, domain.RelatedBlog{Blog: b, Score: score})
+	}
+	sort.Slice(related, func(i, j int) bool {
+		if related[i].Score == related[j].Score {
+			return related[i].Blog.Date.After(related[j].Blog.Date)
+		}
+		return related[i].Score > related[j].Score
+	})
+	c.results[blogId] = related
+	return related, nil
+}
+
+// GetRelatedBlogs ranks the existing blogs by how similar they are to the given
+// one, using tag overlap and TF-IDF similarity of title and content.
+func (uc *BlogUsecase) GetRelatedBlogs(blogId string, limit int) ([]domain.RelatedBlog, error) {
+	uc.related.mu.Lock()
+	defer uc.related.mu.Unlock()
+	if time.Since(uc.related.builtAt) > relatedCacheTTL {
+		blogs, err := uc.blogRepository.GetBlog(domain.BlogFilterOption{})
+		if err != nil {
+			return []domain.RelatedBlog{}, err
+		}
+		uc.related.rebuild(blogs)
+	}
+	related, err := uc.related.rank(blogId)
+	if err != nil {
+		return []domain.RelatedBlog{}, err
+	}
+	if limit > 0 && len(related) > limit {
+		related = related[:limit]
+	}
+	return related, nil
+}
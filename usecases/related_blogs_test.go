@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+func TestGetRelatedBlogs(t *testing.T) {
+	blogs := []domain.Blog{
+		{BlogId: "1", Title: "Getting started with Go", Content: "Go routines and channels make concurrency simple", Tags: []string{"go", "concurrency"}},
+		{BlogId: "2", Title: "Concurrency patterns in Go", Content: "Channels, worker pools and routines in Go", Tags: []string{"go", "concurrency"}},
+		{BlogId: "3", Title: "Baking sourdough bread", Content: "Flour, water and patience", Tags: []string{"cooking"}},
+		{BlogId: "4", Title: "Go modules", Content: "Managing dependencies with go modules", Tags: []string{"go"}},
+	}
+	repo := mocks.NewBlogRepository(t)
+	repo.On("GetBlog", mock.Anything).Return(blogs, nil).Once()
+	uc := NewBlogUsecase(repo)
+
+	related, err := uc.GetRelatedBlogs("1", 5)
+	assert.NoError(t, err)
+	assert.Len(t, related, 2)
+	assert.Equal(t, "2", related[0].Blog.BlogId)
+	assert.Equal(t, "4", related[1].Blog.BlogId)
+
+	// the second call is served from the cache
+	related, err = uc.GetRelatedBlogs("1", 1)
+	assert.NoError(t, err)
+	assert.Len(t, related, 1)
+
+	_, err = uc.GetRelatedBlogs("missing", 5)
+	assert.Error(t, err)
+}
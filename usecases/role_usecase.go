@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"errors"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/yesetoda/BlogMate/domain"
+)
+
+// roleCacheTTL is how long custom roles are cached before permission checks
+// read them again. Changes made here apply at once.
+const roleCacheTTL = 30 * time.Second
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{1,31}$`)
+
+// RoleUsecase manages custom roles and the roles of users, and resolves the
+// permissions roles grant.
+type RoleUsecase struct {
+	roleRepository domain.RoleRepository
+	userRepository domain.UserRepository
+	revoker        domain.TokenRevoker
+	now            func() time.Time
+
+	mu        sync.Mutex
+	custom    map[string]domain.Role
+	fetchedAt time.Time
+}
+
+func NewRoleUsecase(roles domain.RoleRepository, users domain.UserRepository) *RoleUsecase {
+	return &RoleUsecase{roleRepository: roles, userRepository: users, now: time.Now}
+}
+
+// SetTokenRevoker revokes the access tokens of users whose roles change, so
+// they log in again with their new roles.
+func (uc *RoleUsecase) SetTokenRevoker(revoker domain.TokenRevoker) {
+	uc.revoker = revoker
+}
+
+// GetRoles lists the built-in roles followed by the custom ones.
+func (uc *RoleUsecase) GetRoles() ([]domain.Role, error) {
+	roles := []domain.Role{}
+	for _, builtIn := range domain.BuiltInRoles {
+		role, _ := domain.BuiltInRole(builtIn.Name)
+		roles = append(roles, role)
+	}
+	custom, err := uc.roleRepository.GetRoles()
+	if err != nil {
+		return nil, err
+	}
+	return append(roles, custom...), nil
+}
+
+// SaveRole creates or replaces a custom role.
+func (uc *RoleUsecase) SaveRole(role domain.Role) (domain.Role, error) {
+	if !roleNamePattern.MatchString(role.Name) {
+		return domain.Role{}, errors.New("role names are 2 to 32 lowercase letters, digits or dashes")
+	}
+	if _, ok := domain.BuiltInRole(role.Name); ok {
+		return domain.Role{}, errors.New("built-in roles cannot be changed")
+	}
+	permissions := []string{}
+	for _, permission := range role.Permissions {
+		if !knownPermission(permission) {
+			return domain.Role{}, errors.New("unknown permission " + permission)
+		}
+		if !contains(permissions, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+	if len(permissions) == 0 {
+		return domain.Role{}, errors.New("give the role at least one permission")
+	}
+	role.Permissions = permissions
+	role.BuiltIn = false
+	if err := uc.roleRepository.SaveRole(role); err != nil {
+		return domain.Role{}, err
+	}
+	uc.forgetRoles()
+	return role, nil
+}
+
+// DeleteRole removes a custom role. Users keep its name, which then grants
+// nothing.
+func (uc *RoleUsecase) DeleteRole(name string) error {
+	if _, ok := domain.BuiltInRole(name); ok {
+		return errors.New("built-in roles cannot be deleted")
+	}
+	deleted, err := uc.roleRepository.DeleteRole(name)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("role not found")
+	}
+	uc.forgetRoles()
+	return nil
+}
+
+// AssignRoles replaces the roles of a user. Admins cannot change their own
+// roles, so they do not lock themselves out.
+func (uc *RoleUsecase) AssignRoles(adminId, userId string, roles []string) (domain.User, error) {
+	if adminId == userId {
+		return domain.User{}, errors.New("you cannot change your own roles")
+	}
+	custom, err := uc.customRoles()
+	if err != nil {
+		return domain.User{}, err
+	}
+	assigned := []string{}
+	for _, name := range roles {
+		_, builtIn := domain.BuiltInRole(name)
+		if _, ok := custom[name]; !ok && !builtIn {
+			return domain.User{}, errors.New("unknown role " + name)
+		}
+		if !contains(assigned, name) {
+			assigned = append(assigned, name)
+		}
+	}
+	user, err := uc.userRepository.SetRoles(userId, assigned)
+	if err != nil {
+		return domain.User{}, errors.New("user not found")
+	}
+	if uc.revoker != nil {
+		if err := uc.revoker.RevokeUserTokens(userId); err != nil {
+			return user, err
+		}
+	}
+	return user, nil
+}
+
+// Permissions returns the permissions the roles grant together.
+func (uc *RoleUsecase) Permissions(roles []string) ([]string, error) {
+	permissions := []string{}
+	for _, name := range roles {
+		role, ok := domain.BuiltInRole(name)
+		if !ok {
+			custom, err := uc.customRoles()
+			if err != nil {
+				return nil, err
+			}
+			role = custom[name]
+		}
+		for _, permission := range role.Permissions {
+			if !contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions, nil
+}
+
+func (uc *RoleUsecase) customRoles() (map[string]domain.Role, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.custom != nil && uc.now().Sub(uc.fetchedAt) < roleCacheTTL {
+		return uc.custom, nil
+	}
+	roles, err := uc.roleRepository.GetRoles()
+	if err != nil {
+		return nil, err
+	}
+	custom := map[string]domain.Role{}
+	for _, role := range roles {
+		custom[role.Name] = role
+	}
+	uc.custom = custom
+	uc.fetchedAt = uc.now()
+	return custom, nil
+}
+
+func (uc *RoleUsecase) forgetRoles() {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.custom = nil
+}
+
+func knownPermission(permission string) bool {
+	return contains(domain.Permissions, permission)
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+func TestSaveRole(t *testing.T) {
+	roles := mocks.NewRoleRepository(t)
+	uc := NewRoleUsecase(roles, mocks.NewUserRepository(t))
+
+	_, err := uc.SaveRole(domain.Role{Name: "Bad Name", Permissions: []string{domain.PermComment}})
+	assert.Error(t, err)
+	_, err = uc.SaveRole(domain.Role{Name: domain.RoleEditor, Permissions: []string{domain.PermComment}})
+	assert.Error(t, err, "built-in roles cannot be changed")
+	_, err = uc.SaveRole(domain.Role{Name: "translator", Permissions: []string{"blogs:everything"}})
+	assert.Error(t, err)
+	_, err = uc.SaveRole(domain.Role{Name: "translator"})
+	assert.Error(t, err)
+
+	roles.On("SaveRole", domain.Role{Name: "translator", Permissions: []string{domain.PermEditAnyBlog}}).Return(nil)
+	role, err := uc.SaveRole(domain.Role{Name: "translator", Permissions: []string{domain.PermEditAnyBlog, domain.PermEditAnyBlog}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{domain.PermEditAnyBlog}, role.Permissions)
+}
+
+func TestAssignRoles(t *testing.T) {
+	roles := mocks.NewRoleRepository(t)
+	users := mocks.NewUserRepository(t)
+	revoker := mocks.NewTokenRevoker(t)
+	uc := NewRoleUsecase(roles, users)
+	uc.SetTokenRevoker(revoker)
+
+	_, err := uc.AssignRoles("admin1", "admin1", []string{domain.RoleReader})
+	assert.Error(t, err, "admins cannot change their own roles")
+
+	roles.On("GetRoles").Return([]domain.Role{{Name: "translator", Permissions: []string{domain.PermEditAnyBlog}}}, nil)
+	_, err = uc.AssignRoles("admin1", "u1", []string{"superuser"})
+	assert.Error(t, err)
+
+	users.On("SetRoles", "u1", []string{domain.RoleModerator, "translator"}).Return(domain.User{ID: "u1", Roles: []string{domain.RoleModerator, "translator"}}, nil)
+	revoker.On("RevokeUserTokens", "u1").Return(nil)
+	user, err := uc.AssignRoles("admin1", "u1", []string{domain.RoleModerator, "translator", domain.RoleModerator})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{domain.RoleModerator, "translator"}, user.Roles)
+
+	users.On("SetRoles", "missing", mock.Anything).Return(domain.User{}, errors.New("no documents"))
+	_, err = uc.AssignRoles("admin1", "missing", nil)
+	assert.EqualError(t, err, "user not found")
+}
+
+func TestRolePermissions(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	roles := mocks.NewRoleRepository(t)
+	uc := NewRoleUsecase(roles, mocks.NewUserRepository(t))
+	uc.now = func() time.Time { return now }
+
+	permissions, err := uc.Permissions([]string{domain.RoleReader})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{domain.PermComment}, permissions, "built-in roles do not read the custom ones")
+
+	roles.On("GetRoles").Return([]domain.Role{{Name: "translator", Permissions: []string{domain.PermComment, domain.PermEditAnyBlog}}}, nil).Once()
+	permissions, err = uc.Permissions([]string{domain.RoleReader, "translator", "deleted"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{domain.PermComment, domain.PermEditAnyBlog}, permissions)
+
+	now = now.Add(roleCacheTTL / 2)
+	_, err = uc.Permissions([]string{"translator"})
+	assert.NoError(t, err, "custom roles are cached")
+
+	roles.On("GetRoles").Return([]domain.Role{}, nil).Once()
+	now = now.Add(roleCacheTTL)
+	permissions, err = uc.Permissions([]string{"translator"})
+	assert.NoError(t, err)
+	assert.Empty(t, permissions)
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+)
+
+var ErrSemanticSearchDisabled = errors.New("semantic search is not configured")
+
+// SetEmbeddings enables semantic search. Blogs are embedded when they are
+// created or updated and the vectors are kept in the given store.
+func (uc *BlogUsecase) SetEmbeddings(embedder infrastructure.Embedder, store infrastructure.VectorStore) {
+	uc.embedder = embedder
+	uc.vectors = store
+}
+
+func blogEmbeddingText(b domain.Blog) string {
+	return strings.Join([]string{b.Title, b.Content, strings.Join(b.Tags, " ")}, "\n")
+}
+
+func (uc *BlogUsecase) indexBlog(blog domain.Blog) {
+	if uc.embedder == nil || uc.vectors == nil {
+		return
+	}
+	vector, err := uc.embedder.Embed(blogEmbeddingText(blog))
+	if err != nil {
+		log.Println("embedding blog", blog.BlogId, "failed:", err)
+		return
+	}
+	if err := uc.vectors.Upsert(blog.BlogId, vector); err != nil {
+		log.Println("storing embedding for blog", blog.BlogId, "failed:", err)
+	}
+}
+
+func (uc *BlogUsecase) unindexBlog(blogId string) {
+	if uc.vectors == nil {
+		return
+	}
+	if err := uc.vectors.Delete(blogId); err != nil {
+		log.Println("removing embedding for blog", blogId, "failed:", err)
+	}
+}
+
+// IndexAllBlogs embeds every stored blog. It is used to fill a fresh vector
+// store on startup.
+func (uc *BlogUsecase) IndexAllBlogs() error {
+	if uc.embedder == nil || uc.vectors == nil {
+		return ErrSemanticSearchDisabled
+	}
+	blogs, err := uc.blogRepository.GetBlog(domain.BlogFilterOption{})
+	if err != nil {
+		return err
+	}
+	for _, b := range blogs {
+		uc.indexBlog(b)
+	}
+	return nil
+}
+
+func (uc *BlogUsecase) matchesToBlogs(matches []infrastructure.VectorMatch) ([]domain.RelatedBlog, error) {
+	results := []domain.RelatedBlog{}
+	for _, m := range matches {
+		blog, err := uc.blogRepository.GetBlogById(m.ID)
+		if err != nil {
+			// the blog was removed after it was indexed
+			uc.unindexBlog(m.ID)
+			continue
+		}
+		results = append(results, domain.RelatedBlog{Blog: blog, Score: m.Score})
+	}
+	return results, nil
+}
+
+// SemanticSearch returns the blogs whose embeddings are closest to the query.
+func (uc *BlogUsecase) SemanticSearch(query string, limit int) ([]domain.RelatedBlog, error) {
+	if uc.embedder == nil || uc.vectors == nil {
+		return []domain.RelatedBlog{}, ErrSemanticSearchDisabled
+	}
+	if strings.TrimSpace(query) == "" {
+		return []domain.RelatedBlog{}, errors.New("query is required")
+	}
+	vector, err := uc.embedder.Embed(query)
+	if err != nil {
+		return []domain.RelatedBlog{}, err
+	}
+	matches, err := uc.vectors.Search(vector, limit)
+	if err != nil {
+		return []domain.RelatedBlog{}, err
+	}
+	return uc.matchesToBlogs(matches)
+}
+
+// MoreLikeThis returns the blogs whose embeddings are closest to the given blog.
+func (uc *BlogUsecase) MoreLikeThis(blogId string, limit int) ([]domain.RelatedBlog, error) {
+	if uc.embedder == nil || uc.vectors == nil {
+		return []domain.RelatedBlog{}, ErrSemanticSearchDisabled
+	}
+	vector, ok := uc.vectors.Get(blogId)
+	if !ok {
+		blog, err := uc.blogRepository.GetBlogById(blogId)
+		if err != nil {
+			return []domain.RelatedBlog{}, err
+		}
+		uc.indexBlog(blog)
+		if vector, ok = uc.vectors.Get(blogId); !ok {
+			return []domain.RelatedBlog{}, errors.New("could not embed the blog")
+		}
+	}
+	matches, err := uc.vectors.Search(vector, limit, blogId)
+	if err != nil {
+		return []domain.RelatedBlog{}, err
+	}
+	return uc.matchesToBlogs(matches)
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+// semanticBlogs are indexed by the tests, two about Go and one about baking.
+var semanticBlogs = []domain.Blog{
+	{BlogId: "go-generics", Title: "Go generics", Content: "Type parameters make generic functions in Go programs", Tags: []string{"go", "generics"}},
+	{BlogId: "go-interfaces", Title: "Go interfaces", Content: "Interfaces and type parameters in Go programs", Tags: []string{"go"}},
+	{BlogId: "sourdough", Title: "Sourdough bread", Content: "Flour water salt and a starter make a crusty loaf", Tags: []string{"baking"}},
+}
+
+func newSemanticSearchTest(t *testing.T) (*BlogUsecase, *mocks.BlogRepository, *infrastructure.MemoryVectorStore) {
+	blogs := mocks.NewBlogRepository(t)
+	for _, b := range semanticBlogs {
+		blogs.On("GetBlogById", b.BlogId).Return(b, nil).Maybe()
+	}
+	store := infrastructure.NewMemoryVectorStore()
+	uc := NewBlogUsecase(blogs)
+	uc.SetEmbeddings(infrastructure.NewHashEmbedder(256), store)
+	return uc, blogs, store
+}
+
+func resultIds(results []domain.RelatedBlog) []string {
+	ids := []string{}
+	for _, r := range results {
+		ids = append(ids, r.Blog.BlogId)
+	}
+	return ids
+}
+
+func TestSemanticSearch(t *testing.T) {
+	uc, blogs, _ := newSemanticSearchTest(t)
+	blogs.On("GetBlog", domain.BlogFilterOption{}).Return(semanticBlogs, nil).Once()
+	require.NoError(t, uc.IndexAllBlogs())
+
+	results, err := uc.SemanticSearch("sourdough starter loaf", 3)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, "sourdough", results[0].Blog.BlogId)
+	for i := 1; i < len(results); i++ {
+		assert.GreaterOrEqual(t, results[i-1].Score, results[i].Score, "results are ranked by score")
+	}
+
+	results, err = uc.SemanticSearch("generic functions with type parameters in Go", 2)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"go-generics", "go-interfaces"}, resultIds(results))
+	assert.Equal(t, "go-generics", results[0].Blog.BlogId)
+
+	_, err = uc.SemanticSearch("  ", 3)
+	assert.Error(t, err)
+}
+
+func TestMoreLikeThis(t *testing.T) {
+	uc, blogs, store := newSemanticSearchTest(t)
+	blogs.On("GetBlog", domain.BlogFilterOption{}).Return(semanticBlogs, nil).Once()
+	require.NoError(t, uc.IndexAllBlogs())
+
+	results, err := uc.MoreLikeThis("go-generics", 2)
+	require.NoError(t, err)
+	assert.NotContains(t, resultIds(results), "go-generics", "the blog is not related to itself")
+	require.NotEmpty(t, results)
+	assert.Equal(t, "go-interfaces", results[0].Blog.BlogId)
+
+	// a blog missing from the store is embedded on the way
+	require.NoError(t, store.Delete("sourdough"))
+	results, err = uc.MoreLikeThis("sourdough", 2)
+	require.NoError(t, err)
+	assert.NotContains(t, resultIds(results), "sourdough")
+	_, indexed := store.Get("sourdough")
+	assert.True(t, indexed)
+
+	// blogs deleted after they were indexed are dropped from the results
+	blogs.On("GetBlogById", "gone").Return(domain.Blog{}, assert.AnError).Once()
+	vector, _ := store.Get("go-generics")
+	require.NoError(t, store.Upsert("gone", vector))
+	results, err = uc.MoreLikeThis("go-interfaces", 3)
+	require.NoError(t, err)
+	assert.NotContains(t, resultIds(results), "gone")
+	_, indexed = store.Get("gone")
+	assert.False(t, indexed)
+}
+
+func TestSemanticSearchDisabled(t *testing.T) {
+	uc := NewBlogUsecase(mocks.NewBlogRepository(t))
+
+	results, err := uc.SemanticSearch("go generics", 3)
+	assert.Equal(t, ErrSemanticSearchDisabled, err)
+	assert.Empty(t, results)
+	results, err = uc.MoreLikeThis("go-generics", 3)
+	assert.Equal(t, ErrSemanticSearchDisabled, err)
+	assert.Empty(t, results)
+	assert.Equal(t, ErrSemanticSearchDisabled, uc.IndexAllBlogs())
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+)
+
+// RefreshTokenTTL is how long a session stays valid without being refreshed.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// Reasons recorded on revoked sessions.
+const (
+	SessionLoggedOut     = "logout"
+	SessionRevoked       = "revoked"
+	SessionReuseDetected = "refresh token reuse"
+	SessionEmailReverted = "email change reverted"
+)
+
+var errSessionEnded = errors.New("session expired or revoked, please log in again")
+
+// SessionUsecase issues the tokens of a login and rotates its refresh token.
+type SessionUsecase struct {
+	sessionRepository domain.SessionRepository
+	userRepository    domain.UserRepository
+	now               func() time.Time
+}
+
+func NewSessionUsecase(sessions domain.SessionRepository, users domain.UserRepository) *SessionUsecase {
+	return &SessionUsecase{sessionRepository: sessions, userRepository: users, now: time.Now}
+}
+
+// StartSession opens a session for an authenticated user on a device.
+// twoFactor tells that the login passed a second factor, the tokens of the
+// session say so until it ends.
+func (uc *SessionUsecase) StartSession(user domain.User, device, ip string, twoFactor bool) (domain.TokenPair, error) {
+	refreshToken, hash, err := infrastructure.NewRefreshToken()
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	now := uc.now()
+	session, err := uc.sessionRepository.CreateSession(domain.Session{
+		UserId:    user.ID,
+		TokenHash: hash,
+		Device:    device,
+		IP:        ip,
+		CreatedAt: now,
+		LastSeen:  now,
+		ExpiresAt: now.Add(RefreshTokenTTL),
+		TwoFactor: twoFactor,
+	})
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	return uc.tokens(user, session, refreshToken)
+}
+
+// Refresh exchanges a refresh token for new tokens. Each refresh token works
+// once, using one that was already exchanged revokes its session because the
+// token was copied.
+func (uc *SessionUsecase) Refresh(refreshToken, device, ip string) (domain.TokenPair, error) {
+	if refreshToken == "" {
+		return domain.TokenPair{}, errors.New("refresh token is required")
+	}
+	hash := infrastructure.HashRefreshToken(refreshToken)
+	session, err := uc.sessionRepository.FindSession(hash)
+	if err != nil {
+		return domain.TokenPair{}, errSessionEnded
+	}
+	now := uc.now()
+	if !session.Active(now) {
+		return domain.TokenPair{}, errSessionEnded
+	}
+	if session.TokenHash != hash {
+		uc.revokeReused(session)
+		return domain.TokenPair{}, errSessionEnded
+	}
+
+	users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{UserId: session.UserId}})
+	if err != nil || len(users) == 0 || !users[0].IsActive {
+		return domain.TokenPair{}, errSessionEnded
+	}
+	newToken, newHash, err := infrastructure.NewRefreshToken()
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	rotated, err := uc.sessionRepository.RotateSession(session.SessionId, hash, newHash, device, ip, now)
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	if !rotated {
+		// another request exchanged the same token first
+		uc.revokeReused(session)
+		return domain.TokenPair{}, errSessionEnded
+	}
+	return uc.tokens(users[0], session, newToken)
+}
+
+func (uc *SessionUsecase) revokeReused(session domain.Session) {
+	log.Println("refresh token reuse detected for session", session.SessionId, "of user", session.UserId)
+	if _, err := uc.sessionRepository.RevokeSession(session.UserId, session.SessionId, SessionReuseDetected, uc.now()); err != nil {
+		log.Println("revoking session", session.SessionId, "failed:", err)
+	}
+}
+
+func (uc *SessionUsecase) tokens(user domain.User, session domain.Session, refreshToken string) (domain.TokenPair, error) {
+	accessToken, err := infrastructure.GenerateAccessToken(&user, session.SessionId, session.TwoFactor)
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	return domain.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(infrastructure.AccessTokenTTL / time.Second),
+		SessionId:    session.SessionId,
+	}, nil
+}
+
+// GetSessions lists the active sessions of the user, marking the current one.
+func (uc *SessionUsecase) GetSessions(userId, currentSessionId string) ([]domain.Session, error) {
+	sessions, err := uc.sessionRepository.GetSessions(userId)
+	if err != nil {
+		return []domain.Session{}, err
+	}
+	now := uc.now()
+	active := []domain.Session{}
+	for _, session := range sessions {
+		if session.Active(now) {
+			session.Current = session.SessionId == currentSessionId
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+// Revoke ends one session of the user, e.g. a lost device.
+func (uc *SessionUsecase) Revoke(userId, sessionId, reason string) error {
+	revoked, err := uc.sessionRepository.RevokeSession(userId, sessionId, reason, uc.now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return errors.New("session not found")
+	}
+	return nil
+}
+
+// RevokeAll ends every session of the user and returns how many there were.
+func (uc *SessionUsecase) RevokeAll(userId, reason string) (int64, error) {
+	return uc.sessionRepository.RevokeUserSessions(userId, reason, uc.now())
+}
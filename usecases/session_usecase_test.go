@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+func TestSessionRefreshRotation(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	sessions := mocks.NewSessionRepository(t)
+	users := mocks.NewUserRepository(t)
+	uc := NewSessionUsecase(sessions, users)
+	uc.now = func() time.Time { return now }
+	user := domain.User{ID: "u1", Email: "u1@example.com", IsActive: true}
+
+	var stored domain.Session
+	sessions.On("CreateSession", mock.Anything).Return(func(s domain.Session) (domain.Session, error) {
+		s.SessionId = "s1"
+		stored = s
+		return s, nil
+	}).Once()
+	first, err := uc.StartSession(user, "curl/8", "10.0.0.1", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "s1", first.SessionId)
+	assert.NotEmpty(t, first.AccessToken)
+	assert.Equal(t, infrastructure.HashRefreshToken(first.RefreshToken), stored.TokenHash)
+	assert.Equal(t, now.Add(RefreshTokenTTL), stored.ExpiresAt)
+
+	firstHash := infrastructure.HashRefreshToken(first.RefreshToken)
+	sessions.On("FindSession", firstHash).Return(stored, nil).Once()
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{UserId: "u1"}}).Return([]domain.User{user}, nil)
+	sessions.On("RotateSession", "s1", firstHash, mock.Anything, "curl/8", "10.0.0.2", now).Return(true, nil).Once()
+	second, err := uc.Refresh(first.RefreshToken, "curl/8", "10.0.0.2")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	// the first token was replaced, presenting it again revokes the session
+	rotated := stored
+	rotated.TokenHash = infrastructure.HashRefreshToken(second.RefreshToken)
+	rotated.UsedHashes = []string{firstHash}
+	sessions.On("FindSession", firstHash).Return(rotated, nil).Once()
+	sessions.On("RevokeSession", "u1", "s1", SessionReuseDetected, now).Return(true, nil).Once()
+	_, err = uc.Refresh(first.RefreshToken, "evil", "10.6.6.6")
+	assert.Error(t, err)
+}
+
+func TestSessionRefreshRejectsEndedSessions(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	sessions := mocks.NewSessionRepository(t)
+	uc := NewSessionUsecase(sessions, mocks.NewUserRepository(t))
+	uc.now = func() time.Time { return now }
+
+	hash := infrastructure.HashRefreshToken("expired")
+	sessions.On("FindSession", hash).Return(domain.Session{SessionId: "s1", TokenHash: hash, ExpiresAt: now.Add(-time.Minute)}, nil)
+	_, err := uc.Refresh("expired", "", "")
+	assert.Error(t, err)
+
+	hash = infrastructure.HashRefreshToken("revoked")
+	sessions.On("FindSession", hash).Return(domain.Session{SessionId: "s2", TokenHash: hash, ExpiresAt: now.Add(time.Hour), RevokedAt: now}, nil)
+	_, err = uc.Refresh("revoked", "", "")
+	assert.Error(t, err)
+}
+
+func TestGetSessionsMarksCurrent(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	sessions := mocks.NewSessionRepository(t)
+	uc := NewSessionUsecase(sessions, mocks.NewUserRepository(t))
+	uc.now = func() time.Time { return now }
+	sessions.On("GetSessions", "u1").Return([]domain.Session{
+		{SessionId: "s1", ExpiresAt: now.Add(time.Hour)},
+		{SessionId: "s2", ExpiresAt: now.Add(time.Hour)},
+		{SessionId: "s3", ExpiresAt: now.Add(time.Hour), RevokedAt: now},
+	}, nil)
+
+	active, err := uc.GetSessions("u1", "s2")
+	assert.NoError(t, err)
+	assert.Len(t, active, 2)
+	assert.False(t, active[0].Current)
+	assert.True(t, active[1].Current)
+}
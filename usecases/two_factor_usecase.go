@@ -0,0 +1,311 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+)
+
+const (
+	// LoginChallengeTTL is how long the second step of a login may take.
+	LoginChallengeTTL = 5 * time.Minute
+	// maxChallengeAttempts is how many codes a login challenge accepts.
+	maxChallengeAttempts = 5
+	recoveryCodeCount    = 10
+)
+
+var (
+	errInvalidCode       = errors.New("invalid two-factor code")
+	errInvalidChallenge  = errors.New("login expired, please log in again")
+	errTwoFactorDisabled = errors.New("two-factor authentication is not enabled")
+)
+
+// TwoFactorUsecase enrolls users in TOTP two-factor authentication and
+// checks the second factor of their logins.
+type TwoFactorUsecase struct {
+	twoFactorRepository domain.TwoFactorRepository
+	userRepository      domain.UserRepository
+	issuer              string
+	requiredForAdmins   bool
+	loginGuard          *LoginGuardUsecase
+	now                 func() time.Time
+}
+
+func NewTwoFactorUsecase(twoFactors domain.TwoFactorRepository, users domain.UserRepository, issuer string, requiredForAdmins bool) *TwoFactorUsecase {
+	if issuer == "" {
+		issuer = "BlogMate"
+	}
+	return &TwoFactorUsecase{
+		twoFactorRepository: twoFactors,
+		userRepository:      users,
+		issuer:              issuer,
+		requiredForAdmins:   requiredForAdmins,
+		now:                 time.Now,
+	}
+}
+
+// SetLoginGuard counts wrong codes against the user and the address like
+// wrong passwords, so new challenges do not give an attacker more guesses.
+func (uc *TwoFactorUsecase) SetLoginGuard(guard *LoginGuardUsecase) {
+	uc.loginGuard = guard
+}
+
+// RequiredForAdmins tells whether admins must log in with a second factor.
+func (uc *TwoFactorUsecase) RequiredForAdmins() bool {
+	return uc.requiredForAdmins
+}
+
+func (uc *TwoFactorUsecase) Status(user domain.User) (domain.TwoFactorStatus, error) {
+	twoFactor, err := uc.twoFactorRepository.GetTwoFactor(user.ID)
+	if err != nil {
+		return domain.TwoFactorStatus{}, err
+	}
+	status := domain.TwoFactorStatus{Required: user.IsAdmin && uc.requiredForAdmins}
+	if twoFactor.Enabled {
+		status.Enabled = true
+		status.RecoveryCodesLeft = len(twoFactor.RecoveryCodes)
+		status.EnabledAt = twoFactor.EnabledAt
+	}
+	return status, nil
+}
+
+// Enroll creates a new secret for the user. It is only used once a code of
+// it was verified with Activate.
+func (uc *TwoFactorUsecase) Enroll(user domain.User) (domain.TwoFactorEnrollment, error) {
+	twoFactor, err := uc.twoFactorRepository.GetTwoFactor(user.ID)
+	if err != nil {
+		return domain.TwoFactorEnrollment{}, err
+	}
+	if twoFactor.Enabled {
+		return domain.TwoFactorEnrollment{}, errors.New("two-factor authentication is already enabled, disable it first")
+	}
+	secret, err := infrastructure.NewTOTPSecret()
+	if err != nil {
+		return domain.TwoFactorEnrollment{}, err
+	}
+	err = uc.twoFactorRepository.SaveTwoFactor(domain.TwoFactor{UserId: user.ID, Secret: secret, RecoveryCodes: []string{}})
+	if err != nil {
+		return domain.TwoFactorEnrollment{}, err
+	}
+	account := user.Email
+	if account == "" {
+		account = user.Username
+	}
+	return domain.TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: infrastructure.TOTPProvisioningURI(uc.issuer, account, secret),
+	}, nil
+}
+
+// Activate enables the enrolled secret once the user proves their app
+// generates its codes, and returns the recovery codes. They are only shown
+// this once.
+func (uc *TwoFactorUsecase) Activate(userId, code string) ([]string, error) {
+	twoFactor, err := uc.twoFactorRepository.GetTwoFactor(userId)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.Secret == "" {
+		return nil, errors.New("start the enrollment first")
+	}
+	if twoFactor.Enabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	step, ok := infrastructure.ValidateTOTP(twoFactor.Secret, code, uc.now())
+	if !ok {
+		return nil, errInvalidCode
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	twoFactor.Enabled = true
+	twoFactor.EnabledAt = uc.now()
+	twoFactor.LastStep = step
+	twoFactor.RecoveryCodes = hashes
+	if err := uc.twoFactorRepository.SaveTwoFactor(twoFactor); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := infrastructure.NewRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = infrastructure.HashRecoveryCode(code)
+	}
+	return codes, hashes, nil
+}
+
+// StartLogin returns a challenge for the second step of the login when the
+// user has two-factor authentication enabled, and false when the password
+// is enough.
+func (uc *TwoFactorUsecase) StartLogin(user domain.User) (domain.LoginChallengeResponse, bool, error) {
+	twoFactor, err := uc.twoFactorRepository.GetTwoFactor(user.ID)
+	if err != nil {
+		return domain.LoginChallengeResponse{}, false, err
+	}
+	if !twoFactor.Enabled {
+		return domain.LoginChallengeResponse{}, false, nil
+	}
+	token, hash, err := infrastructure.NewRefreshToken()
+	if err != nil {
+		return domain.LoginChallengeResponse{}, false, err
+	}
+	err = uc.twoFactorRepository.CreateChallenge(domain.LoginChallenge{
+		ChallengeId: hash,
+		UserId:      user.ID,
+		ExpiresAt:   uc.now().Add(LoginChallengeTTL),
+	})
+	if err != nil {
+		return domain.LoginChallengeResponse{}, false, err
+	}
+	return domain.LoginChallengeResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		ExpiresIn:         int(LoginChallengeTTL / time.Second),
+	}, true, nil
+}
+
+// CompleteLogin checks the TOTP or recovery code of a login challenge and
+// returns the user to issue tokens for. It returns a *LoginBlockedError
+// while the user or the address ip is blocked by the login guard.
+func (uc *TwoFactorUsecase) CompleteLogin(challengeToken, code, ip string) (domain.User, error) {
+	if challengeToken == "" {
+		return domain.User{}, errInvalidChallenge
+	}
+	hash := infrastructure.HashRefreshToken(challengeToken)
+	challenge, err := uc.twoFactorRepository.CountChallengeAttempt(hash)
+	if err != nil || !challenge.ExpiresAt.After(uc.now()) {
+		return domain.User{}, errInvalidChallenge
+	}
+	if challenge.Attempts > maxChallengeAttempts {
+		uc.twoFactorRepository.DeleteChallenge(hash)
+		return domain.User{}, errInvalidChallenge
+	}
+	if uc.loginGuard != nil {
+		if blocked := uc.loginGuard.CheckUser(challenge.UserId, ip); blocked != nil {
+			return domain.User{}, &LoginBlockedError{Blocked: blocked}
+		}
+	}
+	if err := uc.verifyCode(challenge.UserId, code); err != nil {
+		if uc.loginGuard != nil {
+			uc.loginGuard.FailedUser(challenge.UserId, ip)
+		}
+		return domain.User{}, err
+	}
+	if err := uc.twoFactorRepository.DeleteChallenge(hash); err != nil {
+		return domain.User{}, err
+	}
+	users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{UserId: challenge.UserId}})
+	if err != nil || len(users) == 0 || !users[0].IsActive {
+		return domain.User{}, errInvalidChallenge
+	}
+	return users[0], nil
+}
+
+// verifyCode accepts a TOTP code that was not used yet or an unused
+// recovery code, which is used up.
+func (uc *TwoFactorUsecase) verifyCode(userId, code string) error {
+	twoFactor, err := uc.twoFactorRepository.GetTwoFactor(userId)
+	if err != nil {
+		return err
+	}
+	if !twoFactor.Enabled {
+		return errTwoFactorDisabled
+	}
+	if step, ok := infrastructure.ValidateTOTP(twoFactor.Secret, code, uc.now()); ok {
+		fresh, err := uc.twoFactorRepository.UseStep(userId, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return errInvalidCode
+		}
+		return nil
+	}
+	used, err := uc.twoFactorRepository.UseRecoveryCode(userId, infrastructure.HashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errInvalidCode
+	}
+	return nil
+}
+
+// reauthenticate checks the password and a second factor code of the user
+// before sensitive changes.
+func (uc *TwoFactorUsecase) reauthenticate(userId, password, code string) error {
+	if err := uc.checkPassword(userId, password); err != nil {
+		return err
+	}
+	return uc.verifyCode(userId, code)
+}
+
+func (uc *TwoFactorUsecase) checkPassword(userId, password string) error {
+	users, err := uc.userRepository.Get(domain.UserFilterOption{Filter: domain.UserFilter{UserId: userId}})
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return errors.New("invalid password")
+	}
+	if ok, _ := infrastructure.VerifyPassword(users[0].Password, password); !ok {
+		return errors.New("invalid password")
+	}
+	return nil
+}
+
+// Disable turns two-factor authentication off after the user confirmed their
+// password and a code.
+func (uc *TwoFactorUsecase) Disable(userId, password, code string) error {
+	if err := uc.reauthenticate(userId, password, code); err != nil {
+		return err
+	}
+	return uc.twoFactorRepository.DeleteTwoFactor(userId)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user after they
+// confirmed their password and a code.
+func (uc *TwoFactorUsecase) RegenerateRecoveryCodes(userId, password, code string) ([]string, error) {
+	if err := uc.reauthenticate(userId, password, code); err != nil {
+		return nil, err
+	}
+	twoFactor, err := uc.twoFactorRepository.GetTwoFactor(userId)
+	if err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	twoFactor.RecoveryCodes = hashes
+	if err := uc.twoFactorRepository.SaveTwoFactor(twoFactor); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Reset removes the second factor of a user who lost it. The admin doing it
+// confirms their own password and, unless admins may go without one, their
+// second factor first.
+func (uc *TwoFactorUsecase) Reset(adminId, password, code, userId string) error {
+	if err := uc.checkPassword(adminId, password); err != nil {
+		return err
+	}
+	err := uc.verifyCode(adminId, code)
+	if err == errTwoFactorDisabled && !uc.requiredForAdmins {
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+	return uc.twoFactorRepository.DeleteTwoFactor(userId)
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+func TestTwoFactorEnrollment(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	twoFactors := mocks.NewTwoFactorRepository(t)
+	uc := NewTwoFactorUsecase(twoFactors, mocks.NewUserRepository(t), "", true)
+	uc.now = func() time.Time { return now }
+	user := domain.User{ID: "u1", Email: "u1@example.com"}
+
+	var stored domain.TwoFactor
+	twoFactors.On("GetTwoFactor", "u1").Return(func(string) (domain.TwoFactor, error) { return stored, nil })
+	twoFactors.On("SaveTwoFactor", mock.Anything).Return(func(tf domain.TwoFactor) error {
+		stored = tf
+		return nil
+	})
+
+	enrollment, err := uc.Enroll(user)
+	assert.NoError(t, err)
+	assert.Equal(t, stored.Secret, enrollment.Secret)
+	assert.Contains(t, enrollment.ProvisioningURI, "otpauth://totp/BlogMate:u1@example.com")
+	assert.False(t, stored.Enabled)
+
+	_, err = uc.Activate("u1", "000000")
+	assert.Error(t, err)
+	code, _ := infrastructure.TOTPCode(stored.Secret, infrastructure.TOTPStep(now))
+	codes, err := uc.Activate("u1", code)
+	assert.NoError(t, err)
+	assert.Len(t, codes, recoveryCodeCount)
+	assert.True(t, stored.Enabled)
+	assert.Equal(t, infrastructure.HashRecoveryCode(codes[0]), stored.RecoveryCodes[0])
+	assert.NotContains(t, stored.RecoveryCodes, codes[0], "only hashes are stored")
+
+	_, err = uc.Enroll(user)
+	assert.Error(t, err, "enrolling again needs disabling first")
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	twoFactors := mocks.NewTwoFactorRepository(t)
+	users := mocks.NewUserRepository(t)
+	uc := NewTwoFactorUsecase(twoFactors, users, "BlogMate", true)
+	uc.now = func() time.Time { return now }
+	user := domain.User{ID: "u1", IsActive: true}
+	secret, _ := infrastructure.NewTOTPSecret()
+	twoFactors.On("GetTwoFactor", "u1").Return(domain.TwoFactor{UserId: "u1", Secret: secret, Enabled: true}, nil)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{UserId: "u1"}}).Return([]domain.User{user}, nil)
+
+	var challengeId string
+	twoFactors.On("CreateChallenge", mock.Anything).Return(func(c domain.LoginChallenge) error {
+		challengeId = c.ChallengeId
+		return nil
+	}).Once()
+	challenge, required, err := uc.StartLogin(user)
+	assert.NoError(t, err)
+	assert.True(t, required)
+	assert.Equal(t, infrastructure.HashRefreshToken(challenge.ChallengeToken), challengeId)
+
+	pending := domain.LoginChallenge{ChallengeId: challengeId, UserId: "u1", Attempts: 1, ExpiresAt: now.Add(LoginChallengeTTL)}
+	twoFactors.On("CountChallengeAttempt", challengeId).Return(pending, nil)
+
+	// a used code is refused
+	code, _ := infrastructure.TOTPCode(secret, infrastructure.TOTPStep(now))
+	twoFactors.On("UseStep", "u1", infrastructure.TOTPStep(now)).Return(false, nil).Once()
+	_, err = uc.CompleteLogin(challenge.ChallengeToken, code, "10.0.0.1")
+	assert.Error(t, err)
+
+	twoFactors.On("UseStep", "u1", infrastructure.TOTPStep(now)).Return(true, nil).Once()
+	twoFactors.On("DeleteChallenge", challengeId).Return(nil)
+	loggedIn, err := uc.CompleteLogin(challenge.ChallengeToken, code, "10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, user, loggedIn)
+
+	// recovery codes work once
+	twoFactors.On("UseRecoveryCode", "u1", infrastructure.HashRecoveryCode("abcd-efgh-jkmn")).Return(true, nil).Once()
+	_, err = uc.CompleteLogin(challenge.ChallengeToken, "ABCD-EFGH-JKMN", "10.0.0.1")
+	assert.NoError(t, err)
+	twoFactors.On("UseRecoveryCode", "u1", infrastructure.HashRecoveryCode("abcd-efgh-jkmn")).Return(false, nil).Once()
+	_, err = uc.CompleteLogin(challenge.ChallengeToken, "abcd-efgh-jkmn", "10.0.0.1")
+	assert.Error(t, err)
+}
+
+func TestTwoFactorChallengeLimits(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	twoFactors := mocks.NewTwoFactorRepository(t)
+	uc := NewTwoFactorUsecase(twoFactors, mocks.NewUserRepository(t), "BlogMate", true)
+	uc.now = func() time.Time { return now }
+
+	expired := infrastructure.HashRefreshToken("expired")
+	twoFactors.On("CountChallengeAttempt", expired).Return(domain.LoginChallenge{ChallengeId: expired, UserId: "u1", Attempts: 1, ExpiresAt: now.Add(-time.Second)}, nil)
+	_, err := uc.CompleteLogin("expired", "123456", "10.0.0.1")
+	assert.Equal(t, errInvalidChallenge, err)
+
+	guessed := infrastructure.HashRefreshToken("guessed")
+	twoFactors.On("CountChallengeAttempt", guessed).Return(domain.LoginChallenge{ChallengeId: guessed, UserId: "u1", Attempts: maxChallengeAttempts + 1, ExpiresAt: now.Add(time.Minute)}, nil)
+	twoFactors.On("DeleteChallenge", guessed).Return(nil).Once()
+	_, err = uc.CompleteLogin("guessed", "123456", "10.0.0.1")
+	assert.Equal(t, errInvalidChallenge, err)
+}
+
+func TestTwoFactorWrongCodesLockOutAcrossChallenges(t *testing.T) {
+	mailer := infrastructure.NewMemoryMailer()
+	infrastructure.SetDefaultMailer(mailer)
+	defer infrastructure.SetDefaultMailer(nil)
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	twoFactors := mocks.NewTwoFactorRepository(t)
+	users := mocks.NewUserRepository(t)
+	attempts := mocks.NewLoginAttemptRepository(t)
+	stored := storedLoginAttempts(attempts)
+	guard := NewLoginGuardUsecase(attempts, users)
+	guard.now = func() time.Time { return now }
+	uc := NewTwoFactorUsecase(twoFactors, users, "BlogMate", true)
+	uc.now = func() time.Time { return now }
+	uc.SetLoginGuard(guard)
+	user := domain.User{ID: "u1", Username: "ada", Email: "ada@example.com", IsActive: true}
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{UserId: "u1"}}).Return([]domain.User{user}, nil)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Username: "ada"}}).Return([]domain.User{user}, nil).Maybe()
+	secret, _ := infrastructure.NewTOTPSecret()
+	twoFactors.On("GetTwoFactor", "u1").Return(domain.TwoFactor{UserId: "u1", Secret: secret, Enabled: true}, nil)
+	twoFactors.On("CountChallengeAttempt", mock.Anything).Return(func(challengeId string) (domain.LoginChallenge, error) {
+		// every guess uses a new challenge, as after logging in again
+		return domain.LoginChallenge{ChallengeId: challengeId, UserId: "u1", Attempts: 1, ExpiresAt: now.Add(LoginChallengeTTL)}, nil
+	})
+
+	twoFactors.On("UseRecoveryCode", "u1", infrastructure.HashRecoveryCode("abcd-efgh-jkmn")).Return(false, nil)
+
+	for failures := 1; failures <= accountLoginLimit.lockAfter; failures++ {
+		now = now.Add(loginBackoffMax)
+		_, err := uc.CompleteLogin(fmt.Sprint("challenge-", failures), "abcd-efgh-jkmn", "10.0.0.1")
+		assert.Equal(t, errInvalidCode, err)
+	}
+	assert.Equal(t, accountLoginLimit.lockAfter, stored["user:u1"].Failures)
+
+	code, _ := infrastructure.TOTPCode(secret, infrastructure.TOTPStep(now))
+	_, err := uc.CompleteLogin("challenge-right", code, "10.0.0.2")
+	var blocked *LoginBlockedError
+	if assert.ErrorAs(t, err, &blocked) {
+		assert.True(t, blocked.Blocked.Locked)
+	}
+	assert.NotNil(t, guard.Check("ada", "", "10.0.0.3"), "the password step is locked too")
+	assert.Len(t, mailer.Sent(), 1)
+}
+
+func TestTwoFactorDisableNeedsPasswordAndCode(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	twoFactors := mocks.NewTwoFactorRepository(t)
+	users := mocks.NewUserRepository(t)
+	uc := NewTwoFactorUsecase(twoFactors, users, "BlogMate", true)
+	uc.now = func() time.Time { return now }
+	hashed, _ := infrastructure.PasswordHasher("secret")
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{UserId: "u1"}}).Return([]domain.User{{ID: "u1", Password: hashed}}, nil)
+	secret, _ := infrastructure.NewTOTPSecret()
+	twoFactors.On("GetTwoFactor", "u1").Return(domain.TwoFactor{UserId: "u1", Secret: secret, Enabled: true}, nil)
+	code, _ := infrastructure.TOTPCode(secret, infrastructure.TOTPStep(now))
+
+	assert.Error(t, uc.Disable("u1", "wrong", code))
+	twoFactors.On("UseRecoveryCode", "u1", infrastructure.HashRecoveryCode("000000")).Return(false, nil).Once()
+	assert.Error(t, uc.Disable("u1", "secret", "000000"))
+
+	twoFactors.On("UseStep", "u1", infrastructure.TOTPStep(now)).Return(true, nil).Once()
+	twoFactors.On("DeleteTwoFactor", "u1").Return(nil).Once()
+	assert.NoError(t, uc.Disable("u1", "secret", code))
+}
@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"context"
+
 	"github.com/yesetoda/BlogMate/config"
 	"github.com/yesetoda/BlogMate/domain"
 	"github.com/yesetoda/BlogMate/infrastructure"
@@ -10,14 +12,54 @@ import (
 	"log"
 	"math/big"
 	"time"
+
 )
 
+// ErrAccountNotActivated is returned by Authenticate for a right password of
+// an account that is not activated yet.
+var ErrAccountNotActivated = errors.New("Account not activated")
+
 type userUsecase struct {
 	userRepository domain.UserRepository
+	events         domain.EventEmitter
+	outbox         *OutboxUsecase
+	revoker        domain.TokenRevoker
+	passwordPolicy domain.PasswordPolicy
+	breached       domain.BreachedPasswordChecker
+}
+
+// UserUsecaseOption sets an optional dependency of the user usecase.
+type UserUsecaseOption func(*userUsecase)
+
+// WithUserEvents makes the usecase emit user events, e.g. to webhooks.
+func WithUserEvents(events domain.EventEmitter) UserUsecaseOption {
+	return func(uc *userUsecase) {
+		uc.events = events
+	}
+}
+
+// WithUserOutbox stores the emails and events of user changes in the outbox,
+// together with the change, instead of sending them inline.
+func WithUserOutbox(outbox *OutboxUsecase) UserUsecaseOption {
+	return func(uc *userUsecase) {
+		uc.outbox = outbox
+	}
+}
+
+// WithTokenRevoker revokes the access tokens of a user when they log out,
+// change or reset their password, are promoted or demoted, or are deleted.
+func WithTokenRevoker(revoker domain.TokenRevoker) UserUsecaseOption {
+	return func(uc *userUsecase) {
+		uc.revoker = revoker
+	}
 }
 
-func NewUserUsecase(u domain.UserRepository) (domain.UserUsecase, error) {
-	return &userUsecase{userRepository: u}, nil
+func NewUserUsecase(u domain.UserRepository, opts ...UserUsecaseOption) (domain.UserUsecase, error) {
+	uc := &userUsecase{userRepository: u, passwordPolicy: DefaultPasswordPolicy}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc, nil
 }
 
 func (useCase *userUsecase) Get() ([]domain.User, error) {
@@ -25,42 +67,52 @@ func (useCase *userUsecase) Get() ([]domain.User, error) {
 }
 
 func (useCase *userUsecase) LoginUser(uname string, password string, email string) (string, error) {
-	if uname != "" {
-		user, err := useCase.GetByUsername(uname)
-		if err != nil {
-			return "", err
-		}
-		if user.IsActive == false {
-			return "", errors.New("Account not activated")
-		}
-		accesstoken, refreshToken, err := infrastructure.GenerateToken(&user, password)
-		if err != nil {
-			return "", err
-		}
-		user.RefreshToken = refreshToken
-		useCase.userRepository.Update(user.ID, domain.User{RefreshToken: refreshToken, IsAdmin: user.IsAdmin})
-
-		return accesstoken, nil
-	} else if email != "" {
-		user, err := useCase.GetByEmail(email)
+	user, err := useCase.Authenticate(uname, password, email)
+	if err != nil {
+		return "", err
+	}
+	return infrastructure.GenerateAccessToken(&user, "", false)
+}
 
-		if err != nil {
-			return "", err
-		}
-		if user.IsActive == false {
-			return "", errors.New("Account not activated")
-		}
-		accesstoken, refreshToken, err := infrastructure.GenerateToken(&user, password)
-		if err != nil {
-			return "", err
-		}
-		log.Println(user)
-		user.RefreshToken = refreshToken
-		useCase.userRepository.Update(user.ID, domain.User{RefreshToken: refreshToken, IsAdmin: user.IsAdmin})
+// Authenticate finds the user by username, or by email when no username is
+// given, and checks the password and that the account is activated.
+func (useCase *userUsecase) Authenticate(uname string, password string, email string) (domain.User, error) {
+	filter := domain.UserFilter{Username: uname}
+	if uname == "" {
+		filter = domain.UserFilter{Email: email}
+	}
+	if filter.Username == "" && filter.Email == "" {
+		return domain.User{}, errors.New("Invalid login credentials")
+	}
+	users, err := useCase.userRepository.Get(domain.UserFilterOption{Filter: filter})
+	if err != nil {
+		return domain.User{}, err
+	}
+	if len(users) == 0 {
+		return domain.User{}, errors.New("invalid username or password")
+	}
+	ok, rehash := infrastructure.VerifyPassword(users[0].Password, password)
+	if !ok {
+		return domain.User{}, errors.New("invalid username or password")
+	}
+	if !users[0].IsActive {
+		return domain.User{}, ErrAccountNotActivated
+	}
+	if rehash {
+		useCase.rehashPassword(users[0], password)
+	}
+	return users[0], nil
+}
 
-		return accesstoken, nil
-	} else {
-		return "", errors.New("Invalid login credentials")
+// rehashPassword replaces the stored hash of the password after the hashing
+// algorithm or its cost changed. The login works even if it fails.
+func (useCase *userUsecase) rehashPassword(user domain.User, password string) {
+	hash, err := infrastructure.PasswordHasher(password)
+	if err == nil {
+		_, err = useCase.userRepository.Update(user.ID, domain.User{Password: hash, IsAdmin: user.IsAdmin})
+	}
+	if err != nil {
+		log.Println("rehashing the password of", user.ID, "failed:", err)
 	}
 }
 
@@ -71,7 +123,15 @@ func (useCase *userUsecase) Logout(email string) error {
 	}
 	user.RefreshToken = ""
 	useCase.userRepository.Update(user.ID, domain.User{RefreshToken: "", IsAdmin: user.IsAdmin})
-	return nil
+	return useCase.revokeTokens(user.ID)
+}
+
+// revokeTokens invalidates the access tokens issued to the user so far.
+func (useCase *userUsecase) revokeTokens(userId string) error {
+	if useCase.revoker == nil {
+		return nil
+	}
+	return useCase.revoker.RevokeUserTokens(userId)
 }
 
 func (useCase *userUsecase) ForgetPassword(email string) (string, error) {
@@ -82,14 +142,7 @@ func (useCase *userUsecase) ForgetPassword(email string) (string, error) {
 	if user.IsActive == false {
 		return "", errors.New("Account not activated")
 	}
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	confirmationToken := make([]byte, 64)
-	charsetLength := big.NewInt(int64(len(charset)))
-
-	for i := 0; i < 64; i++ {
-		num, _ := rand.Int(rand.Reader, charsetLength)
-		confirmationToken[i] = charset[num.Int64()]
-	}
+	confirmationToken := newConfirmationToken()
 
 	user.VerifyToken = string(confirmationToken)
 
@@ -100,7 +153,15 @@ func (useCase *userUsecase) ForgetPassword(email string) (string, error) {
 		return "", err
 	}
 	link := config_domain.Port + "/users/resetPassword/?email=" + user.Email + "&token=" + string(confirmationToken)
-	err = infrastructure.SendEmail(user.Email, "Password Reset", "This is the password reset link: ", link)
+	if useCase.outbox != nil {
+		email := domain.OutboxEmailPayload{To: user.Email, Template: infrastructure.PasswordResetEmail, Locale: user.Locale, Name: displayName(user), Link: link}
+		err = useCase.outbox.Enqueue(context.Background(), domain.OutboxEmail, "password-reset:"+user.ID+":"+string(confirmationToken), email)
+		if err != nil {
+			return "", err
+		}
+		return "Password reset token sent to your email", nil
+	}
+	err = infrastructure.SendTemplatedEmail(user.Email, infrastructure.PasswordResetEmail, user.Locale, infrastructure.EmailData{Name: displayName(user), Link: link})
 	if err != nil {
 		return "", err
 	}
@@ -120,11 +181,17 @@ func (useCase *userUsecase) ResetPassword(email string, token string, password s
 		if user.ExpirationDate.Before(time.Now()) {
 			return "Token has expired", errors.New("Token expired")
 		}
-		user.Password, _ = infrastructure.PasswordHasher(password)
-		_, err := useCase.userRepository.Update(user.ID, domain.User{Password: user.Password, IsAdmin: user.IsAdmin})
+		hash, history, err := useCase.newPassword(user, password)
+		if err != nil {
+			return "password has not been updated", err
+		}
+		_, err = useCase.userRepository.Update(user.ID, domain.User{Password: hash, PasswordHistory: history, IsAdmin: user.IsAdmin})
 		if err != nil {
 			return "password has not been updated", err
 		}
+		if err := useCase.revokeTokens(user.ID); err != nil {
+			return "password has been updated but the old sessions were not logged out", err
+		}
 		return "Password reset successful", nil
 	}
 	return "Invalid token", errors.New("Invalid token")
@@ -167,22 +234,24 @@ func (useCase *userUsecase) GetByEmail(email string) (domain.User, error) {
 }
 
 func (useCase *userUsecase) Create(u *domain.User) (domain.User, error) {
+	if err := useCase.validatePassword(domain.User{Username: u.Username, Email: u.Email}, u.Password); err != nil {
+		return domain.User{}, err
+	}
 	pass, err := infrastructure.PasswordHasher(u.Password)
 	if err != nil {
 		return domain.User{}, err
 	}
 	u.Password = pass
 	u.IsActive = false
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	confirmationToken := make([]byte, 64)
-	charsetLength := big.NewInt(int64(len(charset)))
-
-	for i := 0; i < 64; i++ {
-		num, _ := rand.Int(rand.Reader, charsetLength)
-		confirmationToken[i] = charset[num.Int64()]
-	}
+	confirmationToken := newConfirmationToken()
 	u.VerifyToken = string(confirmationToken)
+	if useCase.outbox != nil {
+		return useCase.createWithOutbox(u)
+	}
 	nUser, err := useCase.userRepository.Create(u)
+	if err == nil {
+		useCase.emitRegistered(nUser)
+	}
 	if !nUser.IsAdmin {
 		config_domain,err:=config.LoadConfig()
 		if err != nil {
@@ -190,7 +259,7 @@ func (useCase *userUsecase) Create(u *domain.User) (domain.User, error) {
 		}
 		link := config_domain.Port+"/users/accountVerification/?email=" + u.Email + "&token=" + string(confirmationToken)
 		// link := config_domain.Domain + "/users/accountVerification/?email=" + u.Email + "&token=" + string(confirmationToken)
-		err = infrastructure.SendEmail(u.Email, "Registration Confirmation", "This sign up Confirmation email to verify: ", link)
+		err = infrastructure.SendTemplatedEmail(u.Email, infrastructure.VerificationEmail, u.Locale, infrastructure.EmailData{Name: displayName(*u), Link: link})
 		if err != nil {
 			return nUser, err
 		}
@@ -201,6 +270,28 @@ func (useCase *userUsecase) Create(u *domain.User) (domain.User, error) {
 	return nUser, err
 }
 
+// newConfirmationToken returns a random 64 character token for links sent
+// by email.
+func newConfirmationToken() []byte {
+	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	confirmationToken := make([]byte, 64)
+	charsetLength := big.NewInt(int64(len(charset)))
+
+	for i := 0; i < 64; i++ {
+		num, _ := rand.Int(rand.Reader, charsetLength)
+		confirmationToken[i] = charset[num.Int64()]
+	}
+	return confirmationToken
+}
+
+// displayName is how emails greet the user.
+func displayName(u domain.User) string {
+	if u.FirstName != "" {
+		return u.FirstName
+	}
+	return u.Username
+}
+
 func (useCase *userUsecase) UpdateUser(userId string, updateData domain.User) (domain.User, error) {
 	user, err := useCase.GetByEmail(updateData.Email)
 	updateData.IsAdmin = user.IsAdmin
@@ -220,19 +311,27 @@ func (useCase *userUsecase) ChangePassword(email string, oldPassword string, new
 	if !user.IsActive {
 		return "", errors.New("Account not activated")
 	}
-	oldPassword, _ = infrastructure.PasswordHasher(oldPassword)
-	if user.Password == oldPassword {
-		user.Password, _ = infrastructure.PasswordHasher(newPassword)
-		user.Password = newPassword
-		_, err := useCase.userRepository.Update(user.ID, domain.User{Password: newPassword, IsAdmin: user.IsAdmin})
+	if ok, _ := infrastructure.VerifyPassword(user.Password, oldPassword); ok {
+		hash, history, err := useCase.newPassword(user, newPassword)
 		if err != nil {
 			return "password has not been updated", err
 		}
+		_, err = useCase.userRepository.Update(user.ID, domain.User{Password: hash, PasswordHistory: history, IsAdmin: user.IsAdmin})
+		if err != nil {
+			return "password has not been updated", err
+		}
+		if err := useCase.revokeTokens(user.ID); err != nil {
+			return "password has been updated but the old sessions were not logged out", err
+		}
 		return "Password change successful", nil
 	}
 	return "Invalid password", errors.New("Invalid password")
 }
 func (useCase *userUsecase) Delete(userId string) error {
+	// bump the token version first, it is gone with the user
+	if err := useCase.revokeTokens(userId); err != nil {
+		return err
+	}
 	return useCase.userRepository.Delete(userId)
 }
 func (useCase *userUsecase) PromteUser(username string) (domain.User, error) {
@@ -247,7 +346,7 @@ func (useCase *userUsecase) PromteUser(username string) (domain.User, error) {
 	if err != nil {
 		return user, fmt.Errorf("user not found")
 	}
-	return user, err
+	return user, useCase.revokeTokens(user.ID)
 }
 func (useCase *userUsecase) DemoteUser(username string) (domain.User, error) {
 	user, err := useCase.GetByUsername(username)
@@ -261,7 +360,7 @@ func (useCase *userUsecase) DemoteUser(username string) (domain.User, error) {
 	if err != nil {
 		return user, fmt.Errorf("user not found")
 	}
-	return user, err
+	return user, useCase.revokeTokens(user.ID)
 }
 func (useCase *userUsecase) PromteUserByEmail(email string) (domain.User, error) {
 	user, err := useCase.GetByEmail(email)
@@ -275,7 +374,7 @@ func (useCase *userUsecase) PromteUserByEmail(email string) (domain.User, error)
 	if err != nil {
 		return user, fmt.Errorf("user not found")
 	}
-	return user, err
+	return user, useCase.revokeTokens(user.ID)
 }
 func (useCase *userUsecase) DemoteUserByEmail(email string) (domain.User, error) {
 	user, err := useCase.GetByEmail(email)
@@ -289,5 +388,5 @@ func (useCase *userUsecase) DemoteUserByEmail(email string) (domain.User, error)
 	if err != nil {
 		return user, fmt.Errorf("user not found")
 	}
-	return user, err
+	return user, useCase.revokeTokens(user.ID)
 }
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
 	"github.com/yesetoda/BlogMate/mocks"
 	"fmt"
 	"log"
@@ -15,6 +16,7 @@ type UserUsecaseTestSuite struct {
 	suite.Suite
 	userUsecase    domain.UserUsecase
 	userRepository *mocks.UserRepository
+	mailer         *infrastructure.MemoryMailer
 	data           []domain.User
 }
 
@@ -25,6 +27,8 @@ func (suite *UserUsecaseTestSuite) SetupSuite() {
 		log.Fatal(err)
 	}
 	suite.userUsecase = userUsecase
+	suite.mailer = infrastructure.NewMemoryMailer()
+	infrastructure.SetDefaultMailer(suite.mailer)
 	suite.data = []domain.User{
 		{
 			ID:        "1",
@@ -62,10 +66,36 @@ func (suite *UserUsecaseTestSuite) SetupSuite() {
 func (suite *UserUsecaseTestSuite) TestCreate() {
 	assert := assert.New(suite.T())
 	user := suite.data[0]
-	suite.userRepository.On("Create", &user).Return(user, nil)
+	stored := user
+	suite.userRepository.On("Create", &user).Return(stored, nil)
 	createdUser, err := suite.userUsecase.Create(&user)
 	assert.Nil(err)
-	assert.Equal(createdUser, user)
+	assert.Equal(createdUser, stored)
+	assert.False(user.IsActive)
+	assert.NotEmpty(user.VerifyToken)
+
+	email, ok := suite.mailer.Last()
+	assert.True(ok)
+	assert.Equal([]string{user.Email}, email.To)
+	assert.Contains(email.Text, user.VerifyToken)
+}
+
+func (suite *UserUsecaseTestSuite) TestAuthenticate() {
+	assert := assert.New(suite.T())
+	hashed, _ := infrastructure.PasswordHasher("secret")
+	user := domain.User{ID: "7", Username: "auth_user", Password: hashed, IsActive: true}
+	suite.userRepository.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Username: "auth_user"}}).Return([]domain.User{user}, nil)
+	suite.userRepository.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Username: "nobody"}}).Return([]domain.User{}, nil)
+
+	authenticated, err := suite.userUsecase.Authenticate("auth_user", "secret", "")
+	assert.Nil(err)
+	assert.Equal(user, authenticated)
+	_, err = suite.userUsecase.Authenticate("auth_user", "wrong", "")
+	assert.Error(err)
+	_, err = suite.userUsecase.Authenticate("nobody", "secret", "")
+	assert.Error(err)
+	_, err = suite.userUsecase.Authenticate("", "secret", "")
+	assert.Error(err)
 }
 
 func (suite *UserUsecaseTestSuite) TestGet() {
@@ -130,6 +160,30 @@ func (suite *UserUsecaseTestSuite) TestGetByUsername() {
 	})
 }
 
+func (suite *UserUsecaseTestSuite) TearDownSuite() {
+	infrastructure.SetDefaultMailer(nil)
+}
+
 func TestUserUsecase(t *testing.T) {
 	suite.Run(t, new(UserUsecaseTestSuite))
 }
+
+func TestUserUsecaseRevokesTokens(t *testing.T) {
+	users := mocks.NewUserRepository(t)
+	revoker := mocks.NewTokenRevoker(t)
+	uc, err := NewUserUsecase(users, WithTokenRevoker(revoker))
+	assert.NoError(t, err)
+	user := domain.User{ID: "9", Username: "rev_user", Email: "rev@example.com", IsActive: true}
+
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Email: user.Email}}).Return([]domain.User{user}, nil)
+	users.On("Get", domain.UserFilterOption{Filter: domain.UserFilter{Username: user.Username}}).Return([]domain.User{user}, nil)
+	users.On("Update", user.ID, domain.User{IsAdmin: user.IsAdmin}).Return(user, nil).Once()
+	users.On("Update", user.ID, domain.User{IsAdmin: true}).Return(user, nil).Once()
+	users.On("Delete", user.ID).Return(nil).Once()
+	revoker.On("RevokeUserTokens", user.ID).Return(nil).Times(3)
+
+	assert.NoError(t, uc.Logout(user.Email))
+	_, err = uc.PromteUser(user.Username)
+	assert.NoError(t, err)
+	assert.NoError(t, uc.Delete(user.ID))
+}
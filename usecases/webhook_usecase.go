@@ -0,0 +1,364 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	webhookMaxAttempts = 8
+	webhookBaseBackoff = 30 * time.Second
+	webhookMaxBackoff  = 6 * time.Hour
+	webhookTimeout     = 10 * time.Second
+	webhookBatchSize   = 20
+	// webhookClaimLock outlasts a batch, whose deliveries are sent one after
+	// the other, so no other worker claims them again while it is sent.
+	webhookClaimLock = (webhookBatchSize + 1) * webhookTimeout
+)
+
+type WebhookUsecase struct {
+	webhookRepository domain.WebhookRepository
+	client            *http.Client
+	now               func() time.Time
+	wake              chan struct{}
+}
+
+func NewWebhookUsecase(repo domain.WebhookRepository) *WebhookUsecase {
+	return &WebhookUsecase{
+		webhookRepository: repo,
+		client:            &http.Client{Timeout: webhookTimeout},
+		now:               time.Now,
+		wake:              make(chan struct{}, 1),
+	}
+}
+
+// webhookPayload is the JSON body of every delivery. Id identifies the event,
+// it is the same for every webhook and for redeliveries so that receivers can
+// drop duplicates.
+type webhookPayload struct {
+	Id        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+func isWebhookEvent(event string) bool {
+	for _, e := range domain.WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("webhook url must be an absolute http or https url")
+	}
+	return nil
+}
+
+func validateWebhookEvents(events []string) error {
+	if len(events) == 0 {
+		return errors.New("a webhook needs at least one event")
+	}
+	for _, event := range events {
+		if !isWebhookEvent(event) {
+			return fmt.Errorf("unknown webhook event %q", event)
+		}
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// CreateWebhook registers a subscription. A signing secret is generated when
+// none is given, it is only returned here.
+func (uc *WebhookUsecase) CreateWebhook(w domain.Webhook) (domain.Webhook, error) {
+	if err := validateWebhookURL(w.URL); err != nil {
+		return domain.Webhook{}, err
+	}
+	if err := validateWebhookEvents(w.Events); err != nil {
+		return domain.Webhook{}, err
+	}
+	if w.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return domain.Webhook{}, err
+		}
+		w.Secret = secret
+	}
+	return uc.webhookRepository.CreateWebhook(w)
+}
+
+func hideSecret(w domain.Webhook) domain.Webhook {
+	w.Secret = ""
+	return w
+}
+
+func (uc *WebhookUsecase) GetWebhooks() ([]domain.Webhook, error) {
+	webhooks, err := uc.webhookRepository.GetWebhooks()
+	if err != nil {
+		return []domain.Webhook{}, err
+	}
+	for i := range webhooks {
+		webhooks[i] = hideSecret(webhooks[i])
+	}
+	return webhooks, nil
+}
+
+func (uc *WebhookUsecase) GetWebhookById(webhookId string) (domain.Webhook, error) {
+	w, err := uc.webhookRepository.GetWebhookById(webhookId)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	return hideSecret(w), nil
+}
+
+func (uc *WebhookUsecase) UpdateWebhook(webhookId string, updateData domain.Webhook) (domain.Webhook, error) {
+	if updateData.URL != "" {
+		if err := validateWebhookURL(updateData.URL); err != nil {
+			return domain.Webhook{}, err
+		}
+	}
+	if updateData.Events != nil {
+		if err := validateWebhookEvents(updateData.Events); err != nil {
+			return domain.Webhook{}, err
+		}
+	}
+	w, err := uc.webhookRepository.UpdateWebhook(webhookId, updateData)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	return hideSecret(w), nil
+}
+
+func (uc *WebhookUsecase) DeleteWebhook(webhookId string) error {
+	return uc.webhookRepository.DeleteWebhook(webhookId)
+}
+
+// Emit queues a delivery of the event for every active webhook subscribed to
+// it. The deliveries are sent by Run.
+func (uc *WebhookUsecase) Emit(event string, data interface{}) error {
+	return uc.queueDeliveries(primitive.NewObjectID().Hex(), event, uc.now(), data)
+}
+
+// HandleOutboxMessage is the outbox handler of webhook messages. The message's
+// idempotency key is the event id, so handling it twice queues nothing new.
+func (uc *WebhookUsecase) HandleOutboxMessage(message domain.OutboxMessage) error {
+	var event outboxEvent
+	if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+		return err
+	}
+	return uc.queueDeliveries(message.IdempotencyKey, event.Event, event.CreatedAt, event.Data)
+}
+
+// deliveryIdFor derives the id of the delivery of an event to a webhook, so
+// that queueing the same event again does not create a second delivery.
+func deliveryIdFor(eventId, webhookId string) string {
+	sum := sha256.Sum256([]byte(eventId + "\x00" + webhookId))
+	return hex.EncodeToString(sum[:12])
+}
+
+func (uc *WebhookUsecase) queueDeliveries(eventId, event string, createdAt time.Time, data interface{}) error {
+	webhooks, err := uc.webhookRepository.GetWebhooksForEvent(event)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+	payload, err := json.Marshal(webhookPayload{Id: eventId, Event: event, CreatedAt: createdAt, Data: data})
+	if err != nil {
+		return err
+	}
+	for _, w := range webhooks {
+		_, err := uc.webhookRepository.CreateDelivery(domain.WebhookDelivery{
+			DeliveryId:  deliveryIdFor(eventId, w.WebhookId),
+			WebhookId:   w.WebhookId,
+			Event:       event,
+			Payload:     string(payload),
+			Status:      domain.DeliveryPending,
+			NextAttempt: uc.now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	uc.signal()
+	return nil
+}
+
+func (uc *WebhookUsecase) signal() {
+	select {
+	case uc.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (uc *WebhookUsecase) GetDeliveries(webhookId string, opts domain.PaginationInfo) ([]domain.WebhookDelivery, error) {
+	deliveries, err := uc.webhookRepository.GetDeliveries(webhookId, opts)
+	if err != nil {
+		return []domain.WebhookDelivery{}, err
+	}
+	return deliveries, nil
+}
+
+// Redeliver queues a new delivery with the payload of an earlier one, the
+// original delivery and its log are kept.
+func (uc *WebhookUsecase) Redeliver(webhookId, deliveryId string) (domain.WebhookDelivery, error) {
+	original, err := uc.webhookRepository.GetDeliveryById(webhookId, deliveryId)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	delivery, err := uc.webhookRepository.CreateDelivery(domain.WebhookDelivery{
+		WebhookId:    webhookId,
+		Event:        original.Event,
+		Payload:      original.Payload,
+		Status:       domain.DeliveryPending,
+		NextAttempt:  uc.now(),
+		RedeliveryOf: original.DeliveryId,
+	})
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	uc.signal()
+	return delivery, nil
+}
+
+// webhookBackoff is the wait before the next attempt after the given number
+// of failed ones: 30s, 1m, 2m, ... capped at 6h.
+func webhookBackoff(failed int) time.Duration {
+	wait := webhookBaseBackoff
+	for i := 1; i < failed; i++ {
+		wait *= 2
+		if wait >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return wait
+}
+
+// DeliverDue sends the deliveries whose next attempt is due and returns how
+// many were attempted.
+func (uc *WebhookUsecase) DeliverDue() (int, error) {
+	deliveries, err := uc.webhookRepository.ClaimDueDeliveries(uc.now(), webhookClaimLock, webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, d := range deliveries {
+		if err := uc.attempt(d); err != nil {
+			return 0, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+func (uc *WebhookUsecase) attempt(d domain.WebhookDelivery) error {
+	started := uc.now()
+	attempt := domain.WebhookAttempt{Date: started}
+	w, err := uc.webhookRepository.GetWebhookById(d.WebhookId)
+	if err != nil || !w.IsActive() {
+		attempt.Error = "webhook was deleted or deactivated"
+		return uc.webhookRepository.RecordAttempt(d.DeliveryId, attempt, domain.DeliveryFailed, time.Time{})
+	}
+
+	attempt.StatusCode, err = uc.send(w, d)
+	attempt.Duration = uc.now().Sub(started)
+	if err == nil {
+		return uc.webhookRepository.RecordAttempt(d.DeliveryId, attempt, domain.DeliverySucceeded, time.Time{})
+	}
+	attempt.Error = err.Error()
+	failed := len(d.Attempts) + 1
+	if failed >= webhookMaxAttempts {
+		return uc.webhookRepository.RecordAttempt(d.DeliveryId, attempt, domain.DeliveryFailed, time.Time{})
+	}
+	return uc.webhookRepository.RecordAttempt(d.DeliveryId, attempt, domain.DeliveryPending, uc.now().Add(webhookBackoff(failed)))
+}
+
+func (uc *WebhookUsecase) send(w domain.Webhook, d domain.WebhookDelivery) (int, error) {
+	body := []byte(d.Payload)
+	timestamp := uc.now().Unix()
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "BlogMate-Webhooks/1.0")
+	req.Header.Set("X-BlogMate-Event", d.Event)
+	req.Header.Set("X-BlogMate-Delivery", d.DeliveryId)
+	req.Header.Set("X-BlogMate-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-BlogMate-Signature", infrastructure.SignWebhookPayload(w.Secret, timestamp, body))
+	resp, err := uc.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Run delivers due webhooks until stop is closed. It wakes up every interval
+// and whenever a new delivery is queued.
+func (uc *WebhookUsecase) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			sent, err := uc.DeliverDue()
+			if err != nil {
+				log.Println("delivering webhooks failed:", err)
+			}
+			if err != nil || sent < webhookBatchSize {
+				break
+			}
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-uc.wake:
+		}
+	}
+}
+
+// registeredUser is the public part of a user sent with user.registered.
+type registeredUser struct {
+	ID        string `json:"id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+func (useCase *userUsecase) emitRegistered(u domain.User) {
+	if useCase.events == nil {
+		return
+	}
+	data := registeredUser{ID: u.ID, Username: u.Username, Email: u.Email, FirstName: u.FirstName, LastName: u.LastName}
+	if err := useCase.events.Emit(domain.EventUserRegistered, data); err != nil {
+		log.Println("emitting", domain.EventUserRegistered, "failed:", err)
+	}
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yesetoda/BlogMate/domain"
+	"github.com/yesetoda/BlogMate/infrastructure"
+	"github.com/yesetoda/BlogMate/mocks"
+)
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhookBackoff(1))
+	assert.Equal(t, time.Minute, webhookBackoff(2))
+	assert.Equal(t, 8*time.Minute, webhookBackoff(5))
+	assert.Equal(t, webhookMaxBackoff, webhookBackoff(20))
+}
+
+func TestCreateWebhookValidation(t *testing.T) {
+	repo := mocks.NewWebhookRepository(t)
+	uc := NewWebhookUsecase(repo)
+
+	_, err := uc.CreateWebhook(domain.Webhook{URL: "ftp://example.com", Events: []string{domain.EventBlogCreated}})
+	assert.Error(t, err)
+	_, err = uc.CreateWebhook(domain.Webhook{URL: "https://example.com/hook", Events: []string{"blog.liked"}})
+	assert.Error(t, err)
+
+	repo.On("CreateWebhook", mock.MatchedBy(func(w domain.Webhook) bool { return len(w.Secret) == 64 })).
+		Return(func(w domain.Webhook) (domain.Webhook, error) { return w, nil }).Once()
+	created, err := uc.CreateWebhook(domain.Webhook{URL: "https://example.com/hook", Events: []string{domain.EventBlogCreated}})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, created.Secret)
+}
+
+func TestDeliverDue(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	webhook := domain.Webhook{WebhookId: "w1", URL: server.URL, Secret: "s3cret", Events: []string{domain.EventBlogCreated}}
+	delivery := domain.WebhookDelivery{DeliveryId: "d1", WebhookId: "w1", Event: domain.EventBlogCreated, Payload: `{"id":"e1"}`, Status: domain.DeliveryPending}
+
+	repo := mocks.NewWebhookRepository(t)
+	repo.On("GetWebhookById", "w1").Return(webhook, nil)
+	uc := NewWebhookUsecase(repo)
+	uc.now = func() time.Time { return now }
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("ClaimDueDeliveries", now, webhookClaimLock, webhookBatchSize).Return([]domain.WebhookDelivery{delivery}, nil).Once()
+		repo.On("RecordAttempt", "d1", mock.MatchedBy(func(a domain.WebhookAttempt) bool { return a.StatusCode == 200 }), domain.DeliverySucceeded, time.Time{}).Return(nil).Once()
+		sent, err := uc.DeliverDue()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		assert.Equal(t, `{"id":"e1"}`, string(body))
+		assert.Equal(t, domain.EventBlogCreated, received.Header.Get("X-BlogMate-Event"))
+		assert.Equal(t, "d1", received.Header.Get("X-BlogMate-Delivery"))
+		timestamp, _ := strconv.ParseInt(received.Header.Get("X-BlogMate-Timestamp"), 10, 64)
+		assert.True(t, infrastructure.VerifyWebhookSignature("s3cret", received.Header.Get("X-BlogMate-Signature"), timestamp, body))
+	})
+
+	t.Run("failure is retried later", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		failedOnce := delivery
+		failedOnce.Attempts = []domain.WebhookAttempt{{StatusCode: 500}}
+		repo.On("ClaimDueDeliveries", now, webhookClaimLock, webhookBatchSize).Return([]domain.WebhookDelivery{failedOnce}, nil).Once()
+		repo.On("RecordAttempt", "d1", mock.MatchedBy(func(a domain.WebhookAttempt) bool { return a.StatusCode == 500 && a.Error != "" }), domain.DeliveryPending, now.Add(time.Minute)).Return(nil).Once()
+		_, err := uc.DeliverDue()
+		assert.NoError(t, err)
+	})
+
+	t.Run("gives up after the last attempt", func(t *testing.T) {
+		exhausted := delivery
+		exhausted.Attempts = make([]domain.WebhookAttempt, webhookMaxAttempts-1)
+		repo.On("ClaimDueDeliveries", now, webhookClaimLock, webhookBatchSize).Return([]domain.WebhookDelivery{exhausted}, nil).Once()
+		repo.On("RecordAttempt", "d1", mock.Anything, domain.DeliveryFailed, time.Time{}).Return(nil).Once()
+		_, err := uc.DeliverDue()
+		assert.NoError(t, err)
+	})
+}